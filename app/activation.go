@@ -0,0 +1,237 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/system/manifest"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+type (
+	// Activation describes how the application was launched, it decides which modules are active.
+	Activation struct {
+		// Role is the role the application runs as, every module is active when it is empty.
+		Role string
+		// Flag is the CLI flag the role was read from, if any.
+		Flag     string
+		Manifest *manifest.Manifest
+		Args     []string
+		// Invoked is the path of the CLI command being run, such as [migrate down], as resolved by the command.Root
+		// from its command tree. It is nil when the application has no command.Root.
+		Invoked []string
+	}
+	// Condition must hold for a module to be active.
+	Condition struct {
+		Description string
+		Check       func(activation *Activation) bool
+	}
+)
+
+// Role selects the role the application runs as.
+func Role(role string) opt.Option[App] {
+	return func(opt *App) {
+		opt.activation.Role = role
+	}
+}
+
+// RoleFromEnv selects the role from an environment variable, when it is set.
+func RoleFromEnv(env string) opt.Option[App] {
+	return func(opt *App) {
+		if role := os.Getenv(env); role != "" {
+			opt.activation.Role = role
+		}
+	}
+}
+
+// RoleFromFlag selects the role from a CLI flag, such as `--role worker` or `--role=worker`.
+// The flag is also declared on the command.Root, so that it does not fail the command parsing.
+func RoleFromFlag(flag string) opt.Option[App] {
+	return func(opt *App) {
+		opt.activation.Flag = flag
+		if role, ok := flagValue(opt.activation.Args, flag); ok {
+			opt.activation.Role = role
+		}
+	}
+}
+
+func flagValue(args []string, flag string) (string, bool) {
+	name := "--" + flag
+	for idx, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value, true
+		}
+		if arg == name && idx+1 < len(args) {
+			return args[idx+1], true
+		}
+	}
+	return "", false
+}
+
+// Command returns the top-level CLI command being run, if any.
+// Without a command.Root to resolve it, it is the first positional argument, which misreads the value of a flag
+// other than the role one, such as `--config prod.yaml migrate`.
+func (a *Activation) Command() string {
+	if a.Invoked != nil {
+		if len(a.Invoked) == 0 {
+			return ""
+		}
+		return a.Invoked[0]
+	}
+	for idx := 0; idx < len(a.Args); idx++ {
+		arg := a.Args[idx]
+		switch {
+		case arg == "--":
+			return ""
+		case a.Flag != "" && arg == "--"+a.Flag:
+			idx++
+		case !strings.HasPrefix(arg, "-"):
+			return arg
+		}
+	}
+	return ""
+}
+
+func EnvSet(env string) Condition {
+	return Condition{
+		Description: fmt.Sprintf("env %s is set", env),
+		Check: func(*Activation) bool {
+			return os.Getenv(env) != ""
+		},
+	}
+}
+
+func EnvEquals(env, value string) Condition {
+	return Condition{
+		Description: fmt.Sprintf("env %s=%s", env, value),
+		Check: func(*Activation) bool {
+			return os.Getenv(env) == value
+		},
+	}
+}
+
+func FlagSet(flag string) Condition {
+	return Condition{
+		Description: fmt.Sprintf("flag --%s is set", flag),
+		Check: func(activation *Activation) bool {
+			for _, arg := range activation.Args {
+				if arg == "--" {
+					break
+				}
+				if arg == "--"+flag || strings.HasPrefix(arg, "--"+flag+"=") {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+func ManifestMatches(description string, predicate func(manifest *manifest.Manifest) bool) Condition {
+	return Condition{
+		Description: description,
+		Check: func(activation *Activation) bool {
+			return predicate(activation.Manifest)
+		},
+	}
+}
+
+// IsActive reports whether the module runs for the activation, along with the reason why it does not.
+func (m Module) IsActive(activation *Activation) (bool, string) {
+	if activation.Role != "" && len(m.Roles) > 0 {
+		matches := false
+		for _, role := range m.Roles {
+			matches = matches || role == activation.Role
+		}
+		if !matches {
+			return false, fmt.Sprintf("role '%s' is not one of [%s]", activation.Role, strings.Join(m.Roles, ", "))
+		}
+	}
+	for _, condition := range m.Conditions {
+		if !condition.Check(activation) {
+			return false, fmt.Sprintf("condition not met: %s", condition.Description)
+		}
+	}
+	return true, ""
+}
+
+type deactivation struct {
+	module Module
+	reason string
+}
+
+// activate prunes the modules, and their sub-modules, which are not active.
+func activate(modules []Module, activation *Activation) ([]Module, []deactivation) {
+	active := make([]Module, 0, len(modules))
+	inactive := make([]deactivation, 0)
+	for _, m := range modules {
+		if ok, reason := m.IsActive(activation); !ok {
+			inactive = append(inactive, deactivation{module: m, reason: reason})
+			continue
+		}
+		subModules := make([]IModule, 0, len(m.SubModules))
+		for _, sub := range m.SubModules {
+			subModule, ok := sub.(Module)
+			if !ok {
+				subModules = append(subModules, sub)
+				continue
+			}
+			activeSubs, inactiveSubs := activate([]Module{subModule}, activation)
+			for _, activeSub := range activeSubs {
+				subModules = append(subModules, activeSub)
+			}
+			inactive = append(inactive, inactiveSubs...)
+		}
+		m.SubModules = subModules
+		active = append(active, m)
+	}
+	return active, inactive
+}
+
+// unmetDependencies explains which required values no active module provides, and which inactive module would have.
+func unmetDependencies(graph *Graph, inactive []deactivation) error {
+	if len(graph.Missing) == 0 {
+		return nil
+	}
+	inactiveModules := make([]Module, 0, len(inactive))
+	reasons := make(map[string]string, len(inactive))
+	for _, deactivated := range inactive {
+		if _, seen := reasons[deactivated.module.Name]; seen {
+			continue
+		}
+		inactiveModules = append(inactiveModules, deactivated.module)
+		reasons[deactivated.module.Name] = deactivated.reason
+	}
+	inactiveProviders := make(map[string][]string)
+	Inspect(inactiveModules).Walk(func(node *ModuleNode, p *Provider) {
+		for _, output := range p.Outputs {
+			inactiveProviders[output.key()] = append(inactiveProviders[output.key()], p.Module)
+		}
+	})
+	lines := make([]string, 0, len(graph.Missing))
+	for _, missing := range graph.Missing {
+		consumers := make([]string, 0)
+		graph.Walk(func(_ *ModuleNode, p *Provider) {
+			for _, input := range p.Inputs {
+				if input.key() == missing.key() {
+					consumers = append(consumers, fmt.Sprintf("%s (module %s)", p.Function, p.Module))
+				}
+			}
+		})
+		line := fmt.Sprintf("- %s is required by %s", missing, strings.Join(consumers, ", "))
+		for _, module := range inactiveProviders[missing.key()] {
+			reason := reasons[module]
+			if reason == "" {
+				reason = "its parent module is inactive"
+			}
+			line += fmt.Sprintf(", it is provided by the inactive module %s (%s)", module, reason)
+		}
+		lines = append(lines, line)
+	}
+	return errors.Newf("unmet dependencies:\n%s", strings.Join(lines, "\n"))
+}
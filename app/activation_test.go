@@ -0,0 +1,110 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		value string
+		found bool
+	}{
+		{name: "separate value", args: []string{"serve", "--role", "worker"}, value: "worker", found: true},
+		{name: "inline value", args: []string{"--role=api", "serve"}, value: "api", found: true},
+		{name: "missing value", args: []string{"--role"}},
+		{name: "after terminator", args: []string{"--", "--role", "worker"}},
+		{name: "other flag", args: []string{"--roles", "worker"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found := flagValue(tt.args, "role")
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.value, value)
+		})
+	}
+}
+
+func TestActivation_Command(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		flag     string
+		invoked  []string
+		expected string
+	}{
+		{name: "no arguments"},
+		{name: "command", args: []string{"migrate", "status"}, expected: "migrate"},
+		{name: "after flags", args: []string{"-v", "--debug=true", "serve"}, expected: "serve"},
+		{name: "after role flag", args: []string{"--role", "worker", "migrate"}, flag: "role", expected: "migrate"},
+		{name: "after inline role flag", args: []string{"--role=worker", "migrate"}, flag: "role", expected: "migrate"},
+		{name: "after terminator", args: []string{"--", "migrate"}},
+		{name: "resolved", args: []string{"--config", "prod.yaml", "migrate"}, invoked: []string{"migrate", "down"}, expected: "migrate"},
+		{name: "resolved to the root", args: []string{"--config", "prod.yaml"}, invoked: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activation := &Activation{Args: tt.args, Flag: tt.flag, Invoked: tt.invoked}
+			require.Equal(t, tt.expected, activation.Command())
+		})
+	}
+}
+
+func TestModule_IsActive(t *testing.T) {
+	never := Condition{Description: "never", Check: func(*Activation) bool { return false }}
+	tests := []struct {
+		name       string
+		roles      []string
+		conditions []Condition
+		role       string
+		active     bool
+		reason     string
+	}{
+		{name: "no role", roles: []string{"worker"}, active: true},
+		{name: "matching role", roles: []string{"api", "worker"}, role: "worker", active: true},
+		{name: "unrestricted module", role: "worker", active: true},
+		{name: "other role", roles: []string{"api"}, role: "worker", reason: "role 'worker' is not one of [api]"},
+		{name: "unmet condition", conditions: []Condition{never}, reason: "condition not met: never"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewNamedModule("test", func(m *Module) {
+				m.Roles = tt.roles
+				m.Conditions = tt.conditions
+			})
+			active, reason := m.IsActive(&Activation{Role: tt.role})
+			require.Equal(t, tt.active, active)
+			require.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestActivate(t *testing.T) {
+	worker := NewNamedModule("worker", func(m *Module) {
+		m.Roles = []string{"worker"}
+		m.Public = append(m.Public, newGraphConfig)
+	})
+	api := NewNamedModule("api", func(m *Module) {
+		m.Roles = []string{"api"}
+		m.SubModules = append(m.SubModules, worker)
+	})
+	store := NewNamedModule("store", func(m *Module) {
+		m.Public = append(m.Public, newGraphStore)
+		m.SubModules = append(m.SubModules, worker)
+	})
+
+	active, inactive := activate([]Module{api, store}, &Activation{Role: "api"})
+	require.Len(t, active, 2)
+	require.Empty(t, active[1].SubModules)
+	require.Len(t, inactive, 2)
+	require.Equal(t, "worker", inactive[0].module.Name)
+
+	err := unmetDependencies(Inspect(active, (*graphExternal)(nil)), inactive)
+	require.ErrorContains(t, err, "*app.graphConfig is required by")
+	require.ErrorContains(t, err, "provided by the inactive module worker (role 'api' is not one of [worker])")
+	require.Equal(t, 1, strings.Count(err.Error(), "inactive module worker"))
+}
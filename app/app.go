@@ -2,13 +2,16 @@ package app
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
+	"github.com/kiwiworks/rodent/app/lifecycle"
 	"github.com/kiwiworks/rodent/logger"
 	"github.com/kiwiworks/rodent/logger/props"
 	"github.com/kiwiworks/rodent/system/manifest"
@@ -17,21 +20,49 @@ import (
 )
 
 type App struct {
-	manifest  *manifest.Manifest
-	fxOptions []fx.Option
-	di        *fx.App
-	Done      chan struct{}
-	telemetry *telemetry.Telemetry
+	manifest         *manifest.Manifest
+	modules          []Module
+	activation       *Activation
+	graph            *Graph
+	fxOptions        []fx.Option
+	telemetryOptions []opt.Option[telemetry.Options]
+	di               *fx.App
+	Done             chan struct{}
+	telemetry        *telemetry.Telemetry
+	// logs is where the application logs, when it does not use the configured output, see LogTo.
+	logs zapcore.Core
 }
 
 func Modules(modules ...func() Module) opt.Option[App] {
 	return func(opt *App) {
 		for _, m := range modules {
-			opt.fxOptions = append(opt.fxOptions, m().IntoFxModule())
+			opt.modules = append(opt.modules, m())
 		}
 	}
 }
 
+// FxOptions appends raw fx options to the application, such as fx.Replace or fx.Decorate.
+func FxOptions(options ...fx.Option) opt.Option[App] {
+	return func(opt *App) {
+		opt.fxOptions = append(opt.fxOptions, options...)
+	}
+}
+
+// Telemetry configures the telemetry.Telemetry instance created for the application.
+func Telemetry(options ...opt.Option[telemetry.Options]) opt.Option[App] {
+	return func(opt *App) {
+		opt.telemetryOptions = append(opt.telemetryOptions, options...)
+	}
+}
+
+// LogTo makes the application write its logs to core: its own logs, and those emitted through the contexts
+// handed to its hooks, see logger.WithCore.
+func LogTo(core zapcore.Core) opt.Option[App] {
+	return func(opt *App) {
+		opt.logs = core
+	}
+}
+
 func StartTimeout(timeout time.Duration) opt.Option[App] {
 	return func(opt *App) {
 		opt.manifest.Timeouts.Start = timeout
@@ -46,43 +77,119 @@ func StopTimeout(timeout time.Duration) opt.Option[App] {
 	}
 }
 
-func fxLogProvider() fxevent.Logger {
-	log := &fxevent.ZapLogger{
-		Logger: logger.New(),
+func fxLogProvider(log *zap.Logger) func() fxevent.Logger {
+	return func() fxevent.Logger {
+		fxLog := &fxevent.ZapLogger{
+			Logger: log,
+		}
+		fxLog.UseLogLevel(logger.DebugLevel)
+		return fxLog
 	}
-	log.UseLogLevel(logger.DebugLevel)
-	return log
 }
 
 func New(name, version string, opts ...opt.Option[App]) *App {
-	log := logger.New()
-	m := manifest.New(name, version)
-	telemetryInstance, err := telemetry.New(m)
-	if err != nil {
-		panic(errors.Wrapf(err, "unable to create telemetry instance for %s", name))
+	app, err := TryNew(name, version, opts...)
+	if err != nil && app == nil {
+		panic(err)
 	}
+	// an invalid graph is reported again, as an error, when the application starts
+	return app
+}
 
+// TryNew behaves like New, but returns an error instead of panicking when the telemetry
+// cannot be created or when the dependency graph is invalid.
+func TryNew(name, version string, opts ...opt.Option[App]) (*App, error) {
+	m := manifest.New(name, version)
+	plan := lifecycle.NewPlan()
 	app := &App{
-		manifest: m,
-		fxOptions: []fx.Option{
-			fx.Supply(m),
-			fx.WithLogger(fxLogProvider),
+		manifest:  m,
+		fxOptions: []fx.Option{},
+		activation: &Activation{
+			Manifest: m,
+			Args:     os.Args[1:],
 		},
-		telemetry: telemetryInstance,
-		Done:      make(chan struct{}),
+		Done: make(chan struct{}),
 	}
 	opt.Apply(app, opts...)
+	log := logger.FromContext(app.context(context.Background()))
+	app.fxOptions = append([]fx.Option{fx.WithLogger(fxLogProvider(log))}, app.fxOptions...)
+	activeModules, inactiveModules := activate(app.modules, app.activation)
+	for _, inactive := range inactiveModules {
+		log.Info("module deactivated",
+			props.AppModule(inactive.module.Name),
+			props.AppRole(app.activation.Role),
+			zap.String("reason", inactive.reason),
+		)
+	}
+	moduleOptions := make([]fx.Option, 0, len(activeModules))
+	for _, activeModule := range activeModules {
+		moduleOptions = append(moduleOptions, activeModule.IntoFxModule())
+	}
+	telemetryInstance, err := telemetry.New(m, app.telemetryOptions...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create telemetry instance for %s", name)
+	}
+	app.telemetry = telemetryInstance
+	app.graph = Inspect(activeModules, m, plan, telemetryInstance, app.activation, (*Graph)(nil))
+	app.fxOptions = append(append(moduleOptions, app.fxOptions...),
+		fx.Supply(m, plan, telemetryInstance, app.activation, app.graph),
+		// root invokes run after every module invoke, so every service has joined the plan by now
+		fx.Invoke(plan.Register),
+	)
 	app.di = fx.New(app.fxOptions...)
+	if err = app.di.Err(); err != nil {
+		if unmet := unmetDependencies(app.graph, inactiveModules); unmet != nil {
+			err = errors.Wrap(err, unmet.Error())
+		}
+		log.Error("application graph is invalid", props.AppName(name), zap.Error(err))
+		return app, errors.Wrapf(err, "invalid application graph for %s", name)
+	}
 	log.Info("application created",
 		props.AppName(name),
 		props.AppVersion(version),
 		props.AppStartTimeout(app.di.StartTimeout()),
 		props.AppStopTimeout(app.di.StopTimeout()),
 	)
-	return app
+	return app, nil
+}
+
+func (app *App) Manifest() *manifest.Manifest {
+	return app.manifest
+}
+
+// Graph returns the tree of modules of the application, along with the dependencies between their providers.
+func (app *App) Graph() *Graph {
+	return app.graph
+}
+
+// context makes ctx log where the application does, see LogTo.
+func (app *App) context(ctx context.Context) context.Context {
+	if app.logs == nil {
+		return ctx
+	}
+	return logger.WithCore(ctx, app.logs)
+}
+
+// Start starts every service of the application and returns, the caller is responsible for calling Stop.
+func (app *App) Start(ctx context.Context) error {
+	ctx = app.context(ctx)
+	if err := app.di.Start(ctx); err != nil {
+		return errors.Wrap(err, "failed to start application")
+	}
+	return nil
+}
+
+// Stop stops every service of the application, in the reverse order they were started.
+func (app *App) Stop(ctx context.Context) error {
+	ctx = app.context(ctx)
+	if err := app.di.Stop(ctx); err != nil {
+		return errors.Wrap(err, "failed to stop application")
+	}
+	return nil
 }
 
 func (app *App) StartBackground(ctx context.Context) error {
+	ctx = app.context(ctx)
 	log := logger.FromContext(ctx)
 	rootTracer := app.telemetry.Tracer(app.manifest.Application)
 	rootCtx, rootSpan := rootTracer.Start(ctx, "app.StartBackground")
@@ -96,7 +203,7 @@ func (app *App) StartBackground(ctx context.Context) error {
 	go func() {
 		select {
 		case <-ctx.Done():
-			stopCtx, cancel := context.WithTimeout(context.Background(), app.di.StopTimeout())
+			stopCtx, cancel := context.WithTimeout(app.context(context.Background()), app.di.StopTimeout())
 			defer cancel()
 			if err := app.di.Stop(stopCtx); err != nil {
 				rootSpan.RecordError(err)
@@ -111,9 +218,18 @@ func (app *App) StartBackground(ctx context.Context) error {
 	return nil
 }
 
+// Run starts the application and blocks until it stops, unless it was launched with the GraphCommand,
+// in which case it only prints the dependency graph.
 func (app *App) Run() {
-	ctx := context.Background()
-	log := logger.New()
+	if handled, err := app.runGraph(os.Stdout); handled {
+		if err != nil {
+			// the error was already reported by the command
+			os.Exit(1)
+		}
+		return
+	}
+	ctx := app.context(context.Background())
+	log := logger.FromContext(ctx)
 	log.Info("starting application")
 	rootTracer := app.telemetry.Tracer(app.manifest.Application)
 	rootCtx, rootSpan := rootTracer.Start(ctx, "app.StartBackground")
@@ -0,0 +1,321 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"go.uber.org/fx"
+)
+
+type ProviderKind string
+
+const (
+	ProviderKindProvide  ProviderKind = "provide"
+	ProviderKindSupply   ProviderKind = "supply"
+	ProviderKindDecorate ProviderKind = "decorate"
+	ProviderKindInvoke   ProviderKind = "invoke"
+)
+
+type (
+	// Dependency is a single value consumed or produced by a provider.
+	Dependency struct {
+		Type     string `json:"type"`
+		Name     string `json:"name,omitempty"`
+		Group    string `json:"group,omitempty"`
+		Optional bool   `json:"optional,omitempty"`
+	}
+	// Provider is any function or value registered by a Module.
+	Provider struct {
+		ID       string       `json:"id"`
+		Function string       `json:"function"`
+		Module   string       `json:"module"`
+		Kind     ProviderKind `json:"kind"`
+		Private  bool         `json:"private,omitempty"`
+		Inputs   []Dependency `json:"inputs"`
+		Outputs  []Dependency `json:"outputs"`
+		Groups   []string     `json:"groups,omitempty"`
+		Unused   bool         `json:"unused,omitempty"`
+	}
+	// ModuleNode is a Module with its providers and sub-modules.
+	ModuleNode struct {
+		Name       string        `json:"name"`
+		Providers  []*Provider   `json:"providers"`
+		SubModules []*ModuleNode `json:"subModules"`
+	}
+	// Graph is the resolved tree of modules of an application, with the dependencies between its providers.
+	Graph struct {
+		Modules []*ModuleNode `json:"modules"`
+		// External are the values provided outside any module, by the application itself or by fx.
+		External []Dependency `json:"external"`
+		// Missing are the required inputs which no provider produces.
+		Missing []Dependency `json:"missing"`
+		// Unused are the providers whose outputs are never consumed.
+		Unused []string `json:"unused"`
+	}
+)
+
+var (
+	fxInType  = reflect.TypeOf(fx.In{})
+	fxOutType = reflect.TypeOf(fx.Out{})
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+	// builtins are always available in a fx application.
+	builtins = []reflect.Type{
+		reflect.TypeOf((*fx.Lifecycle)(nil)).Elem(),
+		reflect.TypeOf((*fx.Shutdowner)(nil)).Elem(),
+		reflect.TypeOf(fx.DotGraph("")),
+	}
+)
+
+func (d Dependency) String() string {
+	switch {
+	case d.Group != "":
+		return fmt.Sprintf("%s[group:%s]", d.Type, d.Group)
+	case d.Name != "":
+		return fmt.Sprintf("%s[name:%s]", d.Type, d.Name)
+	default:
+		return d.Type
+	}
+}
+
+// key identifies a value in the container, regardless of it being optional.
+func (d Dependency) key() string {
+	return Dependency{Type: d.Type, Name: d.Name, Group: d.Group}.String()
+}
+
+func functionName(f any) string {
+	value := reflect.ValueOf(f)
+	if value.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", f)
+	}
+	fn := runtime.FuncForPC(value.Pointer())
+	if fn == nil {
+		return value.Type().String()
+	}
+	name := fn.Name()
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
+
+func dependencyFromField(field reflect.StructField) Dependency {
+	group, _, _ := strings.Cut(field.Tag.Get("group"), ",")
+	typ := field.Type
+	if group != "" && typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return Dependency{
+		Type:     typ.String(),
+		Name:     field.Tag.Get("name"),
+		Group:    group,
+		Optional: field.Tag.Get("optional") == "true",
+	}
+}
+
+// dependenciesOf expands parameter or result objects (structs embedding fx.In or fx.Out) into their fields.
+func dependenciesOf(typ reflect.Type, marker reflect.Type) []Dependency {
+	if typ.Kind() != reflect.Struct {
+		return []Dependency{{Type: typ.String()}}
+	}
+	embedsMarker := false
+	for i := 0; i < typ.NumField(); i++ {
+		if field := typ.Field(i); field.Anonymous && field.Type == marker {
+			embedsMarker = true
+		}
+	}
+	if !embedsMarker {
+		return []Dependency{{Type: typ.String()}}
+	}
+	dependencies := make([]Dependency, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		switch {
+		case field.Anonymous && field.Type == marker:
+			continue
+		case field.Anonymous:
+			dependencies = append(dependencies, dependenciesOf(field.Type, marker)...)
+		case field.IsExported():
+			dependencies = append(dependencies, dependencyFromField(field))
+		}
+	}
+	return dependencies
+}
+
+// unwrap returns the function fx will actually call for a provider, along with a human-readable name.
+// Providers wrapped by fx.Annotate are rebuilt so that their tags show up as fx.In and fx.Out fields.
+func unwrap(provider any) (any, string, string) {
+	switch p := provider.(type) {
+	case fx.Annotated:
+		return p.Target, functionName(p.Target), p.Group
+	}
+	value := reflect.ValueOf(provider)
+	if value.Kind() != reflect.Struct {
+		return provider, functionName(provider), ""
+	}
+	ptr := reflect.New(value.Type())
+	ptr.Elem().Set(value)
+	builder, ok := ptr.Interface().(interface{ Build() (any, error) })
+	if !ok {
+		return provider, functionName(provider), ""
+	}
+	name := functionName(provider)
+	if target := value.FieldByName("Target"); target.IsValid() && target.CanInterface() {
+		name = functionName(target.Interface())
+	}
+	built, err := builder.Build()
+	if err != nil {
+		return provider, name, ""
+	}
+	return built, name, ""
+}
+
+func newProvider(module string, kind ProviderKind, provider any) *Provider {
+	target, name, annotatedGroup := unwrap(provider)
+	p := &Provider{
+		Function: name,
+		Module:   module,
+		Kind:     kind,
+		Inputs:   []Dependency{},
+		Outputs:  []Dependency{},
+	}
+	typ := reflect.TypeOf(target)
+	if kind == ProviderKindSupply || typ.Kind() != reflect.Func {
+		p.Function = typ.String()
+		p.Outputs = append(p.Outputs, Dependency{Type: typ.String()})
+		return p
+	}
+	for i := 0; i < typ.NumIn(); i++ {
+		p.Inputs = append(p.Inputs, dependenciesOf(typ.In(i), fxInType)...)
+	}
+	if kind == ProviderKindProvide {
+		for i := 0; i < typ.NumOut(); i++ {
+			if typ.Out(i) == errorType {
+				continue
+			}
+			outputs := dependenciesOf(typ.Out(i), fxOutType)
+			for idx := range outputs {
+				if annotatedGroup != "" {
+					outputs[idx].Group, _, _ = strings.Cut(annotatedGroup, ",")
+				}
+			}
+			p.Outputs = append(p.Outputs, outputs...)
+		}
+	}
+	for _, output := range p.Outputs {
+		if output.Group != "" {
+			p.Groups = append(p.Groups, output.Group)
+		}
+	}
+	// closures such as the ones generated by module.Service are better described by what they consume
+	if strings.Contains(p.Function, ".func") && len(p.Inputs) > 0 {
+		p.Function = fmt.Sprintf("%s(%s)", kind, p.Inputs[0].Type)
+	}
+	return p
+}
+
+func inspectModule(m Module, counter *int) *ModuleNode {
+	node := &ModuleNode{
+		Name:       m.Name,
+		Providers:  []*Provider{},
+		SubModules: []*ModuleNode{},
+	}
+	add := func(kind ProviderKind, private bool, providers []any) {
+		for _, provider := range providers {
+			p := newProvider(m.Name, kind, provider)
+			p.Private = private
+			p.ID = fmt.Sprintf("p%d", *counter)
+			*counter++
+			node.Providers = append(node.Providers, p)
+		}
+	}
+	add(ProviderKindProvide, false, m.Public)
+	add(ProviderKindProvide, true, m.Private)
+	add(ProviderKindSupply, false, m.Instances)
+	add(ProviderKindDecorate, false, m.Decorators)
+	add(ProviderKindInvoke, false, m.Invokers)
+	for _, sub := range m.SubModules {
+		if subModule, ok := sub.(Module); ok {
+			node.SubModules = append(node.SubModules, inspectModule(subModule, counter))
+		}
+	}
+	return node
+}
+
+// Walk calls fn on every provider of the graph, depth first.
+func (g *Graph) Walk(fn func(module *ModuleNode, provider *Provider)) {
+	var walk func(nodes []*ModuleNode)
+	walk = func(nodes []*ModuleNode) {
+		for _, node := range nodes {
+			for _, provider := range node.Providers {
+				fn(node, provider)
+			}
+			walk(node.SubModules)
+		}
+	}
+	walk(g.Modules)
+}
+
+// Inspect builds the Graph of the given modules, external are the values provided outside of them.
+func Inspect(modules []Module, external ...any) *Graph {
+	g := &Graph{
+		Modules:  []*ModuleNode{},
+		External: []Dependency{},
+		Missing:  []Dependency{},
+		Unused:   []string{},
+	}
+	counter := 0
+	for _, m := range modules {
+		g.Modules = append(g.Modules, inspectModule(m, &counter))
+	}
+	for _, builtin := range builtins {
+		g.External = append(g.External, Dependency{Type: builtin.String()})
+	}
+	for _, value := range external {
+		g.External = append(g.External, Dependency{Type: reflect.TypeOf(value).String()})
+	}
+
+	provided := make(map[string]bool)
+	for _, dependency := range g.External {
+		provided[dependency.key()] = true
+	}
+	consumed := make(map[string]bool)
+	g.Walk(func(_ *ModuleNode, p *Provider) {
+		for _, output := range p.Outputs {
+			provided[output.key()] = true
+		}
+		for _, input := range p.Inputs {
+			consumed[input.key()] = true
+		}
+	})
+	missing := make(map[string]Dependency)
+	g.Walk(func(_ *ModuleNode, p *Provider) {
+		for _, input := range p.Inputs {
+			// groups are never missing, they are empty at worst
+			if input.Optional || input.Group != "" || provided[input.key()] {
+				continue
+			}
+			missing[input.key()] = input
+		}
+		if p.Kind != ProviderKindProvide && p.Kind != ProviderKindSupply {
+			return
+		}
+		used := false
+		for _, output := range p.Outputs {
+			used = used || consumed[output.key()]
+		}
+		if !used {
+			p.Unused = true
+			g.Unused = append(g.Unused, p.Function)
+		}
+	})
+	for _, dependency := range missing {
+		g.Missing = append(g.Missing, dependency)
+	}
+	sort.Slice(g.Missing, func(i, j int) bool {
+		return g.Missing[i].key() < g.Missing[j].key()
+	})
+	return g
+}
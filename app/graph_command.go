@@ -0,0 +1,43 @@
+package app
+
+import (
+	"io"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// GraphCommand is the CLI command printing the dependency graph of the application, such as
+// `myapp graph --format mermaid`. It is answered by Run before the application starts, so that it also describes
+// the graphs which fail to resolve.
+const GraphCommand = "graph"
+
+func graphCommand(graph *Graph) *cobra.Command {
+	format := string(GraphFormatDot)
+	cmd := &cobra.Command{
+		Use:     GraphCommand,
+		Short:   "Print the dependency graph of the application",
+		Long:    "Print the resolved tree of modules, the providers they declare, the value groups those feed, and the providers nobody consumes.",
+		Example: "graph --format mermaid",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return graph.Write(cmd.OutOrStdout(), GraphFormat(format))
+		},
+		SilenceUsage: true,
+	}
+	cmd.Flags().StringVarP(&format, "format", "f", format, "output format, one of [dot, json, mermaid]")
+	return cmd
+}
+
+// runGraph prints the dependency graph to out when the application was launched with the GraphCommand,
+// it reports whether it did.
+func (app *App) runGraph(out io.Writer) (bool, error) {
+	if app.activation.Command() != GraphCommand {
+		return false, nil
+	}
+	args := app.activation.Args
+	cmd := graphCommand(app.graph)
+	cmd.SetOut(out)
+	cmd.SetArgs(args[slices.Index(args, GraphCommand)+1:])
+	return true, cmd.Execute()
+}
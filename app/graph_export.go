@@ -0,0 +1,159 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+type GraphFormat string
+
+const (
+	GraphFormatDot     GraphFormat = "dot"
+	GraphFormatJson    GraphFormat = "json"
+	GraphFormatMermaid GraphFormat = "mermaid"
+)
+
+type edge struct {
+	from  string
+	to    string
+	label string
+}
+
+// edges links every provider to the providers consuming one of its outputs.
+func (g *Graph) edges() []edge {
+	producers := make(map[string][]*Provider)
+	g.Walk(func(_ *ModuleNode, p *Provider) {
+		for _, output := range p.Outputs {
+			producers[output.key()] = append(producers[output.key()], p)
+		}
+	})
+	edges := make([]edge, 0)
+	g.Walk(func(_ *ModuleNode, consumer *Provider) {
+		for _, input := range consumer.Inputs {
+			for _, producer := range producers[input.key()] {
+				if producer == consumer {
+					continue
+				}
+				edges = append(edges, edge{from: producer.ID, to: consumer.ID, label: input.String()})
+			}
+		}
+	})
+	return edges
+}
+
+func (p *Provider) label() string {
+	lines := []string{p.Function}
+	if p.Kind != ProviderKindProvide {
+		lines[0] = fmt.Sprintf("%s (%s)", p.Function, p.Kind)
+	}
+	if p.Private {
+		lines = append(lines, "private")
+	}
+	for _, group := range p.Groups {
+		lines = append(lines, "group:"+group)
+	}
+	if p.Unused {
+		lines = append(lines, "unused")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (g *Graph) writeDot(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString("digraph rodent {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	cluster := 0
+	var writeModule func(node *ModuleNode, indent string)
+	writeModule = func(node *ModuleNode, indent string) {
+		fmt.Fprintf(b, "%ssubgraph cluster_%d {\n%s\tlabel=%q;\n", indent, cluster, indent, node.Name)
+		cluster++
+		for _, p := range node.Providers {
+			style := "solid"
+			if p.Private {
+				style = "dashed"
+			}
+			color := "black"
+			if p.Unused {
+				color = "gray"
+			}
+			fmt.Fprintf(b, "%s\t%s [label=%q, style=%s, color=%s];\n", indent, p.ID, p.label(), style, color)
+		}
+		for _, sub := range node.SubModules {
+			writeModule(sub, indent+"\t")
+		}
+		fmt.Fprintf(b, "%s}\n", indent)
+	}
+	for _, node := range g.Modules {
+		writeModule(node, "\t")
+	}
+	for _, e := range g.edges() {
+		fmt.Fprintf(b, "\t%s -> %s [label=%q];\n", e.from, e.to, e.label)
+	}
+	for idx, missing := range g.Missing {
+		fmt.Fprintf(b, "\tmissing_%d [label=%q, color=red, shape=octagon];\n", idx, "missing\n"+missing.String())
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidEscape(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return strings.ReplaceAll(s, "\n", "<br/>")
+}
+
+func (g *Graph) writeMermaid(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString("flowchart LR\n")
+	cluster := 0
+	var writeModule func(node *ModuleNode, indent string)
+	writeModule = func(node *ModuleNode, indent string) {
+		fmt.Fprintf(b, "%ssubgraph m%d[\"%s\"]\n", indent, cluster, mermaidEscape(node.Name))
+		cluster++
+		for _, p := range node.Providers {
+			fmt.Fprintf(b, "%s\t%s[\"%s\"]\n", indent, p.ID, mermaidEscape(p.label()))
+			if p.Unused {
+				fmt.Fprintf(b, "%s\tclass %s unused\n", indent, p.ID)
+			}
+			if p.Private {
+				fmt.Fprintf(b, "%s\tclass %s private\n", indent, p.ID)
+			}
+		}
+		for _, sub := range node.SubModules {
+			writeModule(sub, indent+"\t")
+		}
+		fmt.Fprintf(b, "%send\n", indent)
+	}
+	for _, node := range g.Modules {
+		writeModule(node, "\t")
+	}
+	for _, e := range g.edges() {
+		fmt.Fprintf(b, "\t%s -->|\"%s\"| %s\n", e.from, mermaidEscape(e.label), e.to)
+	}
+	for idx, missing := range g.Missing {
+		fmt.Fprintf(b, "\tmissing_%d{{\"missing<br/>%s\"}}\n", idx, mermaidEscape(missing.String()))
+	}
+	b.WriteString("\tclassDef unused stroke-dasharray: 5 5,color:#888\n")
+	b.WriteString("\tclassDef private stroke-dasharray: 2 2\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Write exports the graph in the given format.
+func (g *Graph) Write(w io.Writer, format GraphFormat) error {
+	switch format {
+	case GraphFormatDot:
+		return g.writeDot(w)
+	case GraphFormatMermaid:
+		return g.writeMermaid(w)
+	case GraphFormatJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(g)
+	default:
+		return errors.Newf("unsupported graph format '%s', expected one of [dot, json, mermaid]", format)
+	}
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+type (
+	graphConfig   struct{}
+	graphStore    struct{}
+	graphHandler  struct{}
+	graphOrphan   struct{}
+	graphExternal struct{}
+	graphMissing  struct{}
+	graphServer   struct{}
+)
+
+type graphServerParams struct {
+	fx.In
+	Store    *graphStore
+	Handlers []*graphHandler `group:"graph.handler"`
+	Missing  *graphMissing   `optional:"true"`
+}
+
+func newGraphConfig() *graphConfig                           { return &graphConfig{} }
+func newGraphStore(*graphConfig, *graphExternal) *graphStore { return &graphStore{} }
+func newGraphHandler(*graphStore) *graphHandler              { return &graphHandler{} }
+func newGraphOrphan() *graphOrphan                           { return &graphOrphan{} }
+func newGraphServer(graphServerParams) *graphServer          { return &graphServer{} }
+func needsMissing(*graphMissing) *graphServer                { return nil }
+func startGraphServer(*graphServer)                          {}
+
+func testModules() []Module {
+	store := NewNamedModule("store", func(m *Module) {
+		m.Private = append(m.Private, newGraphConfig)
+		m.Public = append(m.Public, newGraphStore)
+	})
+	return []Module{
+		NewNamedModule("server", func(m *Module) {
+			m.Public = append(m.Public,
+				newGraphServer,
+				newGraphOrphan,
+				fx.Annotate(newGraphHandler, fx.ResultTags(`group:"graph.handler"`)),
+			)
+			m.Invokers = append(m.Invokers, startGraphServer)
+			m.SubModules = append(m.SubModules, store)
+		}),
+	}
+}
+
+func findProvider(g *Graph, function string) *Provider {
+	var found *Provider
+	g.Walk(func(_ *ModuleNode, p *Provider) {
+		if p.Function == function {
+			found = p
+		}
+	})
+	return found
+}
+
+func TestInspect(t *testing.T) {
+	r := require.New(t)
+	g := Inspect(testModules(), &graphExternal{})
+
+	r.Len(g.Modules, 1)
+	r.Equal("server", g.Modules[0].Name)
+	r.Len(g.Modules[0].SubModules, 1)
+	r.Equal("store", g.Modules[0].SubModules[0].Name)
+
+	config := findProvider(g, "app.newGraphConfig")
+	r.NotNil(config)
+	r.True(config.Private)
+	r.Equal("store", config.Module)
+
+	handler := findProvider(g, "app.newGraphHandler")
+	r.NotNil(handler)
+	r.Equal([]string{"graph.handler"}, handler.Groups)
+
+	server := findProvider(g, "app.newGraphServer")
+	r.NotNil(server)
+	r.Contains(server.Inputs, Dependency{Type: "*app.graphHandler", Group: "graph.handler"})
+	r.False(server.Unused)
+
+	r.Equal([]string{"app.newGraphOrphan"}, g.Unused)
+	r.Empty(g.Missing)
+}
+
+func TestInspectMissing(t *testing.T) {
+	r := require.New(t)
+	g := Inspect([]Module{
+		NewNamedModule("broken", func(m *Module) {
+			m.Public = append(m.Public, needsMissing)
+		}),
+	})
+	r.Equal([]Dependency{{Type: "*app.graphMissing"}}, g.Missing)
+}
+
+func TestGraphWrite(t *testing.T) {
+	g := Inspect(testModules(), &graphExternal{})
+	for _, format := range []GraphFormat{GraphFormatDot, GraphFormatMermaid, GraphFormatJson} {
+		t.Run(string(format), func(t *testing.T) {
+			r := require.New(t)
+			buf := &bytes.Buffer{}
+			r.NoError(g.Write(buf, format))
+			r.Contains(buf.String(), "newGraphStore")
+			if format == GraphFormatJson {
+				r.True(json.Valid(buf.Bytes()))
+			}
+		})
+	}
+	r := require.New(t)
+	r.Error(g.Write(&bytes.Buffer{}, "yaml"))
+}
+
+func TestGraphCommand(t *testing.T) {
+	r := require.New(t)
+	broken, err := TryNew("broken", "0.0.0", Modules(func() Module {
+		return NewNamedModule("broken", func(m *Module) {
+			m.Public = append(m.Public, needsMissing)
+			m.Invokers = append(m.Invokers, startGraphServer)
+		})
+	}))
+	r.Error(err)
+
+	broken.activation.Args = []string{"serve"}
+	handled, err := broken.runGraph(&bytes.Buffer{})
+	r.NoError(err)
+	r.False(handled)
+
+	buf := &bytes.Buffer{}
+	broken.activation.Args = []string{"graph", "--format", "json"}
+	handled, err = broken.runGraph(buf)
+	r.NoError(err)
+	r.True(handled)
+	r.True(json.Valid(buf.Bytes()))
+	r.Contains(buf.String(), "app.needsMissing")
+
+	broken.activation.Args = []string{"graph", "--format", "yaml"}
+	handled, err = broken.runGraph(&bytes.Buffer{})
+	r.True(handled)
+	r.Error(err)
+}
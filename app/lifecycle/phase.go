@@ -0,0 +1,33 @@
+package lifecycle
+
+import "fmt"
+
+// Phase groups services which start together, every service of a phase is started
+// before any service of a later phase, and stopped after them.
+type Phase int
+
+const (
+	// PhaseInfrastructure is meant for services every other service relies on, such as supervision or storage.
+	PhaseInfrastructure Phase = 100
+	// PhaseMigration is meant for services preparing the storage, such as schema migrations.
+	PhaseMigration Phase = 200
+	// PhaseDefault is the phase of services which do not declare one.
+	PhaseDefault Phase = 300
+	// PhaseServing is meant for services accepting traffic, such as HTTP servers.
+	PhaseServing Phase = 400
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseInfrastructure:
+		return "infrastructure"
+	case PhaseMigration:
+		return "migration"
+	case PhaseDefault:
+		return "default"
+	case PhaseServing:
+		return "serving"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
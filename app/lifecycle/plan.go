@@ -0,0 +1,215 @@
+package lifecycle
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+)
+
+type (
+	// Service is a unit of the startup plan, DependsOn references the Key of other services.
+	Service struct {
+		Name      string
+		Key       reflect.Type
+		Phase     Phase
+		DependsOn []reflect.Type
+		OnStart   func(ctx context.Context) error
+		OnStop    func(ctx context.Context) error
+	}
+	// Step is a resolved Phase, with its services in start order.
+	Step struct {
+		Phase    Phase
+		Services []*Service
+	}
+	// Plan collects services while the dependency graph is built, then registers them
+	// into the fx.Lifecycle in dependency order once sealed.
+	Plan struct {
+		mu       sync.Mutex
+		services []*Service
+		sealed   bool
+	}
+)
+
+func NewPlan() *Plan {
+	return &Plan{
+		services: []*Service{},
+	}
+}
+
+func (p *Plan) Add(service *Service) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sealed {
+		return errors.Newf("service '%s' was declared after the startup plan was resolved", service.Name)
+	}
+	for _, existing := range p.services {
+		if existing.Key == service.Key {
+			return errors.Newf("service '%s' is declared more than once", service.Name)
+		}
+	}
+	p.services = append(p.services, service)
+	return nil
+}
+
+func (p *Plan) Services() []*Service {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Service{}, p.services...)
+}
+
+// Resolve orders services by phase, then by their dependencies, services without any
+// ordering constraint keep their declaration order.
+func (p *Plan) Resolve() ([]Step, error) {
+	services := p.Services()
+	byKey := make(map[reflect.Type]*Service, len(services))
+	for _, service := range services {
+		byKey[service.Key] = service
+	}
+	byPhase := make(map[Phase][]*Service)
+	for _, service := range services {
+		for _, dependency := range service.DependsOn {
+			target, ok := byKey[dependency]
+			if !ok {
+				return nil, errors.Newf(
+					"service '%s' depends on '%s', which is not declared with module.Service",
+					service.Name, dependency,
+				)
+			}
+			if target.Phase > service.Phase {
+				return nil, errors.Newf(
+					"service '%s' (phase %s) depends on '%s' which starts in a later phase (%s)",
+					service.Name, service.Phase, target.Name, target.Phase,
+				)
+			}
+		}
+		byPhase[service.Phase] = append(byPhase[service.Phase], service)
+	}
+	phases := make([]Phase, 0, len(byPhase))
+	for phase := range byPhase {
+		phases = append(phases, phase)
+	}
+	sort.Slice(phases, func(i, j int) bool {
+		return phases[i] < phases[j]
+	})
+	steps := make([]Step, 0, len(phases))
+	for _, phase := range phases {
+		ordered, err := sortPhase(byPhase[phase])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid startup plan for phase %s", phase)
+		}
+		steps = append(steps, Step{
+			Phase:    phase,
+			Services: ordered,
+		})
+	}
+	return steps, nil
+}
+
+// sortPhase is a stable topological sort of services sharing the same phase.
+func sortPhase(services []*Service) ([]*Service, error) {
+	inPhase := make(map[reflect.Type]bool, len(services))
+	for _, service := range services {
+		inPhase[service.Key] = true
+	}
+	pending := make(map[reflect.Type]int, len(services))
+	for _, service := range services {
+		for _, dependency := range service.DependsOn {
+			if inPhase[dependency] {
+				pending[service.Key]++
+			}
+		}
+	}
+	ordered := make([]*Service, 0, len(services))
+	done := make(map[reflect.Type]bool, len(services))
+	for len(ordered) < len(services) {
+		progressed := false
+		for _, service := range services {
+			if done[service.Key] || pending[service.Key] > 0 {
+				continue
+			}
+			done[service.Key] = true
+			ordered = append(ordered, service)
+			progressed = true
+			for _, dependent := range services {
+				for _, dependency := range dependent.DependsOn {
+					if dependency == service.Key {
+						pending[dependent.Key]--
+					}
+				}
+			}
+			break
+		}
+		if !progressed {
+			cycle := make([]string, 0)
+			for _, service := range services {
+				if !done[service.Key] {
+					cycle = append(cycle, service.Name)
+				}
+			}
+			return nil, errors.Newf("dependency cycle between services: %s", strings.Join(cycle, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+// Register seals the plan and appends its services to the fx.Lifecycle in start order,
+// fx stops them in the exact reverse order.
+func (p *Plan) Register(lifecycle fx.Lifecycle) error {
+	p.mu.Lock()
+	p.sealed = true
+	p.mu.Unlock()
+	steps, err := p.Resolve()
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		registerStep(lifecycle, step)
+	}
+	return nil
+}
+
+func registerStep(lifecycle fx.Lifecycle, step Step) {
+	var startedAt, stoppedAt time.Time
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			startedAt = time.Now()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.FromContext(ctx).Info("phase stopped",
+				props.AppPhase(step.Phase.String()),
+				props.AppPhaseDuration(time.Since(stoppedAt)),
+			)
+			return nil
+		},
+	})
+	for _, service := range step.Services {
+		lifecycle.Append(fx.Hook{
+			OnStart: service.OnStart,
+			OnStop:  service.OnStop,
+		})
+	}
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.FromContext(ctx).Info("phase started",
+				props.AppPhase(step.Phase.String()),
+				props.AppPhaseDuration(time.Since(startedAt)),
+				props.AppPhaseServices(len(step.Services)),
+			)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stoppedAt = time.Now()
+			return nil
+		},
+	})
+}
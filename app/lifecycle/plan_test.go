@@ -0,0 +1,138 @@
+package lifecycle
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	serviceA struct{}
+	serviceB struct{}
+	serviceC struct{}
+	serviceD struct{}
+)
+
+func keyOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil))
+}
+
+func service[T any](name string, phase Phase, dependsOn ...reflect.Type) *Service {
+	return &Service{
+		Name:      name,
+		Key:       keyOf[T](),
+		Phase:     phase,
+		DependsOn: dependsOn,
+	}
+}
+
+func names(steps []Step) [][]string {
+	out := make([][]string, 0, len(steps))
+	for _, step := range steps {
+		stepNames := make([]string, 0, len(step.Services))
+		for _, s := range step.Services {
+			stepNames = append(stepNames, s.Name)
+		}
+		out = append(out, stepNames)
+	}
+	return out
+}
+
+func TestPlanResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		services []*Service
+		want     [][]string
+		wantErr  bool
+	}{
+		{
+			name:     "empty",
+			services: nil,
+			want:     [][]string{},
+		},
+		{
+			name: "declaration order is kept without constraints",
+			services: []*Service{
+				service[serviceA]("a", PhaseDefault),
+				service[serviceB]("b", PhaseDefault),
+			},
+			want: [][]string{{"a", "b"}},
+		},
+		{
+			name: "phases are ordered",
+			services: []*Service{
+				service[serviceA]("server", PhaseServing),
+				service[serviceB]("migrator", PhaseMigration),
+				service[serviceC]("worker", PhaseDefault),
+			},
+			want: [][]string{{"migrator"}, {"worker"}, {"server"}},
+		},
+		{
+			name: "dependencies are started first",
+			services: []*Service{
+				service[serviceA]("a", PhaseDefault, keyOf[serviceB](), keyOf[serviceC]()),
+				service[serviceB]("b", PhaseDefault, keyOf[serviceC]()),
+				service[serviceC]("c", PhaseDefault),
+				service[serviceD]("d", PhaseDefault),
+			},
+			want: [][]string{{"c", "b", "a", "d"}},
+		},
+		{
+			name: "dependency on an earlier phase",
+			services: []*Service{
+				service[serviceA]("a", PhaseServing, keyOf[serviceB]()),
+				service[serviceB]("b", PhaseInfrastructure),
+			},
+			want: [][]string{{"b"}, {"a"}},
+		},
+		{
+			name: "dependency on a later phase",
+			services: []*Service{
+				service[serviceA]("a", PhaseInfrastructure, keyOf[serviceB]()),
+				service[serviceB]("b", PhaseServing),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown dependency",
+			services: []*Service{
+				service[serviceA]("a", PhaseDefault, keyOf[serviceB]()),
+			},
+			wantErr: true,
+		},
+		{
+			name: "cycle",
+			services: []*Service{
+				service[serviceA]("a", PhaseDefault, keyOf[serviceB]()),
+				service[serviceB]("b", PhaseDefault, keyOf[serviceC]()),
+				service[serviceC]("c", PhaseDefault, keyOf[serviceA]()),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			plan := NewPlan()
+			for _, s := range tt.services {
+				r.NoError(plan.Add(s))
+			}
+			steps, err := plan.Resolve()
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+			r.NoError(err)
+			r.Equal(tt.want, names(steps))
+		})
+	}
+}
+
+func TestPlanAddTwice(t *testing.T) {
+	r := require.New(t)
+	plan := NewPlan()
+	r.NoError(plan.Add(service[serviceA]("a", PhaseDefault)))
+	r.Error(plan.Add(service[serviceA]("a", PhaseDefault)))
+}
@@ -19,6 +19,10 @@ type (
 		Decorators []any
 		Invokers   []any
 		SubModules []IModule
+		// Roles the module is active for, a module without roles is active for every role.
+		Roles []string
+		// Conditions which must all hold for the module to be active.
+		Conditions []Condition
 	}
 )
 
@@ -31,6 +35,8 @@ func NewNamedModule(name string, opts ...opt.Option[Module]) Module {
 		Decorators: []any{},
 		Invokers:   []any{},
 		SubModules: []IModule{},
+		Roles:      []string{},
+		Conditions: []Condition{},
 	}
 	opt.Apply(&mod, opts...)
 	return mod
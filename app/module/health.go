@@ -0,0 +1,90 @@
+package module
+
+import (
+	"sort"
+	"time"
+)
+
+type HealthStatus string
+
+const (
+	HealthStatusStarting HealthStatus = "starting"
+	HealthStatusHealthy  HealthStatus = "healthy"
+	HealthStatusDraining HealthStatus = "draining"
+	HealthStatusCrashed  HealthStatus = "crashed"
+)
+
+type (
+	// ServiceHealth is the health of a single service, as derived from its HealthCheckManifest.
+	ServiceHealth struct {
+		Name        string       `json:"name"`
+		Description string       `json:"description,omitempty"`
+		Status      HealthStatus `json:"status"`
+		Optional    bool         `json:"optional"`
+		StartedAt   *time.Time   `json:"startedAt,omitempty"`
+		DrainingAt  *time.Time   `json:"drainingAt,omitempty"`
+		CrashedAt   *time.Time   `json:"crashedAt,omitempty"`
+		LastError   string       `json:"lastError,omitempty"`
+	}
+	// HealthReport aggregates the health of every service known to the ServiceRegistry.
+	HealthReport struct {
+		Live     bool            `json:"live"`
+		Ready    bool            `json:"ready"`
+		Services []ServiceHealth `json:"services"`
+	}
+)
+
+func serviceHealthFromManifest(manifest HealthCheckManifest) ServiceHealth {
+	health := ServiceHealth{
+		Name:        manifest.Name,
+		Description: manifest.Description,
+		Status:      HealthStatusHealthy,
+		Optional:    manifest.Optional,
+		DrainingAt:  manifest.DrainingSince,
+		CrashedAt:   manifest.CrashedAt,
+	}
+	if manifest.LastError != nil {
+		health.LastError = manifest.LastError.Error()
+	}
+	if !manifest.StartedAt.IsZero() {
+		startedAt := manifest.StartedAt
+		health.StartedAt = &startedAt
+	}
+	switch {
+	case manifest.CrashedAt != nil && !manifest.CrashedAt.Before(manifest.StartedAt):
+		health.Status = HealthStatusCrashed
+	case manifest.StartedAt.IsZero():
+		health.Status = HealthStatusStarting
+	case manifest.DrainingSince != nil:
+		health.Status = HealthStatusDraining
+	}
+	return health
+}
+
+// Health inspects every registered service and aggregates their status.
+// The application is live as long as no required service has crashed,
+// and ready once every required service is healthy.
+func (s *ServiceRegistry) Health() HealthReport {
+	report := HealthReport{
+		Live:     true,
+		Ready:    true,
+		Services: make([]ServiceHealth, 0, len(s.services)),
+	}
+	for _, service := range s.services {
+		health := serviceHealthFromManifest(s.inspect(service))
+		report.Services = append(report.Services, health)
+		if health.Optional {
+			continue
+		}
+		if health.Status == HealthStatusCrashed {
+			report.Live = false
+		}
+		if health.Status != HealthStatusHealthy {
+			report.Ready = false
+		}
+	}
+	sort.SliceStable(report.Services, func(i, j int) bool {
+		return report.Services[i].Name < report.Services[j].Name
+	})
+	return report
+}
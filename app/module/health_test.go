@@ -0,0 +1,110 @@
+package module
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+type fakeService struct {
+	manifest HealthCheckManifest
+}
+
+func (f *fakeService) OnStart(context.Context) error { return nil }
+func (f *fakeService) OnStop(context.Context) error  { return nil }
+func (f *fakeService) Inspect() HealthCheckManifest  { return f.manifest }
+
+func TestServiceRegistryHealth(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Second)
+	earlier := now.Add(-time.Second)
+
+	tests := []struct {
+		name      string
+		manifests []HealthCheckManifest
+		live      bool
+		ready     bool
+		statuses  []HealthStatus
+	}{
+		{
+			name:      "no services",
+			manifests: nil,
+			live:      true,
+			ready:     true,
+			statuses:  []HealthStatus{},
+		},
+		{
+			name: "all healthy",
+			manifests: []HealthCheckManifest{
+				{Name: "a", StartedAt: now},
+				{Name: "b", StartedAt: now, CrashedAt: &earlier},
+			},
+			live:     true,
+			ready:    true,
+			statuses: []HealthStatus{HealthStatusHealthy, HealthStatusHealthy},
+		},
+		{
+			name: "required service starting",
+			manifests: []HealthCheckManifest{
+				{Name: "a", StartedAt: now},
+				{Name: "b"},
+			},
+			live:     true,
+			ready:    false,
+			statuses: []HealthStatus{HealthStatusHealthy, HealthStatusStarting},
+		},
+		{
+			name: "required service crashed",
+			manifests: []HealthCheckManifest{
+				{Name: "a", StartedAt: now, CrashedAt: &later, LastError: errors.Newf("boom")},
+			},
+			live:     false,
+			ready:    false,
+			statuses: []HealthStatus{HealthStatusCrashed},
+		},
+		{
+			name: "required service draining",
+			manifests: []HealthCheckManifest{
+				{Name: "a", StartedAt: now},
+				{Name: "b", StartedAt: now, DrainingSince: &later},
+			},
+			live:     true,
+			ready:    false,
+			statuses: []HealthStatus{HealthStatusHealthy, HealthStatusDraining},
+		},
+		{
+			name: "optional service crashed",
+			manifests: []HealthCheckManifest{
+				{Name: "a", StartedAt: now},
+				{Name: "b", StartedAt: now, CrashedAt: &later, Optional: true},
+			},
+			live:     true,
+			ready:    true,
+			statuses: []HealthStatus{HealthStatusHealthy, HealthStatusCrashed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			services := make(map[string]HealthCheck)
+			for _, manifest := range tt.manifests {
+				services[manifest.Name] = &fakeService{manifest: manifest}
+			}
+			registry := &ServiceRegistry{services: services}
+
+			report := registry.Health()
+			r.Equal(tt.live, report.Live)
+			r.Equal(tt.ready, report.Ready)
+			statuses := make([]HealthStatus, 0, len(report.Services))
+			for _, service := range report.Services {
+				statuses = append(statuses, service.Status)
+			}
+			r.Equal(tt.statuses, statuses)
+		})
+	}
+}
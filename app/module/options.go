@@ -37,3 +37,17 @@ func SubModules(modules ...func() app.Module) opt.Option[app.Module] {
 		})...)
 	}
 }
+
+// Roles restricts the module to the given roles, it is always active when the application runs without a role.
+func Roles(roles ...string) opt.Option[app.Module] {
+	return func(opt *app.Module) {
+		opt.Roles = append(opt.Roles, roles...)
+	}
+}
+
+// When activates the module only if every condition holds.
+func When(conditions ...app.Condition) opt.Option[app.Module] {
+	return func(opt *app.Module) {
+		opt.Conditions = append(opt.Conditions, conditions...)
+	}
+}
@@ -11,34 +11,62 @@ import (
 	"github.com/kiwiworks/rodent/system/manifest"
 )
 
+// ServiceRegistry tracks every HealthCheck service declared through Service, and supervises them:
+// crashes reported with ReportCrash are handled according to the SupervisorConfig.
 type ServiceRegistry struct {
-	services map[string]HealthCheck
-	timeouts *manifest.Timeouts
+	services   map[string]HealthCheck
+	timeouts   *manifest.Timeouts
+	supervisor *supervisor
+	cancel     context.CancelFunc
 }
 
 type ServiceRegistryParams struct {
 	fx.In
-	Services []HealthCheck
-	Manifest *manifest.Manifest
+	Manifest   *manifest.Manifest
+	Shutdowner fx.Shutdowner
+	Config     *SupervisorConfig `optional:"true"`
 }
 
-func NewServiceRegistry(params ServiceRegistryParams) (*ServiceRegistry, error) {
-	services := make(map[string]HealthCheck)
-	for _, service := range params.Services {
-		inspect := service.Inspect()
-		if _, alreadyExists := services[inspect.Name]; alreadyExists {
-			return nil, errors.Newf("duplicate service name: %s", inspect.Name)
-		}
-		services[inspect.Name] = service
-	}
+func NewServiceRegistry(params ServiceRegistryParams) *ServiceRegistry {
 	return &ServiceRegistry{
-		services: services,
-		timeouts: &params.Manifest.Timeouts,
-	}, nil
+		services:   make(map[string]HealthCheck),
+		timeouts:   &params.Manifest.Timeouts,
+		supervisor: newSupervisor(params.Config, params.Shutdowner),
+	}
+}
+
+func (s *ServiceRegistry) register(service HealthCheck) error {
+	if s == nil {
+		return nil
+	}
+	inspect := service.Inspect()
+	if _, alreadyExists := s.services[inspect.Name]; alreadyExists {
+		return errors.Newf("duplicate service name: %s", inspect.Name)
+	}
+	s.services[inspect.Name] = service
+	return nil
+}
+
+func (s *ServiceRegistry) contextFor(ctx context.Context, name string) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return s.supervisor.contextFor(ctx, name)
+}
+
+func (s *ServiceRegistry) markStarted(name string) {
+	if s == nil {
+		return
+	}
+	s.supervisor.markStarted(name)
+}
+
+func (s *ServiceRegistry) inspect(service HealthCheck) HealthCheckManifest {
+	return s.supervisor.inspect(service.Inspect())
 }
 
 func (s *ServiceRegistry) stopService(ctx context.Context, healthCheck HealthCheck) error {
-	shutdownCtx, cancel := context.WithTimeout(ctx, s.timeouts.Start)
+	shutdownCtx, cancel := context.WithTimeout(ctx, s.timeouts.Stop)
 	defer cancel()
 	if err := healthCheck.OnStop(shutdownCtx); err != nil {
 		return errors.Wrapf(err, "failed to stop service '%s'", healthCheck.Inspect().Name)
@@ -47,11 +75,13 @@ func (s *ServiceRegistry) stopService(ctx context.Context, healthCheck HealthChe
 }
 
 func (s *ServiceRegistry) startService(ctx context.Context, healthCheck HealthCheck) error {
+	name := healthCheck.Inspect().Name
 	startupCtx, cancel := context.WithTimeout(ctx, s.timeouts.Start)
 	defer cancel()
-	if err := healthCheck.OnStart(startupCtx); err != nil {
-		return errors.Wrapf(err, "failed to start service '%s'", healthCheck.Inspect().Name)
+	if err := healthCheck.OnStart(s.contextFor(startupCtx, name)); err != nil {
+		return errors.Wrapf(err, "failed to start service '%s'", name)
 	}
+	s.markStarted(name)
 	return nil
 }
 
@@ -72,9 +102,15 @@ func (s *ServiceRegistry) RestartService(ctx context.Context, name string) error
 }
 
 func (s *ServiceRegistry) OnStart(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go s.supervisor.run(ctx, s.RestartService)
 	return nil
 }
 
 func (s *ServiceRegistry) OnStop(context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
 	return nil
 }
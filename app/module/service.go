@@ -1,33 +1,45 @@
 package module
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
 	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/lifecycle"
 	"github.com/kiwiworks/rodent/logger"
 	"github.com/kiwiworks/rodent/system/opt"
 )
 
-func registerLifecycle[T OnStartStop](impl T, lifecycle fx.Lifecycle) {
-	lifecycle.Append(fx.Hook{
-		OnStart: impl.OnStart,
-		OnStop:  impl.OnStop,
-	})
-}
+type (
+	ServiceOptions struct {
+		Phase     lifecycle.Phase
+		DependsOn []reflect.Type
+	}
+	serviceParams struct {
+		fx.In
+		Lifecycle fx.Lifecycle
+		Plan      *lifecycle.Plan  `optional:"true"`
+		Registry  *ServiceRegistry `optional:"true"`
+	}
+)
 
-func registerLifecycleStart[T OnStart](impl T, lifecycle fx.Lifecycle) {
-	lifecycle.Append(fx.Hook{
-		OnStart: impl.OnStart,
-	})
+// DependsOn makes the service start after the service T, and stop before it.
+// T must also be declared with Service.
+func DependsOn[T any]() opt.Option[ServiceOptions] {
+	return func(opt *ServiceOptions) {
+		opt.DependsOn = append(opt.DependsOn, reflect.TypeOf((*T)(nil)))
+	}
 }
 
-func registerLifecycleStop[T OnStop](impl T, lifecycle fx.Lifecycle) {
-	lifecycle.Append(fx.Hook{
-		OnStop: impl.OnStop,
-	})
+// InPhase sets the lifecycle.Phase in which the service starts, services default to lifecycle.PhaseDefault.
+func InPhase(phase lifecycle.Phase) opt.Option[ServiceOptions] {
+	return func(opt *ServiceOptions) {
+		opt.Phase = phase
+	}
 }
 
 func invoke[T any](*T) {
@@ -35,41 +47,63 @@ func invoke[T any](*T) {
 	log.Info("service available", zap.String("service", fmt.Sprintf("%T", (*T)(nil))))
 }
 
-func Service[T any]() opt.Option[app.Module] {
+func registerService[T any](impl *T, options ServiceOptions, params serviceParams) error {
+	asAny := any(impl)
+	service := &lifecycle.Service{
+		Name:      fmt.Sprintf("%T", impl),
+		Key:       reflect.TypeOf(impl),
+		Phase:     options.Phase,
+		DependsOn: options.DependsOn,
+	}
+	if onStart, ok := asAny.(OnStart); ok {
+		service.OnStart = onStart.OnStart
+	}
+	if onStop, ok := asAny.(OnStop); ok {
+		service.OnStop = onStop.OnStop
+	}
+	if healthCheck, ok := asAny.(HealthCheck); ok {
+		if err := params.Registry.register(healthCheck); err != nil {
+			return err
+		}
+		service.Name = healthCheck.Inspect().Name
+		service.OnStart = func(ctx context.Context) error {
+			if err := healthCheck.OnStart(params.Registry.contextFor(ctx, service.Name)); err != nil {
+				return err
+			}
+			params.Registry.markStarted(service.Name)
+			return nil
+		}
+	}
+	if params.Plan == nil {
+		params.Lifecycle.Append(fx.Hook{
+			OnStart: service.OnStart,
+			OnStop:  service.OnStop,
+		})
+		return nil
+	}
+	return params.Plan.Add(service)
+}
+
+func Service[T any](opts ...opt.Option[ServiceOptions]) opt.Option[app.Module] {
 	concrete := new(T)
 	asAny := any(concrete)
 	switch asAny.(type) {
-	case OnStartStop:
-		return func(opt *app.Module) {
-			opt.Decorators = append(opt.Decorators, func(impl *T, lifecycle fx.Lifecycle) *T {
-				asAny := any(impl)
-				asService := asAny.(OnStartStop)
-				registerLifecycle(asService, lifecycle)
-				return impl
-			})
-			opt.Invokers = append(opt.Invokers, invoke[T])
-		}
-	case OnStart:
-		return func(opt *app.Module) {
-			opt.Decorators = append(opt.Decorators, func(impl *T, lifecycle fx.Lifecycle) *T {
-				asAny := any(impl)
-				asService := asAny.(OnStart)
-				registerLifecycleStart(asService, lifecycle)
-				return impl
-			})
-			opt.Invokers = append(opt.Invokers, invoke[T])
-		}
-	case OnStop:
-		return func(opt *app.Module) {
-			opt.Decorators = append(opt.Decorators, func(impl *T, lifecycle fx.Lifecycle) *T {
-				asAny := any(impl)
-				asService := asAny.(OnStop)
-				registerLifecycleStop(asService, lifecycle)
-				return impl
-			})
-			opt.Invokers = append(opt.Invokers, invoke[T])
-		}
+	case OnStart, OnStop:
 	default:
 		panic(fmt.Sprintf("%T is not a valid Service, it should at least implement one of `app.OnStart` or `app.OnStop`", concrete))
 	}
+	options := ServiceOptions{
+		Phase:     lifecycle.PhaseDefault,
+		DependsOn: []reflect.Type{},
+	}
+	opt.Apply(&options, opts...)
+	return func(opt *app.Module) {
+		opt.Decorators = append(opt.Decorators, func(impl *T, params serviceParams) (*T, error) {
+			if err := registerService(impl, options, params); err != nil {
+				return nil, err
+			}
+			return impl, nil
+		})
+		opt.Invokers = append(opt.Invokers, invoke[T])
+	}
 }
@@ -0,0 +1,226 @@
+package module
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+)
+
+type (
+	// Crash is emitted by a supervised service when it stopped unexpectedly.
+	Crash struct {
+		Service string
+		Err     error
+		At      time.Time
+	}
+	serviceState struct {
+		// restarting serializes the handling of the crashes of the service.
+		restarting sync.Mutex
+		startedAt  time.Time
+		crashedAt  *time.Time
+		lastError  error
+		restarts   int
+	}
+	supervisor struct {
+		config     *SupervisorConfig
+		shutdowner fx.Shutdowner
+		crashes    chan Crash
+		done       chan struct{}
+		mu         sync.RWMutex
+		states     map[string]*serviceState
+	}
+)
+
+type crashReporterKey struct{}
+
+func newSupervisor(config *SupervisorConfig, shutdowner fx.Shutdowner) *supervisor {
+	if config == nil {
+		config = DefaultSupervisorConfig()
+	}
+	return &supervisor{
+		config:     config,
+		shutdowner: shutdowner,
+		crashes:    make(chan Crash),
+		done:       make(chan struct{}),
+		states:     make(map[string]*serviceState),
+	}
+}
+
+// ReportCrash notifies the ServiceRegistry that the service which received ctx in its OnStart hook has crashed.
+// Services should call it from the goroutines they spawn, instead of only logging the error.
+func ReportCrash(ctx context.Context, err error) {
+	if reporter, ok := ctx.Value(crashReporterKey{}).(func(error)); ok {
+		reporter(err)
+		return
+	}
+	logger.FromContext(ctx).Error("unsupervised service crashed", zap.Error(err))
+}
+
+// contextFor returns a context which routes ReportCrash calls to this supervisor on behalf of the service.
+func (s *supervisor) contextFor(ctx context.Context, service string) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, crashReporterKey{}, func(err error) {
+		crash := Crash{
+			Service: service,
+			Err:     err,
+			At:      time.Now(),
+		}
+		s.markCrashed(crash)
+		select {
+		case s.crashes <- crash:
+		case <-s.done:
+		}
+	})
+}
+
+func (s *supervisor) state(service string) *serviceState {
+	state, ok := s.states[service]
+	if !ok {
+		state = &serviceState{}
+		s.states[service] = state
+	}
+	return state
+}
+
+func (s *supervisor) markStarted(service string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state(service).startedAt = time.Now()
+}
+
+func (s *supervisor) markCrashed(crash Crash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state(crash.Service)
+	state.crashedAt = &crash.At
+	state.lastError = crash.Err
+}
+
+// resetBudget restores the restart budget of a service which ran for the stable window before it crashed.
+func (s *supervisor) resetBudget(crash Crash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state(crash.Service)
+	if s.config.StableAfter > 0 && !state.startedAt.IsZero() && crash.At.Sub(state.startedAt) >= s.config.StableAfter {
+		state.restarts = 0
+	}
+}
+
+// handled reports whether the service was restarted since the crash, as when it crashed several times in a row.
+func (s *supervisor) handled(crash Crash) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state(crash.Service).startedAt.After(crash.At)
+}
+
+// nextRestart consumes one restart from the service budget, it returns false once the budget is exhausted.
+func (s *supervisor) nextRestart(service string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state(service)
+	if s.config.MaxRestarts > 0 && state.restarts >= s.config.MaxRestarts {
+		return state.restarts, false
+	}
+	state.restarts++
+	return state.restarts, true
+}
+
+// inspect merges what the supervisor observed with the manifest reported by the service itself.
+func (s *supervisor) inspect(manifest HealthCheckManifest) HealthCheckManifest {
+	if s == nil {
+		return manifest
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.states[manifest.Name]
+	if !ok {
+		return manifest
+	}
+	if state.startedAt.After(manifest.StartedAt) {
+		manifest.StartedAt = state.startedAt
+	}
+	if state.crashedAt != nil && (manifest.CrashedAt == nil || state.crashedAt.After(*manifest.CrashedAt)) {
+		manifest.CrashedAt = state.crashedAt
+		manifest.LastError = state.lastError
+	}
+	return manifest
+}
+
+func (s *supervisor) shutdown(ctx context.Context) {
+	log := logger.FromContext(ctx)
+	if err := s.shutdowner.Shutdown(fx.ExitCode(1)); err != nil {
+		log.Error("failed to shutdown application", zap.Error(err))
+	}
+}
+
+// run dispatches crashes to restart until ctx is done.
+func (s *supervisor) run(ctx context.Context, restart func(ctx context.Context, service string) error) {
+	defer close(s.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case crash := <-s.crashes:
+			go s.handleCrash(ctx, crash, restart)
+		}
+	}
+}
+
+func (s *supervisor) handleCrash(ctx context.Context, crash Crash, restart func(ctx context.Context, service string) error) {
+	log := logger.FromContext(ctx).With(props.ServiceName(crash.Service))
+	policy := s.config.PolicyFor(crash.Service)
+	log.Error("service crashed", zap.Error(crash.Err), zap.Stringer("service.restart.policy", policy))
+
+	switch policy {
+	case RestartPolicyIgnore:
+		return
+	case RestartPolicyShutdown:
+		s.shutdown(ctx)
+		return
+	}
+	s.mu.Lock()
+	state := s.state(crash.Service)
+	s.mu.Unlock()
+	state.restarting.Lock()
+	defer state.restarting.Unlock()
+	if s.handled(crash) {
+		log.Debug("service already restarted since the crash")
+		return
+	}
+	s.resetBudget(crash)
+	for {
+		attempt, ok := s.nextRestart(crash.Service)
+		if !ok {
+			log.Error("service restart budget exhausted, shutting down", props.ServiceRestartAttempt(attempt))
+			s.shutdown(ctx)
+			return
+		}
+		delay := s.config.Backoff.Delay(attempt)
+		log.Warn("restarting service", props.ServiceRestartAttempt(attempt), props.ServiceRestartBackoff(delay))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		err := restart(ctx, crash.Service)
+		if err == nil {
+			return
+		}
+		s.markCrashed(Crash{
+			Service: crash.Service,
+			Err:     err,
+			At:      time.Now(),
+		})
+	}
+}
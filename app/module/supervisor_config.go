@@ -0,0 +1,126 @@
+package module
+
+import (
+	"math"
+	"time"
+
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// RestartPolicy defines how the ServiceRegistry reacts when a service reports a crash.
+type RestartPolicy int
+
+const (
+	// RestartPolicyRestart restarts the crashed service with an exponential backoff.
+	RestartPolicyRestart RestartPolicy = iota
+	// RestartPolicyIgnore only records the crash, the service stays down.
+	RestartPolicyIgnore
+	// RestartPolicyShutdown shuts the whole application down.
+	RestartPolicyShutdown
+)
+
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartPolicyRestart:
+		return "restart"
+	case RestartPolicyIgnore:
+		return "ignore"
+	case RestartPolicyShutdown:
+		return "shutdown"
+	default:
+		return "unknown"
+	}
+}
+
+type (
+	// Backoff computes the delay to wait before each restart attempt.
+	Backoff struct {
+		Initial    time.Duration
+		Max        time.Duration
+		Multiplier float64
+	}
+	SupervisorConfig struct {
+		Backoff Backoff
+		// MaxRestarts is the restart budget of each service, once exhausted the application is shut down.
+		// A value of zero or less means an unlimited budget.
+		MaxRestarts int
+		// StableAfter is how long a service must run without crashing for its restart budget to be restored.
+		// A value of zero or less never restores the budget.
+		StableAfter   time.Duration
+		DefaultPolicy RestartPolicy
+		Policies      map[string]RestartPolicy
+	}
+)
+
+// Delay returns the delay before the given restart attempt, attempts start at 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := float64(b.Initial) * math.Pow(b.Multiplier, float64(attempt-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		return b.Max
+	}
+	return time.Duration(delay)
+}
+
+func DefaultSupervisorConfig() *SupervisorConfig {
+	return &SupervisorConfig{
+		Backoff: Backoff{
+			Initial:    time.Second,
+			Max:        time.Second * 30,
+			Multiplier: 2,
+		},
+		MaxRestarts:   5,
+		StableAfter:   time.Minute * 5,
+		DefaultPolicy: RestartPolicyRestart,
+		Policies:      map[string]RestartPolicy{},
+	}
+}
+
+func NewSupervisorConfig(opts ...opt.Option[SupervisorConfig]) *SupervisorConfig {
+	cfg := DefaultSupervisorConfig()
+	opt.Apply(cfg, opts...)
+	return cfg
+}
+
+func (c *SupervisorConfig) PolicyFor(service string) RestartPolicy {
+	if policy, ok := c.Policies[service]; ok {
+		return policy
+	}
+	return c.DefaultPolicy
+}
+
+func RestartBackoff(initial, max time.Duration, multiplier float64) opt.Option[SupervisorConfig] {
+	return func(opt *SupervisorConfig) {
+		opt.Backoff = Backoff{
+			Initial:    initial,
+			Max:        max,
+			Multiplier: multiplier,
+		}
+	}
+}
+
+func MaxRestarts(budget int) opt.Option[SupervisorConfig] {
+	return func(opt *SupervisorConfig) {
+		opt.MaxRestarts = budget
+	}
+}
+
+func RestartBudgetReset(stableAfter time.Duration) opt.Option[SupervisorConfig] {
+	return func(opt *SupervisorConfig) {
+		opt.StableAfter = stableAfter
+	}
+}
+
+func DefaultRestartPolicy(policy RestartPolicy) opt.Option[SupervisorConfig] {
+	return func(opt *SupervisorConfig) {
+		opt.DefaultPolicy = policy
+	}
+}
+
+func ServiceRestartPolicy(service string, policy RestartPolicy) opt.Option[SupervisorConfig] {
+	return func(opt *SupervisorConfig) {
+		opt.Policies[service] = policy
+	}
+}
@@ -0,0 +1,42 @@
+package module
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{
+		Initial:    time.Second,
+		Max:        time.Second * 5,
+		Multiplier: 2,
+	}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: time.Second * 2},
+		{attempt: 3, want: time.Second * 4},
+		{attempt: 4, want: time.Second * 5},
+		{attempt: 10, want: time.Second * 5},
+	}
+
+	r := require.New(t)
+	for _, tt := range tests {
+		r.Equal(tt.want, backoff.Delay(tt.attempt), "Delay(%d)", tt.attempt)
+	}
+}
+
+func TestSupervisorConfigPolicyFor(t *testing.T) {
+	r := require.New(t)
+	cfg := NewSupervisorConfig(
+		DefaultRestartPolicy(RestartPolicyIgnore),
+		ServiceRestartPolicy("web.server", RestartPolicyShutdown),
+	)
+	r.Equal(RestartPolicyShutdown, cfg.PolicyFor("web.server"))
+	r.Equal(RestartPolicyIgnore, cfg.PolicyFor("other"))
+}
@@ -0,0 +1,94 @@
+package module
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+type fakeShutdowner struct {
+	calls atomic.Int32
+}
+
+func (f *fakeShutdowner) Shutdown(...fx.ShutdownOption) error {
+	f.calls.Add(1)
+	return nil
+}
+
+func testSupervisor() (*supervisor, *fakeShutdowner) {
+	shutdowner := &fakeShutdowner{}
+	config := NewSupervisorConfig(RestartBackoff(time.Millisecond, time.Millisecond, 1), MaxRestarts(2))
+	return newSupervisor(config, shutdowner), shutdowner
+}
+
+func TestSupervisorRestartBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		uptime    time.Duration
+		restarts  int
+		failures  int
+		shutdowns int32
+		attempts  int
+	}{
+		{name: "restarted", failures: 0, attempts: 1},
+		{name: "restarted after a failure", failures: 1, attempts: 2},
+		{name: "budget exhausted", failures: 2, shutdowns: 1, attempts: 2},
+		{name: "budget already spent", restarts: 2, uptime: time.Minute, shutdowns: 1},
+		{name: "budget restored after the stable window", restarts: 2, uptime: time.Minute * 10, attempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			s, shutdowner := testSupervisor()
+			now := time.Now()
+			state := s.state("service")
+			state.startedAt = now.Add(-tt.uptime)
+			state.restarts = tt.restarts
+
+			attempts := 0
+			s.handleCrash(context.Background(), Crash{Service: "service", At: now}, func(ctx context.Context, service string) error {
+				attempts++
+				if attempts <= tt.failures {
+					return errors.Newf("failed to start %s", service)
+				}
+				s.markStarted(service)
+				return nil
+			})
+			r.Equal(tt.attempts, attempts)
+			r.Equal(tt.shutdowns, shutdowner.calls.Load())
+		})
+	}
+}
+
+func TestSupervisorSerializesRestarts(t *testing.T) {
+	r := require.New(t)
+	s, shutdowner := testSupervisor()
+	s.markStarted("service")
+	crash := Crash{Service: "service", At: time.Now()}
+
+	var restarts atomic.Int32
+	restart := func(ctx context.Context, service string) error {
+		restarts.Add(1)
+		time.Sleep(time.Millisecond * 10)
+		s.markStarted(service)
+		return nil
+	}
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handleCrash(context.Background(), crash, restart)
+		}()
+	}
+	wg.Wait()
+	r.EqualValues(1, restarts.Load())
+	r.Zero(shutdowner.calls.Load())
+}
@@ -8,9 +8,13 @@ import (
 type HealthCheckManifest struct {
 	Name        string
 	Description string
-	StartedAt   time.Time
-	CrashedAt   *time.Time
-	LastError   error
+	// Optional services are reported but never fail the readiness or liveness of the application.
+	Optional  bool
+	StartedAt time.Time
+	// DrainingSince is set once the service started draining its work, it stops being ready from then on.
+	DrainingSince *time.Time
+	CrashedAt     *time.Time
+	LastError     error
 }
 
 type (
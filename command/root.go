@@ -8,6 +8,7 @@ import (
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"github.com/kiwiworks/rodent/app"
 	"github.com/kiwiworks/rodent/errors"
 	"github.com/kiwiworks/rodent/logger"
 	"github.com/kiwiworks/rodent/system/manifest"
@@ -21,9 +22,10 @@ type Root struct {
 
 type RootParams struct {
 	fx.In
-	Manifest *manifest.Manifest
-	Shutdown fx.Shutdowner
-	Commands []*Command `group:"command"`
+	Manifest   *manifest.Manifest
+	Shutdown   fx.Shutdowner
+	Commands   []*Command      `group:"command"`
+	Activation *app.Activation `optional:"true"`
 }
 
 func NewRoot(params RootParams) (*Root, error) {
@@ -33,6 +35,9 @@ func NewRoot(params RootParams) (*Root, error) {
 		Use:     params.Manifest.Application,
 		Version: params.Manifest.Version.String(),
 	}
+	if params.Activation != nil && params.Activation.Flag != "" {
+		rootCmd.PersistentFlags().String(params.Activation.Flag, params.Activation.Role, "role the application runs as")
+	}
 	allCommands := make(map[string]*Command)
 	allCobraCommands := make(map[string]*cobra.Command)
 	edges := make(map[string][]string)
@@ -77,12 +82,29 @@ func NewRoot(params RootParams) (*Root, error) {
 		}
 	}
 
+	if params.Activation != nil {
+		params.Activation.Invoked = invoked(rootCmd, params.Activation.Args)
+	}
+
 	return &Root{
 		root:       rootCmd,
 		shutdowner: params.Shutdown,
 	}, nil
 }
 
+// invoked resolves the path of the command the arguments run, it is nil when they do not resolve to a command.
+func invoked(root *cobra.Command, args []string) []string {
+	cmd, _, err := root.Find(args)
+	if err != nil {
+		return nil
+	}
+	path := make([]string, 0)
+	for ; cmd != nil && cmd != root; cmd = cmd.Parent() {
+		path = append([]string{cmd.Name()}, path...)
+	}
+	return path
+}
+
 func (r *Root) OnStart(ctx context.Context) error {
 	log := logger.FromContext(ctx)
 	ctx, r.cancel = context.WithCancel(context.Background())
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/system/manifest"
+)
+
+func TestNewRootResolvesInvokedCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		invoked []string
+	}{
+		{name: "root", invoked: []string{}},
+		{name: "command", args: []string{"migrate", "down"}, invoked: []string{"migrate", "down"}},
+		{name: "after role flag", args: []string{"--role", "worker", "serve"}, invoked: []string{"serve"}},
+		{name: "after value flag", args: []string{"--config", "prod.yaml", "migrate", "down"}, invoked: []string{"migrate", "down"}},
+		{name: "arguments", args: []string{"migrate", "create", "add_users"}, invoked: []string{"migrate", "create"}},
+		{name: "unknown", args: []string{"deploy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activation := &app.Activation{Args: tt.args, Flag: "role"}
+			_, err := NewRoot(RootParams{
+				Manifest: manifest.New("test", "1.0.0"),
+				Commands: []*Command{
+					New("serve", "", ""),
+					New("migrate", "", ""),
+					New("migrate.down", "", ""),
+					New("migrate.create", "", ""),
+				},
+				Activation: activation,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.invoked, activation.Invoked)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// PublicIDField is the column of the public IDs of the mixins.Resource schemas.
+const PublicIDField = "public_id"
+
+// FindByPublicID returns the row of the query with the public ID, or an errors.NotFoundError named after the entity,
+// such as `entity.FindByPublicID(ctx, client.User.Query(), id)`. The id is an uuid.UUID, or a string for the
+// prefixed IDs.
+func FindByPublicID[T any](ctx context.Context, query interface {
+	Only(ctx context.Context) (T, error)
+}, id any) (T, error) {
+	var zero T
+	if !Where(query, sql.FieldEQ(PublicIDField, id)) {
+		return zero, errors.Newf("query %T cannot be filtered by public ID", query)
+	}
+	row, err := query.Only(ctx)
+	if IsNotFound(err) {
+		return zero, errors.NotFoundError{
+			Entity:              Name[T](),
+			RequestedIdentifier: fmt.Sprint(id),
+		}
+	}
+	if err != nil {
+		return zero, errors.Wrapf(err, "failed to find %s %v", Name[T](), id)
+	}
+	return row, nil
+}
+
+// Name returns the name of the entity type, such as `User` for *ent.User.
+func Name[T any]() string {
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// IsNotFound reports whether the error is, or wraps, the NotFoundError of a generated package.
+func IsNotFound(err error) bool {
+	return isGenerated(err, "NotFoundError")
+}
+
+// IsConstraintError reports whether the error is, or wraps, the ConstraintError of a generated package,
+// such as a unique constraint violation.
+func IsConstraintError(err error) bool {
+	return isGenerated(err, "ConstraintError")
+}
+
+// IsValidationError reports whether the error is, or wraps, the ValidationError of a generated package.
+func IsValidationError(err error) bool {
+	return isGenerated(err, "ValidationError")
+}
+
+// isGenerated walks the chain of the error for the generated error type, which differs for each generated package.
+func isGenerated(err error, name string) bool {
+	switch err := err.(type) {
+	case nil:
+		return false
+	case interface{ Unwrap() []error }:
+		for _, err := range err.Unwrap() {
+			if isGenerated(err, name) {
+				return true
+			}
+		}
+		return false
+	default:
+		if t := reflect.TypeOf(err); t.Kind() == reflect.Pointer && t.Elem().Name() == name {
+			return true
+		}
+		if wrapper, ok := err.(interface{ Unwrap() error }); ok {
+			return isGenerated(wrapper.Unwrap(), name)
+		}
+		return false
+	}
+}
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type (
+	// Prefix names the prefix of a kind of prefixed IDs.
+	Prefix interface {
+		Prefix() string
+	}
+	// PublicID is a prefixed ID as a request parameter, its OpenAPI schema carries the pattern of the prefix, and its
+	// checksum is validated along with the request.
+	//
+	//	type UserPrefix struct{}
+	//
+	//	func (UserPrefix) Prefix() string { return "usr" }
+	//
+	//	type GetUser struct {
+	//		ID entity.PublicID[UserPrefix] `path:"id"`
+	//	}
+	PublicID[P Prefix] string
+)
+
+func (PublicID[P]) Schema(huma.Registry) *huma.Schema {
+	var prefix P
+	return &huma.Schema{
+		Type:        huma.TypeString,
+		Pattern:     PrefixedIDPattern(prefix.Prefix()),
+		Description: "A public ID prefixed by " + prefix.Prefix() + "_",
+		Examples:    []any{NewPrefixedID(prefix.Prefix())},
+	}
+}
+
+func (id *PublicID[P]) Resolve(_ huma.Context, path *huma.PathBuffer) []error {
+	var prefix P
+	if err := ValidatePrefixedID(prefix.Prefix(), string(*id)); err != nil {
+		return []error{&huma.ErrorDetail{
+			Message:  err.Error(),
+			Location: path.String(),
+			Value:    string(*id),
+		}}
+	}
+	return nil
+}
+
+func (id PublicID[P]) String() string {
+	return string(id)
+}
@@ -0,0 +1,120 @@
+package entity
+
+import (
+	"hash/crc32"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+const (
+	// crockford is the base32 alphabet of Douglas Crockford, without the letters mistaken for digits.
+	crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// encodedUUIDLength is how many base32 characters encode the 128 bits of an UUID.
+	encodedUUIDLength = 26
+	checksumLength    = 2
+)
+
+var validPrefix = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// NewSortableUUID returns an UUIDv7, which are ordered by their creation time, unlike the random UUIDv4.
+// Inserting them keeps the index of the column compact.
+func NewSortableUUID() uuid.UUID {
+	return uuid.Must(uuid.NewV7())
+}
+
+// ValidatePrefix checks the prefix of prefixed IDs, a lower case word such as `usr`.
+func ValidatePrefix(prefix string) error {
+	if !validPrefix.MatchString(prefix) {
+		return errors.Newf("invalid public ID prefix '%s', it must be lower case letters and digits", prefix)
+	}
+	return nil
+}
+
+// NewPrefixedID returns a Stripe-like ID, such as `usr_01HZX3C8V6J0T9Q2K8M1N4R5S7AB`: the prefix names the kind
+// of resource, followed by an UUIDv7 encoded in base32, so that the IDs sort by their creation time,
+// and by a checksum of the whole ID, so that the mistyped IDs are rejected without a lookup.
+func NewPrefixedID(prefix string) string {
+	id := NewSortableUUID()
+	body := prefix + "_" + encodeUUID(id)
+	return body + checksum(body)
+}
+
+// ParsePrefixedID checks the prefix and the checksum of a prefixed ID, and returns the UUID it encodes.
+func ParsePrefixedID(prefix string, id string) (uuid.UUID, error) {
+	body, found := strings.CutPrefix(id, prefix+"_")
+	if !found {
+		return uuid.Nil, errors.Newf("public ID '%s' does not start with '%s_'", id, prefix)
+	}
+	if len(body) != encodedUUIDLength+checksumLength {
+		return uuid.Nil, errors.Newf("public ID '%s' is not %d characters long after its prefix", id, encodedUUIDLength+checksumLength)
+	}
+	encoded, sum := body[:encodedUUIDLength], body[encodedUUIDLength:]
+	if checksum(prefix+"_"+encoded) != sum {
+		return uuid.Nil, errors.Newf("public ID '%s' has an invalid checksum", id)
+	}
+	decoded, ok := decodeUUID(encoded)
+	if !ok {
+		return uuid.Nil, errors.Newf("public ID '%s' is not base32 encoded", id)
+	}
+	return decoded, nil
+}
+
+// ValidatePrefixedID checks the prefix and the checksum of a prefixed ID.
+func ValidatePrefixedID(prefix string, id string) error {
+	_, err := ParsePrefixedID(prefix, id)
+	return err
+}
+
+// PrefixedIDPattern is the regular expression matching the prefixed IDs, for the OpenAPI schemas.
+func PrefixedIDPattern(prefix string) string {
+	return "^" + regexp.QuoteMeta(prefix) + "_[0-9A-HJKMNP-TV-Z]{" + strconv.Itoa(encodedUUIDLength+checksumLength) + "}$"
+}
+
+// encodeUUID encodes the 128 bits of the UUID as 26 base32 characters, most significant first, the first character
+// carries 2 leading zero bits so that the encoded IDs sort like the UUIDs.
+func encodeUUID(id uuid.UUID) string {
+	var encoded [encodedUUIDLength]byte
+	for i := range encoded {
+		var value byte
+		for bit := 0; bit < 5; bit++ {
+			value <<= 1
+			if position := i*5 + bit - 2; position >= 0 && id[position/8]&(0x80>>(position%8)) != 0 {
+				value |= 1
+			}
+		}
+		encoded[i] = crockford[value]
+	}
+	return string(encoded[:])
+}
+
+func decodeUUID(encoded string) (uuid.UUID, bool) {
+	var id uuid.UUID
+	for i := 0; i < len(encoded); i++ {
+		value := strings.IndexByte(crockford, encoded[i])
+		if value < 0 {
+			return uuid.Nil, false
+		}
+		for bit := 0; bit < 5; bit++ {
+			if value&(0x10>>bit) == 0 {
+				continue
+			}
+			position := i*5 + bit - 2
+			if position < 0 {
+				return uuid.Nil, false
+			}
+			id[position/8] |= 0x80 >> (position % 8)
+		}
+	}
+	return id, true
+}
+
+// checksum is a CRC-32 of the ID, truncated to 2 base32 characters.
+func checksum(body string) string {
+	sum := crc32.ChecksumIEEE([]byte(body)) % (32 * 32)
+	return string([]byte{crockford[sum/32], crockford[sum%32]})
+}
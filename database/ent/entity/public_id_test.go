@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type userPrefix struct{}
+
+func (userPrefix) Prefix() string { return "usr" }
+
+func TestPrefixedID(t *testing.T) {
+	r := require.New(t)
+	id := NewPrefixedID("usr")
+	r.Regexp(regexp.MustCompile(PrefixedIDPattern("usr")), id)
+
+	parsed, err := ParsePrefixedID("usr", id)
+	r.NoError(err)
+	r.Equal(id, "usr_"+encodeUUID(parsed)+checksum("usr_"+encodeUUID(parsed)))
+	r.EqualValues(7, parsed.Version())
+
+	later := NewPrefixedID("usr")
+	r.Less(id, later)
+
+	id = fixedID()
+	r.NoError(ValidatePrefixedID("usr", id))
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{name: "other prefix", id: "org" + strings.TrimPrefix(id, "usr")},
+		{name: "mistyped", id: id[:10] + flip(id[10]) + id[11:]},
+		{name: "truncated", id: id[:len(id)-1]},
+		{name: "not base32", id: id[:10] + "U" + id[11:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, ValidatePrefixedID("usr", tt.id))
+		})
+	}
+}
+
+// fixedID is a fixed prefixed ID, so that the checksum of its altered versions cannot match by chance.
+func fixedID() string {
+	body := "usr_" + encodeUUID(uuid.MustParse("01926f3a-1f4e-7c2a-9b3d-5e6f7a8b9c0d"))
+	return body + checksum(body)
+}
+
+func flip(c byte) string {
+	if c == '0' {
+		return "1"
+	}
+	return "0"
+}
+
+func TestEncodeUUID(t *testing.T) {
+	for range 100 {
+		id := NewSortableUUID()
+		decoded, ok := decodeUUID(encodeUUID(id))
+		require.True(t, ok)
+		require.Equal(t, id, decoded)
+	}
+}
+
+func TestPublicIDParam(t *testing.T) {
+	r := require.New(t)
+	_, api := humatest.New(t)
+	huma.Get(api, "/users/{id}", func(ctx context.Context, request *struct {
+		ID PublicID[userPrefix] `path:"id"`
+	}) (*struct{}, error) {
+		return &struct{}{}, nil
+	})
+	param := api.OpenAPI().Paths["/users/{id}"].Get.Parameters[0]
+	r.Equal(PrefixedIDPattern("usr"), param.Schema.Pattern)
+
+	test := humatest.Wrap(t, api)
+	id := fixedID()
+	r.Equal(204, test.Get("/users/"+id).Code)
+	r.Equal(422, test.Get("/users/"+id[:10]+flip(id[10])+id[11:]).Code)
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"reflect"
+
+	"entgo.io/ent/dialect/sql"
+)
+
+// wherer is implemented by the generated mutations.
+type wherer interface {
+	WhereP(...func(*sql.Selector))
+}
+
+// Where adds the predicate to a generated query or mutation, the queries only accept their typed predicates,
+// such as `predicate.User`, which are all functions of *sql.Selector.
+func Where(q any, predicate func(*sql.Selector)) bool {
+	if w, ok := q.(wherer); ok {
+		w.WhereP(predicate)
+		return true
+	}
+	return callTyped(q, "Where", predicate)
+}
+
+// Order adds the ordering to a generated query, such as `sql.OrderByField("id").ToFunc()`.
+func Order(q any, order func(*sql.Selector)) bool {
+	return callTyped(q, "Order", order)
+}
+
+// callTyped calls the variadic method of the query with the function converted to the typed functions the method
+// accepts, such as `user.OrderOption`.
+func callTyped(q any, name string, fn func(*sql.Selector)) bool {
+	method := reflect.ValueOf(q).MethodByName(name)
+	if !method.IsValid() || !method.Type().IsVariadic() || method.Type().NumIn() != 1 {
+		return false
+	}
+	typed := method.Type().In(0).Elem()
+	if !reflect.TypeOf(fn).ConvertibleTo(typed) {
+		return false
+	}
+	method.Call([]reflect.Value{reflect.ValueOf(fn).Convert(typed)})
+	return true
+}
@@ -0,0 +1,201 @@
+package mixins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/mixin"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+const insertHistory = `INSERT INTO rodent_audit_history (entity, entity_id, operation, actor, before, after) VALUES ($1, $2, $3, $4, $5, $6)`
+
+type (
+	// AuditHistory stores the before and after values of the changed fields of each mutation in
+	// the rodent_audit_history table, along with the actor of the mutation, register migration.AuditHistoryMigration
+	// to create it.
+	// The entries are written through the client of the mutation, which requires the `sql/execquery` feature of entc,
+	// they are only written atomically with the mutation when it runs within an ent transaction.
+	// The before values are only known to the mutations of a single row, such as UpdateOne.
+	// The soft-deletions of the SoftDeletable schemas are recorded as the updates of their `deleted_at`.
+	AuditHistory struct {
+		mixin.Schema
+	}
+	// change is a history entry of a single row.
+	change struct {
+		id     string
+		before map[string]any
+		after  map[string]any
+	}
+)
+
+func (AuditHistory) Hooks() []ent.Hook {
+	return []ent.Hook{
+		func(next ent.Mutator) ent.Mutator {
+			return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+				op := m.Op()
+				changes, err := collectChanges(ctx, m)
+				if err != nil {
+					return nil, err
+				}
+				value, err := next.Mutate(ctx, m)
+				if err != nil {
+					return nil, err
+				}
+				if op.Is(ent.OpDelete|ent.OpDeleteOne) && m.Op() != op {
+					// SoftDeletable turned the deletion into an update, whose history is written by the nested mutation
+					return value, nil
+				}
+				if m.Op().Is(ent.OpCreate) {
+					// the identifier of a created row is only known once it is inserted
+					ids, err := mutationIDs(ctx, m)
+					if err != nil {
+						return nil, err
+					}
+					if len(ids) == 1 {
+						changes[0].id = ids[0]
+					}
+				}
+				if err = writeHistory(ctx, m, changes); err != nil {
+					return nil, err
+				}
+				return value, nil
+			})
+		},
+	}
+}
+
+// collectChanges reads the changes before the mutation runs, so that the old values and the affected rows are
+// the ones the mutation is about to change.
+func collectChanges(ctx context.Context, m ent.Mutation) ([]change, error) {
+	after := make(map[string]any)
+	for _, name := range m.Fields() {
+		after[name], _ = m.Field(name)
+	}
+	for _, name := range m.ClearedFields() {
+		after[name] = nil
+	}
+	switch {
+	case m.Op().Is(ent.OpCreate):
+		return []change{{after: after}}, nil
+	case m.Op().Is(ent.OpUpdateOne):
+		ids, err := mutationIDs(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		before := make(map[string]any, len(after))
+		for name := range after {
+			old, err := m.OldField(ctx, name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read the old %s of %s", name, m.Type())
+			}
+			before[name] = old
+		}
+		before, after = diff(before, after)
+		if len(after) == 0 || len(ids) == 0 {
+			return nil, nil
+		}
+		return []change{{id: ids[0], before: before, after: after}}, nil
+	default:
+		ids, err := mutationIDs(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		changes := make([]change, 0, len(ids))
+		for _, id := range ids {
+			changes = append(changes, change{id: id, after: after})
+		}
+		return changes, nil
+	}
+}
+
+// diff only keeps the fields whose value changed.
+func diff(before, after map[string]any) (map[string]any, map[string]any) {
+	changedBefore := make(map[string]any, len(before))
+	changedAfter := make(map[string]any, len(after))
+	for name, value := range after {
+		old, known := before[name]
+		if known && reflect.DeepEqual(old, value) {
+			continue
+		}
+		changedAfter[name] = value
+		if known {
+			changedBefore[name] = old
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+// mutationIDs formats the identifiers of the rows of the mutation, from the generated ID or IDs methods.
+func mutationIDs(ctx context.Context, m ent.Mutation) ([]string, error) {
+	if m.Op().Is(ent.OpCreate) {
+		if id := mutationID(m); id != "" {
+			return []string{id}, nil
+		}
+		return nil, nil
+	}
+	method := reflect.ValueOf(m).MethodByName("IDs")
+	if !method.IsValid() {
+		return nil, errors.Newf("mutation %T has no IDs", m)
+	}
+	out := method.Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, errors.Wrapf(err, "failed to read the identifiers of the %s rows", m.Type())
+	}
+	ids := make([]string, 0, out[0].Len())
+	for i := 0; i < out[0].Len(); i++ {
+		ids = append(ids, fmt.Sprint(out[0].Index(i).Interface()))
+	}
+	return ids, nil
+}
+
+// writeHistory inserts the entries through the ExecContext method the `sql/execquery` feature adds to the clients,
+// the client of the mutation runs within its transaction, if any.
+func writeHistory(ctx context.Context, m ent.Mutation, changes []change) error {
+	client := reflect.ValueOf(m).MethodByName("Client")
+	if !client.IsValid() {
+		return errors.Newf("mutation %T has no client to write the history with", m)
+	}
+	exec := client.Call(nil)[0].MethodByName("ExecContext")
+	if !exec.IsValid() {
+		return errors.Newf("the client of mutation %T cannot execute statements, enable the sql/execquery feature", m)
+	}
+	actor := ActorFromContext(ctx)
+	for _, c := range changes {
+		before, err := marshalFields(c.before)
+		if err != nil {
+			return errors.Wrapf(err, "failed to encode the history of %s", m.Type())
+		}
+		after, err := marshalFields(c.after)
+		if err != nil {
+			return errors.Wrapf(err, "failed to encode the history of %s", m.Type())
+		}
+		args := []any{m.Type(), c.id, strings.TrimPrefix(m.Op().String(), "Op"), actor, before, after}
+		in := []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(insertHistory)}
+		for _, arg := range args {
+			in = append(in, reflect.ValueOf(&arg).Elem())
+		}
+		out := exec.Call(in)
+		if err, _ := out[1].Interface().(error); err != nil {
+			return errors.Wrapf(err, "failed to write the history of %s %s", m.Type(), c.id)
+		}
+	}
+	return nil
+}
+
+// marshalFields encodes the fields as JSON, nil when there are none.
+func marshalFields(fields map[string]any) (any, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
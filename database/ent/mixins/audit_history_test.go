@@ -0,0 +1,137 @@
+package mixins_test
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+	"github.com/kiwiworks/rodent/internal/testent"
+	"github.com/kiwiworks/rodent/internal/testent/account"
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+// historySchema is the SQLite counterpart of migration.AuditHistorySchema.
+const historySchema = `
+CREATE TABLE rodent_audit_history (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	entity     TEXT NOT NULL,
+	entity_id  TEXT NOT NULL,
+	operation  TEXT NOT NULL,
+	actor      TEXT NOT NULL,
+	before     TEXT,
+	after      TEXT,
+	changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+type historyEntry struct {
+	entityID, operation, actor string
+	before, after              sql.NullString
+}
+
+func auditClient(t *testing.T) *testent.Client {
+	t.Helper()
+	client := testClient(t)
+	_, err := client.ExecContext(context.Background(), historySchema)
+	require.NoError(t, err)
+	return client
+}
+
+func history(t *testing.T, client *testent.Client, entity string) []historyEntry {
+	t.Helper()
+	rows, err := client.QueryContext(context.Background(),
+		`SELECT entity_id, operation, actor, before, after FROM rodent_audit_history WHERE entity = ? ORDER BY id`, entity)
+	require.NoError(t, err)
+	defer rows.Close()
+	var entries []historyEntry
+	for rows.Next() {
+		var entry historyEntry
+		require.NoError(t, rows.Scan(&entry.entityID, &entry.operation, &entry.actor, &entry.before, &entry.after))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, rows.Err())
+	return entries
+}
+
+func TestAuditedHooks(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client := auditClient(t)
+
+	created := client.Account.Create().SetEmail("ada@example.com").SaveX(mixins.WithActor(ctx, "system:signup"))
+	r.Equal("system:signup", created.CreatedBy)
+	r.Equal("system:signup", created.UpdatedBy)
+
+	request := auth.InjectUser(auth.InjectRequest(ctx), &auth.ResolvedUser{ProviderName: "google", ProviderId: "42"})
+	updated := client.Account.UpdateOne(created).SetName("Ada").SaveX(request)
+	r.Equal("system:signup", updated.CreatedBy, "the creator is immutable")
+	r.Equal("google:42", updated.UpdatedBy)
+
+	updated = client.Account.UpdateOne(created).SetName("Ada L.").SaveX(auth.InjectRequest(ctx))
+	r.Equal(mixins.AnonymousActor, updated.UpdatedBy)
+
+	updated = client.Account.UpdateOne(created).SetName("Ada").SetUpdatedBy("support:7").SaveX(request)
+	r.Equal("support:7", updated.UpdatedBy, "an actor set on the mutation is kept")
+
+	client.Account.Update().Where(account.ID(created.ID)).SetName("Lovelace").ExecX(ctx)
+	r.Equal(mixins.SystemActor, client.Account.GetX(ctx, created.ID).UpdatedBy)
+}
+
+func TestAuditHistory(t *testing.T) {
+	r := require.New(t)
+	ctx := mixins.WithActor(context.Background(), "system:test")
+	client := auditClient(t)
+
+	ada := client.Account.Create().SetEmail("ada@example.com").SaveX(ctx)
+	bob := client.Account.Create().SetEmail("bob@example.com").SaveX(ctx)
+	// UpdateOneID reads the before values from the database, UpdateOne takes them from the given entity
+	client.Account.UpdateOneID(ada.ID).SetEmail("ada@example.com").SetName("Ada").ExecX(ctx)
+	client.Account.UpdateOneID(ada.ID).SetName("Ada").ExecX(ctx)
+	client.Account.Update().SetName("anonymized").ExecX(ctx)
+
+	entries := history(t, client, "Account")
+	adaID, bobID := strconv.Itoa(ada.ID), strconv.Itoa(bob.ID)
+	r.Len(entries, 5)
+	r.Equal(adaID, entries[0].entityID)
+	r.Equal("Create", entries[0].operation)
+	r.Equal("system:test", entries[0].actor)
+	r.False(entries[0].before.Valid)
+	r.JSONEq(`{"email":"ada@example.com","created_by":"system:test","updated_by":"system:test"}`, entries[0].after.String)
+	r.Equal(bobID, entries[1].entityID)
+
+	r.Equal("UpdateOne", entries[2].operation)
+	r.JSONEq(`{"name":""}`, entries[2].before.String, "only the changed fields are recorded")
+	r.JSONEq(`{"name":"Ada"}`, entries[2].after.String)
+
+	// an update which changes nothing is not recorded, the bulk update records each row without the before values
+	for i, id := range []string{adaID, bobID} {
+		entry := entries[3+i]
+		r.Equal(id, entry.entityID)
+		r.Equal("Update", entry.operation)
+		r.False(entry.before.Valid)
+		r.JSONEq(`{"name":"anonymized","updated_by":"system:test"}`, entry.after.String)
+	}
+}
+
+func TestAuditHistorySoftDeletes(t *testing.T) {
+	r := require.New(t)
+	ctx := mixins.WithActor(context.Background(), "system:test")
+	client := auditClient(t)
+
+	first := client.Invoice.Create().SetNumber("INV-1").SaveX(ctx)
+	second := client.Invoice.Create().SetNumber("INV-2").SaveX(ctx)
+	client.Invoice.DeleteOne(first).ExecX(ctx)
+	client.Invoice.DeleteOneID(second.ID).ExecX(mixins.HardDelete(ctx))
+
+	entries := history(t, client, "Invoice")
+	r.Len(entries, 4)
+	r.Equal([]string{"Create", "Create", "Update", "DeleteOne"}, []string{
+		entries[0].operation, entries[1].operation, entries[2].operation, entries[3].operation,
+	})
+	r.Equal(strconv.Itoa(first.ID), entries[2].entityID, "a soft-deletion is only recorded as the update it runs")
+	r.Contains(entries[2].after.String, `"deleted_at"`)
+	r.Equal(strconv.Itoa(second.ID), entries[3].entityID, "a hard deletion is recorded as such")
+}
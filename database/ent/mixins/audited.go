@@ -0,0 +1,110 @@
+package mixins
+
+import (
+	"context"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/mixin"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+const (
+	createdByField = "created_by"
+	updatedByField = "updated_by"
+	// SystemActor is recorded when the context is not the one of an HTTP request and carries no actor,
+	// such as within the jobs and the commands.
+	SystemActor = "system"
+	// AnonymousActor is recorded for the HTTP requests without an authenticated user.
+	AnonymousActor = "anonymous"
+)
+
+type (
+	// Audited records who created and last updated the rows, from the authenticated user of the context.
+	// Its hooks are registered by the generated `ent/runtime` package, which must be imported.
+	Audited struct {
+		mixin.Schema
+	}
+	actorKey struct{}
+)
+
+// WithActor makes the mutations of the context record the given actor, in place of the authenticated user,
+// such as `mixins.WithActor(ctx, "system:billing")` for a job.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or else the authenticated user, or else the AnonymousActor
+// within an HTTP request, see auth.IsRequest, and the SystemActor outside of one.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	if user := auth.UserFromContext(ctx); user != nil {
+		return userActor(user)
+	}
+	if auth.IsRequest(ctx) {
+		return AnonymousActor
+	}
+	return SystemActor
+}
+
+// userActor identifies the user by its provider, which unlike the username does not change.
+func userActor(user *auth.ResolvedUser) string {
+	if user.ProviderId == "" {
+		return user.Username
+	}
+	if user.ProviderName == "" {
+		return user.ProviderId
+	}
+	return user.ProviderName + ":" + user.ProviderId
+}
+
+func (Audited) Fields() []ent.Field {
+	return []ent.Field{
+		field.String(createdByField).
+			Optional().
+			Immutable().
+			Comment("The actor who created the resource"),
+		field.String(updatedByField).
+			Optional().
+			Comment("The actor who last updated the resource"),
+	}
+}
+
+func (Audited) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.WithComments(true),
+	}
+}
+
+func (Audited) Hooks() []ent.Hook {
+	return []ent.Hook{
+		func(next ent.Mutator) ent.Mutator {
+			return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+				if !m.Op().Is(ent.OpCreate | ent.OpUpdate | ent.OpUpdateOne) {
+					return next.Mutate(ctx, m)
+				}
+				actor := ActorFromContext(ctx)
+				fields := []string{updatedByField}
+				if m.Op().Is(ent.OpCreate) {
+					fields = append(fields, createdByField)
+				}
+				for _, name := range fields {
+					// an actor set explicitly on the mutation is kept
+					if _, set := m.Field(name); set {
+						continue
+					}
+					if err := m.SetField(name, actor); err != nil {
+						return nil, errors.Wrapf(err, "failed to set %s of %s", name, m.Type())
+					}
+				}
+				return next.Mutate(ctx, m)
+			})
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package mixins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+func TestActorFromContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{
+			name:     "system fallback",
+			ctx:      context.Background(),
+			expected: SystemActor,
+		},
+		{
+			name:     "provider user",
+			ctx:      auth.InjectUser(context.Background(), &auth.ResolvedUser{ProviderName: "google", ProviderId: "42", Username: "bob"}),
+			expected: "google:42",
+		},
+		{
+			name:     "username only",
+			ctx:      auth.InjectUser(context.Background(), &auth.ResolvedUser{Username: "bob"}),
+			expected: "bob",
+		},
+		{
+			name:     "anonymous request",
+			ctx:      auth.InjectRequest(context.Background()),
+			expected: AnonymousActor,
+		},
+		{
+			name:     "authenticated request",
+			ctx:      auth.InjectUser(auth.InjectRequest(context.Background()), &auth.ResolvedUser{Username: "bob"}),
+			expected: "bob",
+		},
+		{
+			name:     "explicit actor within a request",
+			ctx:      WithActor(auth.InjectRequest(context.Background()), "webhook:stripe"),
+			expected: "webhook:stripe",
+		},
+		{
+			name:     "explicit actor wins",
+			ctx:      WithActor(auth.InjectUser(context.Background(), &auth.ResolvedUser{Username: "bob"}), "system:billing"),
+			expected: "system:billing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, ActorFromContext(tt.ctx))
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before, after := diff(
+		map[string]any{"email": "a", "name": "bob", "age": 3},
+		map[string]any{"email": "b", "name": "bob", "age": 3, "avatar": nil},
+	)
+	require.Equal(t, map[string]any{"email": "a"}, before)
+	require.Equal(t, map[string]any{"email": "b", "avatar": nil}, after)
+}
@@ -7,17 +7,71 @@ import (
 	"entgo.io/ent/schema/field"
 	"entgo.io/ent/schema/mixin"
 	"github.com/google/uuid"
+
+	"github.com/kiwiworks/rodent/database/ent/entity"
+	"github.com/kiwiworks/rodent/errors"
 )
 
-type Resource struct {
-	mixin.Schema
-}
+const (
+	// RandomUUID public IDs are UUIDv4.
+	RandomUUID IDStrategy = iota
+	// SortableUUID public IDs are UUIDv7, which are ordered by their creation time and keep the index compact.
+	SortableUUID
+	// PrefixedID public IDs are Stripe-like strings, such as `usr_01HZX3C8V6J0T9Q2K8M1N4R5S7AB`, see entity.NewPrefixedID.
+	PrefixedID
+)
+
+type (
+	// IDStrategy is how the public IDs of a Resource are generated.
+	IDStrategy int
+	// Resource adds a unique public ID, which is exposed in place of the internal ID, look the rows up with
+	// entity.FindByPublicID.
+	//
+	//	func (User) Mixin() []ent.Mixin {
+	//		return []ent.Mixin{mixins.Resource{IDs: mixins.PrefixedID, Prefix: "usr"}}
+	//	}
+	Resource struct {
+		mixin.Schema
+		// IDs is how the public IDs are generated, RandomUUID by default.
+		IDs IDStrategy
+		// Prefix of the PrefixedID public IDs, such as `usr`.
+		Prefix string
+	}
+)
 
-func (Resource) Fields() []ent.Field {
-	return []ent.Field{
-		field.UUID("public_id", uuid.UUID{}).
-			Default(uuid.New).
-			Comment("A resource public ID which will be exposed"),
+func (r Resource) Fields() []ent.Field {
+	switch r.IDs {
+	case SortableUUID:
+		return []ent.Field{
+			field.UUID(entity.PublicIDField, uuid.UUID{}).
+				Default(entity.NewSortableUUID).
+				Unique().
+				Immutable().
+				Comment("A resource public ID which will be exposed"),
+		}
+	case PrefixedID:
+		prefix := r.Prefix
+		errors.Must(prefix, entity.ValidatePrefix(prefix))
+		return []ent.Field{
+			field.String(entity.PublicIDField).
+				DefaultFunc(func() string {
+					return entity.NewPrefixedID(prefix)
+				}).
+				Validate(func(id string) error {
+					return entity.ValidatePrefixedID(prefix, id)
+				}).
+				Unique().
+				Immutable().
+				Comment("A resource public ID which will be exposed"),
+		}
+	default:
+		return []ent.Field{
+			field.UUID(entity.PublicIDField, uuid.UUID{}).
+				Default(uuid.New).
+				Unique().
+				Immutable().
+				Comment("A resource public ID which will be exposed"),
+		}
 	}
 }
 
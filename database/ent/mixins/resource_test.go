@@ -0,0 +1,31 @@
+package mixins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/ent/entity"
+)
+
+func TestResourceFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource Resource
+	}{
+		{name: "random uuid", resource: Resource{}},
+		{name: "sortable uuid", resource: Resource{IDs: SortableUUID}},
+		{name: "prefixed id", resource: Resource{IDs: PrefixedID, Prefix: "usr"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			fields := tt.resource.Fields()
+			r.Len(fields, 1)
+			descriptor := fields[0].Descriptor()
+			r.Equal(entity.PublicIDField, descriptor.Name)
+			r.True(descriptor.Unique)
+			r.True(descriptor.Immutable, "the public IDs never change once exposed")
+		})
+	}
+}
@@ -1,20 +1,52 @@
 package mixins
 
 import (
+	"context"
+	"reflect"
+	"time"
+
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/schema"
 	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
 	"entgo.io/ent/schema/mixin"
+
+	"github.com/kiwiworks/rodent/database/ent/entity"
+	"github.com/kiwiworks/rodent/errors"
 )
 
-type SoftDeletable struct {
-	mixin.Schema
+const deletedAtField = "deleted_at"
+
+type (
+	// SoftDeletable excludes the soft-deleted rows from the queries, and turns the deletions into `deleted_at` updates.
+	// Its interceptors and hooks are registered by the generated `ent/runtime` package, which must be imported.
+	SoftDeletable struct {
+		mixin.Schema
+	}
+	includeDeletedKey struct{}
+	hardDeleteKey     struct{}
+)
+
+// IncludeDeleted makes the queries of the context return the soft-deleted rows too.
+func IncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// HardDelete makes the deletions of the context remove the rows, soft-deleted or not.
+func HardDelete(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hardDeleteKey{}, true)
+}
+
+func includesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
 }
 
 func (SoftDeletable) Fields() []ent.Field {
 	return []ent.Field{
-		field.Time("deleted_at").
+		field.Time(deletedAtField).
 			Optional().
 			Comment("Represent the time at which the resource was soft-deleted"),
 	}
@@ -27,5 +59,84 @@ func (SoftDeletable) Annotations() []schema.Annotation {
 }
 
 func (SoftDeletable) Interceptors() []ent.Interceptor {
-	return []ent.Interceptor{}
+	return []ent.Interceptor{
+		ent.TraverseFunc(func(ctx context.Context, q ent.Query) error {
+			if includesDeleted(ctx) {
+				return nil
+			}
+			if !entity.Where(q, sql.FieldIsNull(deletedAtField)) {
+				return errors.Newf("query %T cannot exclude the soft-deleted rows", q)
+			}
+			return nil
+		}),
+	}
+}
+
+func (SoftDeletable) Hooks() []ent.Hook {
+	return []ent.Hook{
+		func(next ent.Mutator) ent.Mutator {
+			return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+				if !m.Op().Is(ent.OpDelete | ent.OpDeleteOne) {
+					return next.Mutate(ctx, m)
+				}
+				if hard, _ := ctx.Value(hardDeleteKey{}).(bool); hard {
+					return next.Mutate(ctx, m)
+				}
+				return softDelete(ctx, m)
+			})
+		},
+	}
+}
+
+// softDelete turns the deletion into an update of `deleted_at`, executed by the client of the mutation.
+func softDelete(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+	mutation, ok := m.(interface {
+		WhereP(...func(*sql.Selector))
+		SetOp(ent.Op)
+	})
+	if !ok {
+		return nil, errors.Newf("mutation %T cannot be turned into a soft-deletion", m)
+	}
+	// the generated Client method returns the typed client of the schema, its Mutate method dispatches on the Op
+	client := reflect.ValueOf(m).MethodByName("Client")
+	if !client.IsValid() {
+		return nil, errors.Newf("mutation %T has no client to soft-delete with", m)
+	}
+	mutate := client.Call(nil)[0].MethodByName("Mutate")
+	if !mutate.IsValid() {
+		return nil, errors.Newf("the client of mutation %T cannot mutate", m)
+	}
+	mutation.WhereP(sql.FieldIsNull(deletedAtField))
+	mutation.SetOp(ent.OpUpdate)
+	if err := m.SetField(deletedAtField, time.Now()); err != nil {
+		return nil, errors.Wrapf(err, "failed to soft-delete %s", m.Type())
+	}
+	out := mutate.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(m)})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, err
+	}
+	return out[0].Interface(), nil
+}
+
+// Restore clears the `deleted_at` of a soft-deleted row, such as `mixins.Restore(ctx, client.User.UpdateOneID(id))`.
+func Restore[U interface {
+	ClearDeletedAt() U
+	Exec(ctx context.Context) error
+}](ctx context.Context, update U) error {
+	if err := update.ClearDeletedAt().Exec(IncludeDeleted(ctx)); err != nil {
+		return errors.Wrapf(err, "failed to restore")
+	}
+	return nil
+}
+
+// UniqueWhenNotDeleted is a unique index ignoring the soft-deleted rows, use it in place of unique fields on
+// SoftDeletable schemas, so that a soft-deleted row does not prevent the creation of a new one with the same values.
+//
+//	func (User) Indexes() []ent.Index {
+//		return []ent.Index{mixins.UniqueWhenNotDeleted("email")}
+//	}
+func UniqueWhenNotDeleted(fields ...string) ent.Index {
+	return index.Fields(fields...).
+		Unique().
+		Annotations(entsql.IndexWhere(deletedAtField + " IS NULL"))
 }
@@ -0,0 +1,115 @@
+package mixins_test
+
+import (
+	"context"
+	"testing"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+	"github.com/kiwiworks/rodent/internal/testent"
+	"github.com/kiwiworks/rodent/internal/testent/enttest"
+	"github.com/kiwiworks/rodent/internal/testent/post"
+	_ "github.com/kiwiworks/rodent/internal/testent/runtime"
+)
+
+// testClient opens a client on an in-memory SQLite database, private to the test.
+func testClient(t *testing.T) *testent.Client {
+	t.Helper()
+	client := enttest.Open(t, dialect.SQLite, "file:"+t.Name()+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func createPosts(t *testing.T, client *testent.Client, titles ...string) []*testent.Post {
+	t.Helper()
+	posts := make([]*testent.Post, len(titles))
+	for i, title := range titles {
+		posts[i] = client.Post.Create().SetTitle(title).SaveX(context.Background())
+	}
+	return posts
+}
+
+func TestSoftDeletableDeleteOne(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client := testClient(t)
+	posts := createPosts(t, client, "first", "second")
+
+	var ops []ent.Op
+	client.Post.Use(func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			ops = append(ops, m.Op())
+			return next.Mutate(ctx, m)
+		})
+	})
+	r.NoError(client.Post.DeleteOneID(posts[0].ID).Exec(ctx))
+	r.Equal([]ent.Op{ent.OpDeleteOne, ent.OpUpdate}, ops, "the soft-deletion runs the hooks again as an update")
+
+	visible := client.Post.Query().AllX(ctx)
+	r.Len(visible, 1)
+	r.Equal(posts[1].ID, visible[0].ID)
+	_, err := client.Post.Get(ctx, posts[0].ID)
+	r.True(testent.IsNotFound(err), "got %v", err)
+
+	r.Len(client.Post.Query().AllX(mixins.IncludeDeleted(ctx)), 2)
+	deleted := client.Post.GetX(mixins.IncludeDeleted(ctx), posts[0].ID)
+	r.False(deleted.DeletedAt.IsZero())
+
+	err = client.Post.DeleteOneID(posts[0].ID).Exec(ctx)
+	r.True(testent.IsNotFound(err), "a soft-deleted row cannot be deleted again, got %v", err)
+}
+
+func TestSoftDeletableDelete(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client := testClient(t)
+	posts := createPosts(t, client, "first", "second", "third")
+	r.NoError(client.Post.DeleteOneID(posts[0].ID).Exec(ctx))
+
+	deleted, err := client.Post.Delete().Where(post.TitleNEQ("third")).Exec(ctx)
+	r.NoError(err)
+	r.Equal(1, deleted, "the rows which are already soft-deleted are not counted")
+	r.Equal([]string{"third"}, client.Post.Query().Select(post.FieldTitle).StringsX(ctx))
+	r.Equal(3, client.Post.Query().CountX(mixins.IncludeDeleted(ctx)))
+}
+
+func TestSoftDeletableHardDelete(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client := testClient(t)
+	posts := createPosts(t, client, "first", "second")
+	r.NoError(client.Post.DeleteOneID(posts[0].ID).Exec(ctx))
+
+	r.NoError(client.Post.DeleteOneID(posts[0].ID).Exec(mixins.HardDelete(ctx)), "soft-deleted rows can be hard-deleted")
+	r.NoError(client.Post.DeleteOneID(posts[1].ID).Exec(mixins.HardDelete(ctx)))
+	r.Zero(client.Post.Query().CountX(mixins.IncludeDeleted(ctx)))
+}
+
+func TestSoftDeletableRestore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client := testClient(t)
+	posts := createPosts(t, client, "first")
+	r.NoError(client.Post.DeleteOneID(posts[0].ID).Exec(ctx))
+
+	r.NoError(mixins.Restore(ctx, client.Post.UpdateOneID(posts[0].ID)))
+	restored := client.Post.GetX(ctx, posts[0].ID)
+	r.True(restored.DeletedAt.IsZero())
+}
+
+func TestUniqueWhenNotDeleted(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client := testClient(t)
+	posts := createPosts(t, client, "first")
+
+	_, err := client.Post.Create().SetTitle("first").Save(ctx)
+	r.True(testent.IsConstraintError(err), "got %v", err)
+	r.NoError(client.Post.DeleteOneID(posts[0].ID).Exec(ctx))
+	_, err = client.Post.Create().SetTitle("first").Save(ctx)
+	r.NoError(err, "a soft-deleted row does not prevent creating a new one")
+}
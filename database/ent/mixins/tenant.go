@@ -0,0 +1,108 @@
+package mixins
+
+import (
+	"context"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"entgo.io/ent/schema/mixin"
+
+	"github.com/kiwiworks/rodent/database/ent/entity"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/web/tenancy"
+)
+
+const tenantIdField = "tenant_id"
+
+// Tenant scopes the rows to the tenant of the context, see tenancy.WithTenant: the queries only return the rows of
+// the tenant, the updates and deletions only affect them, and the created rows belong to it.
+// Without a tenant, the operations fail with tenancy.ErrMissingTenant, unless the scoping was lifted with
+// tenancy.CrossTenant, in which case the created rows must set their tenant.
+// Its interceptors and hooks are registered by the generated `ent/runtime` package, which must be imported.
+type Tenant struct {
+	mixin.Schema
+}
+
+func (Tenant) Fields() []ent.Field {
+	return []ent.Field{
+		field.String(tenantIdField).
+			NotEmpty().
+			Immutable().
+			Comment("The tenant owning the resource"),
+	}
+}
+
+func (Tenant) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields(tenantIdField),
+	}
+}
+
+func (Tenant) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.WithComments(true),
+	}
+}
+
+func (Tenant) Interceptors() []ent.Interceptor {
+	return []ent.Interceptor{
+		ent.TraverseFunc(func(ctx context.Context, q ent.Query) error {
+			tenant, scoped, err := tenantScope(ctx)
+			if err != nil {
+				return errors.Wrapf(err, "failed to query %T", q)
+			}
+			if !scoped {
+				return nil
+			}
+			if !entity.Where(q, sql.FieldEQ(tenantIdField, tenant)) {
+				return errors.Newf("query %T cannot be scoped to the tenant", q)
+			}
+			return nil
+		}),
+	}
+}
+
+func (Tenant) Hooks() []ent.Hook {
+	return []ent.Hook{
+		func(next ent.Mutator) ent.Mutator {
+			return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+				tenant, scoped, err := tenantScope(ctx)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to mutate %s", m.Type())
+				}
+				if !scoped {
+					return next.Mutate(ctx, m)
+				}
+				if !m.Op().Is(ent.OpCreate) {
+					if !entity.Where(m, sql.FieldEQ(tenantIdField, tenant)) {
+						return nil, errors.Newf("mutation %T cannot be scoped to the tenant", m)
+					}
+					return next.Mutate(ctx, m)
+				}
+				if value, set := m.Field(tenantIdField); set && value != tenant {
+					return nil, errors.Newf("cannot create %s in tenant %v from tenant %s", m.Type(), value, tenant)
+				}
+				if err = m.SetField(tenantIdField, tenant); err != nil {
+					return nil, errors.Wrapf(err, "failed to set the tenant of %s", m.Type())
+				}
+				return next.Mutate(ctx, m)
+			})
+		},
+	}
+}
+
+// tenantScope returns the tenant the operations are scoped to, they are not scoped across the tenants.
+func tenantScope(ctx context.Context) (string, bool, error) {
+	if _, cross := tenancy.IsCrossTenant(ctx); cross {
+		return "", false, nil
+	}
+	tenant, ok := tenancy.FromContext(ctx)
+	if !ok {
+		return "", false, tenancy.ErrMissingTenant
+	}
+	return tenant, true, nil
+}
@@ -0,0 +1,66 @@
+package mixins_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/internal/testent"
+	"github.com/kiwiworks/rodent/internal/testent/project"
+	"github.com/kiwiworks/rodent/web/tenancy"
+)
+
+func TestTenantMissing(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client := testClient(t)
+
+	_, err := client.Project.Create().SetName("apollo").Save(ctx)
+	r.True(errors.Is(err, tenancy.ErrMissingTenant), "got %v", err)
+	_, err = client.Project.Query().All(ctx)
+	r.True(errors.Is(err, tenancy.ErrMissingTenant), "got %v", err)
+	_, err = client.Project.Delete().Exec(ctx)
+	r.True(errors.Is(err, tenancy.ErrMissingTenant), "got %v", err)
+}
+
+func TestTenantScope(t *testing.T) {
+	r := require.New(t)
+	acme := tenancy.WithTenant(context.Background(), "acme")
+	globex := tenancy.WithTenant(context.Background(), "globex")
+	client := testClient(t)
+
+	apollo := client.Project.Create().SetName("apollo").SaveX(acme)
+	r.Equal("acme", apollo.TenantID, "the created rows belong to the tenant of the context")
+	client.Project.Create().SetName("gemini").SaveX(globex)
+	_, err := client.Project.Create().SetName("mercury").SetTenantID("globex").Save(acme)
+	r.ErrorContains(err, "cannot create Project in tenant globex from tenant acme")
+
+	r.Equal([]string{"apollo"}, client.Project.Query().Select(project.FieldName).StringsX(acme))
+	_, err = client.Project.Get(globex, apollo.ID)
+	r.True(testent.IsNotFound(err), "the rows of another tenant are not found, got %v", err)
+
+	err = client.Project.UpdateOneID(apollo.ID).SetName("hijacked").Exec(globex)
+	r.True(testent.IsNotFound(err), "the rows of another tenant cannot be updated, got %v", err)
+	updated, err := client.Project.Update().SetName("renamed").Save(globex)
+	r.NoError(err)
+	r.Equal(1, updated)
+	deleted, err := client.Project.Delete().Exec(globex)
+	r.NoError(err)
+	r.Equal(1, deleted)
+	r.Equal("apollo", client.Project.GetX(acme, apollo.ID).Name)
+}
+
+func TestTenantCrossTenant(t *testing.T) {
+	r := require.New(t)
+	ctx := tenancy.CrossTenant(context.Background(), "test")
+	client := testClient(t)
+
+	_, err := client.Project.Create().SetName("apollo").Save(ctx)
+	r.True(testent.IsValidationError(err), "the rows created across the tenants must set their tenant, got %v", err)
+	client.Project.Create().SetName("apollo").SetTenantID("acme").ExecX(ctx)
+	client.Project.Create().SetName("gemini").SetTenantID("globex").ExecX(ctx)
+	r.Equal(2, client.Project.Query().CountX(ctx))
+	r.Equal(1, client.Project.Query().CountX(tenancy.WithTenant(context.Background(), "acme")))
+}
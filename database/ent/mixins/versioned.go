@@ -0,0 +1,117 @@
+package mixins
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/mixin"
+
+	"github.com/kiwiworks/rodent/database/ent/entity"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+const versionField = "version"
+
+type (
+	// Versioned increments the version of the rows on each update, the updates expecting a version, set on the
+	// mutation, such as `SetVersion(version)`, or with ExpectVersion, fail with an errors.ConflictError when the row
+	// moved on. The expectation is checked by the UpdateOne operations, the other updates are only restricted to
+	// the rows of that version. The If-Match header of a request is not read, the handler sets it on the mutation
+	// it targets, as the api.CRUD updates do.
+	// Its hooks are registered by the generated `ent/runtime` package, which must be imported.
+	Versioned struct {
+		mixin.Schema
+	}
+	expectedVersionKey struct{}
+)
+
+// ExpectVersion makes the updates of the context expect the rows to be at the given version, prefer setting the
+// version on the mutation when the context runs more than one update.
+func ExpectVersion(ctx context.Context, version int) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+// expectedVersion returns the version set on the mutation, or else by ExpectVersion.
+func expectedVersion(ctx context.Context, m ent.Mutation) (int, bool) {
+	if value, ok := m.Field(versionField); ok {
+		if version, ok := value.(int); ok {
+			return version, true
+		}
+	}
+	version, ok := ctx.Value(expectedVersionKey{}).(int)
+	return version, ok
+}
+
+func (Versioned) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int(versionField).
+			Default(1).
+			Positive().
+			Comment("The version of the resource, incremented on each update"),
+	}
+}
+
+func (Versioned) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.WithComments(true),
+	}
+}
+
+func (Versioned) Hooks() []ent.Hook {
+	return []ent.Hook{
+		func(next ent.Mutator) ent.Mutator {
+			return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+				if !m.Op().Is(ent.OpUpdate | ent.OpUpdateOne) {
+					return next.Mutate(ctx, m)
+				}
+				expected, ok := expectedVersion(ctx, m)
+				if !ok {
+					if err := m.AddField(versionField, 1); err != nil {
+						return nil, errors.Wrapf(err, "failed to increment the version of %s", m.Type())
+					}
+					return next.Mutate(ctx, m)
+				}
+				if m.Op().Is(ent.OpUpdateOne) {
+					actual, err := m.OldField(ctx, versionField)
+					if err != nil {
+						return nil, errors.Wrapf(err, "failed to read the version of %s", m.Type())
+					}
+					if actual != expected {
+						return nil, errors.Conflict(m.Type(), mutationID(m), expected, actual.(int))
+					}
+				}
+				// the version is checked again by the update, in case the row moved on since it was read
+				if !entity.Where(m, sql.FieldEQ(versionField, expected)) {
+					return nil, errors.Newf("mutation %T cannot check the version", m)
+				}
+				if err := m.SetField(versionField, expected+1); err != nil {
+					return nil, errors.Wrapf(err, "failed to increment the version of %s", m.Type())
+				}
+				value, err := next.Mutate(ctx, m)
+				if err != nil && m.Op().Is(ent.OpUpdateOne) && entity.IsNotFound(err) {
+					return nil, errors.Conflict(m.Type(), mutationID(m), expected, 0)
+				}
+				return value, err
+			})
+		},
+	}
+}
+
+// mutationID formats the identifier of a single row mutation.
+func mutationID(m ent.Mutation) string {
+	id := reflect.ValueOf(m).MethodByName("ID")
+	if !id.IsValid() {
+		return ""
+	}
+	out := id.Call(nil)
+	if !out[1].Bool() {
+		return ""
+	}
+	return fmt.Sprint(out[0].Interface())
+}
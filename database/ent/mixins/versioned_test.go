@@ -0,0 +1,69 @@
+package mixins_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/internal/testent/document"
+)
+
+func requireConflict(t *testing.T, expected errors.ConflictError, err error) {
+	t.Helper()
+	conflict := errors.As[errors.ConflictError](err)
+	require.NotNil(t, conflict, "expected a conflict, got %v", err)
+	require.Equal(t, expected, *conflict)
+}
+
+func TestVersionedUpdateOne(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client := testClient(t)
+	doc := client.Document.Create().SetTitle("draft").SaveX(ctx)
+	r.Equal(1, doc.Version)
+
+	doc = client.Document.UpdateOneID(doc.ID).SetTitle("review").SaveX(ctx)
+	r.Equal(2, doc.Version, "an update without expectation increments the version")
+
+	doc = client.Document.UpdateOneID(doc.ID).SetTitle("final").SetVersion(2).SaveX(ctx)
+	r.Equal(3, doc.Version)
+
+	_, err := client.Document.UpdateOneID(doc.ID).SetTitle("stale").SetVersion(2).Save(ctx)
+	requireConflict(t, errors.Conflict("Document", strconv.Itoa(doc.ID), 2, 3), err)
+
+	_, err = client.Document.UpdateOneID(doc.ID).SetTitle("stale").Save(mixins.ExpectVersion(ctx, 1))
+	r.True(errors.IsConflict(err))
+	r.Equal("final", client.Document.GetX(ctx, doc.ID).Title)
+}
+
+func TestVersionedUpdateOneRace(t *testing.T) {
+	ctx := context.Background()
+	client := testClient(t)
+	read := client.Document.Create().SetTitle("draft").SaveX(ctx)
+	client.Document.UpdateOneID(read.ID).SetTitle("concurrent").ExecX(ctx)
+
+	// UpdateOne reads the version of the given entity, which moved on since, the update itself detects it
+	_, err := client.Document.UpdateOne(read).SetTitle("stale").SetVersion(1).Save(ctx)
+	requireConflict(t, errors.Conflict("Document", strconv.Itoa(read.ID), 1, 0), err)
+}
+
+func TestVersionedUpdate(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client := testClient(t)
+	first := client.Document.Create().SetTitle("first").SaveX(ctx)
+	second := client.Document.Create().SetTitle("second").SaveX(ctx)
+	client.Document.UpdateOneID(second.ID).SetTitle("second, revised").ExecX(ctx)
+
+	updated, err := client.Document.Update().SetTitle("archived").Save(mixins.ExpectVersion(ctx, 1))
+	r.NoError(err)
+	r.Equal(1, updated, "the updates of many rows only change the ones at the expected version")
+	first = client.Document.GetX(ctx, first.ID)
+	r.Equal("archived", first.Title)
+	r.Equal(2, first.Version)
+	r.Equal("second, revised", client.Document.Query().Where(document.ID(second.ID)).OnlyX(ctx).Title)
+}
@@ -0,0 +1,169 @@
+// Package schemadiff generates the versioned migrations bringing the database to the state of the ent schema.
+package schemadiff
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/schema"
+
+	"github.com/kiwiworks/rodent/database/migration"
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// ErrDrift is returned by Check when the ent schema and the migrations have drifted.
+var ErrDrift = errors.Newf("the ent schema and the migrations have drifted")
+
+type Options struct {
+	// Dir is the directory of the SQL migrations, the generated ones are written there.
+	Dir string
+	// DevURL is a clean Postgres database the SQL migrations of Dir are replayed on, to compute the current state.
+	// The connected database is inspected instead when it is empty, it must be migrated up to date, Check requires it.
+	// Go migrations cannot be replayed, the schema changes they make are seen as missing when replaying.
+	DevURL string
+	now    func() time.Time
+}
+
+func Dir(dir string) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.Dir = dir
+	}
+}
+
+func DevURL(url string) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.DevURL = url
+	}
+}
+
+func newOptions(opts ...opt.Option[Options]) Options {
+	options := Options{
+		Dir: migration.DefaultMigratorConfig().Dir,
+		now: time.Now,
+	}
+	opt.Apply(&options, opts...)
+	return options
+}
+
+// Generate writes the up and down SQL migrations bringing the current state to the ent schema, such as the
+// `migrate.Tables` of the generated ent package. It returns the paths of the written files, none if nothing changed.
+func Generate(ctx context.Context, db *pg.Database, name string, tables []*schema.Table, opts ...opt.Option[Options]) ([]string, error) {
+	options := newOptions(opts...)
+	files, err := diff(ctx, db, name, tables, options)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(options.Dir, 0o755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the migrations directory %s", options.Dir)
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		path := filepath.Join(options.Dir, file.Name())
+		if err = os.WriteFile(path, file.Bytes(), 0o644); err != nil {
+			return nil, errors.Wrapf(err, "failed to write migration file %s", path)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Check fails with ErrDrift when the migrations of Dir, replayed on the DevURL database, differ from the ent schema,
+// such as in CI. It requires the DevURL, inspecting a database would compare its state rather than the migrations.
+func Check(ctx context.Context, db *pg.Database, tables []*schema.Table, opts ...opt.Option[Options]) error {
+	options := newOptions(opts...)
+	if options.DevURL == "" {
+		return errors.Newf("checking the migrations requires a dev database to replay them on, see schemadiff.DevURL")
+	}
+	files, err := diff(ctx, db, "drift", tables, options)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".up.sql") {
+			return errors.Wrapf(ErrDrift, "the following changes are missing from the migrations:\n%s", file.Bytes())
+		}
+	}
+	return nil
+}
+
+func diff(ctx context.Context, db *pg.Database, name string, tables []*schema.Table, options Options) ([]migrate.File, error) {
+	if !validName(name) {
+		return nil, errors.Newf("migration name %s must only contain letters, digits, underscores and dashes", name)
+	}
+	dir, err := replayableDir(options.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var generated []migrate.File
+	dir.SyncWrites(func(name string, data []byte) error {
+		if name != migrate.HashFileName {
+			generated = append(generated, migrate.NewLocalFile(name, data))
+		}
+		return nil
+	})
+
+	var (
+		driver dialect.Driver = db.Driver()
+		mode   schema.Mode    = schema.ModeInspect
+	)
+	if options.DevURL != "" {
+		dev, err := sql.Open(pg.Dialect, options.DevURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to connect to the dev database")
+		}
+		defer dev.Close()
+		driver, mode = entsql.OpenDB(dialect.Postgres, dev), schema.ModeReplay
+	}
+	migrator, err := schema.NewMigrate(driver,
+		schema.WithDir(dir),
+		schema.WithFormatter(formatter{now: options.now}),
+		schema.WithMigrationMode(mode),
+		schema.WithDialect(dialect.Postgres),
+		schema.WithDropColumn(true),
+		schema.WithDropIndex(true),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the ent migrator")
+	}
+	if err = migrator.NamedDiff(ctx, name, tables...); err != nil {
+		return nil, errors.Wrapf(err, "failed to diff the ent schema")
+	}
+	return generated, nil
+}
+
+// replayableDir loads the up SQL migrations of dir in memory, the down ones must not be replayed.
+func replayableDir(dir string) (*migrate.MemDir, error) {
+	var files []migrate.File
+	paths, err := fs.Glob(os.DirFS(dir), "*.up.sql")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the migrations of %s", dir)
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read migration %s", path)
+		}
+		files = append(files, migrate.NewLocalFile(path, content))
+	}
+	memDir := &migrate.MemDir{}
+	if err = memDir.CopyFiles(files); err != nil {
+		return nil, errors.Wrapf(err, "failed to load the migrations of %s", dir)
+	}
+	return memDir, nil
+}
+
+func validName(name string) bool {
+	return name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return !(r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+	}) < 0
+}
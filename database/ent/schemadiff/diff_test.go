@@ -0,0 +1,99 @@
+package schemadiff
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"entgo.io/ent/dialect/sql/schema"
+	"entgo.io/ent/schema/field"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/pg/pgtest"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+func usersTable(columns ...*schema.Column) *schema.Table {
+	id := &schema.Column{Name: "id", Type: field.TypeInt, Increment: true}
+	table := &schema.Table{
+		Name:       "users",
+		Columns:    append([]*schema.Column{id, {Name: "email", Type: field.TypeString}}, columns...),
+		PrimaryKey: []*schema.Column{id},
+	}
+	return table
+}
+
+func contents(files []migrate.File) map[string]string {
+	byName := make(map[string]string, len(files))
+	for _, file := range files {
+		byName[file.Name()] = string(file.Bytes())
+	}
+	return byName
+}
+
+func clock(day int) func() time.Time {
+	return func() time.Time {
+		return time.Date(2024, time.March, day, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func TestCheckRequiresDevURL(t *testing.T) {
+	err := Check(context.Background(), nil, []*schema.Table{usersTable()}, Dir(t.TempDir()))
+	require.ErrorContains(t, err, "checking the migrations requires a dev database")
+}
+
+func TestDiffReplay(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	db := pgtest.New(t)
+
+	options := newOptions(Dir(dir), DevURL(pgtest.DSN(t)))
+	options.now = clock(1)
+	files, err := diff(ctx, db, "add_users", []*schema.Table{usersTable()}, options)
+	r.NoError(err)
+	generated := contents(files)
+	r.Len(generated, 2)
+	r.Contains(generated["20240301000000_add_users.up.sql"], `CREATE TABLE "users"`)
+	r.Contains(generated["20240301000000_add_users.down.sql"], `DROP TABLE "users"`)
+	for _, file := range files {
+		r.NoError(os.WriteFile(filepath.Join(dir, file.Name()), file.Bytes(), 0o644))
+	}
+
+	// the migrations are replayed, the connected database which was never migrated is not looked at
+	r.NoError(Check(ctx, db, []*schema.Table{usersTable()}, Dir(dir), DevURL(pgtest.DSN(t))))
+	nickname := &schema.Column{Name: "nickname", Type: field.TypeString, Nullable: true}
+	err = Check(ctx, db, []*schema.Table{usersTable(nickname)}, Dir(dir), DevURL(pgtest.DSN(t)))
+	r.True(errors.Is(err, ErrDrift))
+	r.ErrorContains(err, `"nickname"`)
+
+	options = newOptions(Dir(dir), DevURL(pgtest.DSN(t)))
+	options.now = clock(2)
+	files, err = diff(ctx, db, "add_nickname", []*schema.Table{usersTable(nickname)}, options)
+	r.NoError(err)
+	generated = contents(files)
+	r.Len(generated, 2)
+	r.Contains(generated["20240302000000_add_nickname.up.sql"], `ADD COLUMN "nickname"`)
+	r.NotContains(generated["20240302000000_add_nickname.up.sql"], `CREATE TABLE`, "the previous migrations are replayed")
+}
+
+func TestDiffInspect(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, `CREATE TABLE users (id BIGSERIAL PRIMARY KEY, email VARCHAR NOT NULL)`)
+
+	options := newOptions(Dir(t.TempDir()))
+	options.now = clock(1)
+	files, err := diff(ctx, db, "inspected", []*schema.Table{usersTable()}, options)
+	r.NoError(err)
+	r.Empty(files, "the database already matches the schema")
+
+	nickname := &schema.Column{Name: "nickname", Type: field.TypeString, Nullable: true}
+	files, err = diff(ctx, db, "inspected", []*schema.Table{usersTable(nickname)}, options)
+	r.NoError(err)
+	r.Contains(contents(files)["20240301000000_inspected.up.sql"], `ADD COLUMN "nickname"`)
+}
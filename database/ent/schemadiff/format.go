@@ -0,0 +1,69 @@
+package schemadiff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+
+	"github.com/kiwiworks/rodent/database/migration"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// formatter writes a plan as the up and down SQL files understood by migration.LoadFS.
+// The down file is omitted when a change cannot be reversed, the migration then cannot be rolled back.
+type formatter struct {
+	now func() time.Time
+}
+
+func (f formatter) Format(plan *migrate.Plan) ([]migrate.File, error) {
+	version := plan.Version
+	if version == "" {
+		version = f.now().UTC().Format(migration.TimestampLayout)
+	}
+	delimiter := plan.Delimiter
+	if delimiter == "" {
+		delimiter = ";"
+	}
+	var header strings.Builder
+	fmt.Fprintf(&header, "-- %s, generated from the ent schema\n", plan.Name)
+	if !plan.Transactional {
+		header.WriteString(migration.NoTransactionAnnotation + "\n")
+	}
+
+	up := strings.Builder{}
+	up.WriteString(header.String())
+	for _, change := range plan.Changes {
+		writeStatements(&up, change.Comment, []string{change.Cmd}, delimiter)
+	}
+	files := []migrate.File{
+		migrate.NewLocalFile(fmt.Sprintf("%s_%s.up.sql", version, plan.Name), []byte(up.String())),
+	}
+
+	down := strings.Builder{}
+	down.WriteString(header.String())
+	for _, change := range slices.Backward(plan.Changes) {
+		statements, err := change.ReverseStmts()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to reverse %s", change.Cmd)
+		}
+		if len(statements) == 0 {
+			return files, nil
+		}
+		writeStatements(&down, change.Comment, statements, delimiter)
+	}
+	return append(files, migrate.NewLocalFile(fmt.Sprintf("%s_%s.down.sql", version, plan.Name), []byte(down.String()))), nil
+}
+
+func writeStatements(out *strings.Builder, comment string, statements []string, delimiter string) {
+	if comment != "" {
+		out.WriteString("\n-- " + comment + "\n")
+	} else {
+		out.WriteString("\n")
+	}
+	for _, statement := range statements {
+		out.WriteString(strings.TrimSuffix(statement, delimiter) + delimiter + "\n")
+	}
+}
@@ -0,0 +1,102 @@
+package schemadiff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"ariga.io/atlas/sql/migrate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/migration"
+)
+
+func TestFormatter(t *testing.T) {
+	f := formatter{now: func() time.Time {
+		return time.Date(2024, time.March, 2, 10, 4, 5, 0, time.UTC)
+	}}
+	createUsers := &migrate.Change{
+		Cmd:     `CREATE TABLE "users" ("id" bigint NOT NULL)`,
+		Comment: `create "users" table`,
+		Reverse: `DROP TABLE "users"`,
+	}
+	createIndex := &migrate.Change{
+		Cmd:     `CREATE INDEX CONCURRENTLY "users_id" ON "users" ("id")`,
+		Comment: `create index "users_id" to table: "users"`,
+		Reverse: []string{`DROP INDEX "users_id"`},
+	}
+
+	tests := []struct {
+		name     string
+		plan     *migrate.Plan
+		expected map[string]string
+	}{
+		{
+			name: "reversible",
+			plan: &migrate.Plan{Name: "add_users", Transactional: true, Changes: []*migrate.Change{createUsers, createIndex}},
+			expected: map[string]string{
+				"20240302100405_add_users.up.sql": "-- add_users, generated from the ent schema\n\n" +
+					"-- create \"users\" table\nCREATE TABLE \"users\" (\"id\" bigint NOT NULL);\n\n" +
+					"-- create index \"users_id\" to table: \"users\"\nCREATE INDEX CONCURRENTLY \"users_id\" ON \"users\" (\"id\");\n",
+				"20240302100405_add_users.down.sql": "-- add_users, generated from the ent schema\n\n" +
+					"-- create index \"users_id\" to table: \"users\"\nDROP INDEX \"users_id\";\n\n" +
+					"-- create \"users\" table\nDROP TABLE \"users\";\n",
+			},
+		},
+		{
+			name: "irreversible and not transactional",
+			plan: &migrate.Plan{Version: "20240101000000", Name: "drop_users", Changes: []*migrate.Change{{Cmd: `DROP TABLE "users";`}}},
+			expected: map[string]string{
+				"20240101000000_drop_users.up.sql": "-- drop_users, generated from the ent schema\n" +
+					migration.NoTransactionAnnotation + "\n\n" +
+					"DROP TABLE \"users\";\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := f.Format(tt.plan)
+			require.NoError(t, err)
+			formatted := map[string]string{}
+			mapFS := fstest.MapFS{}
+			for _, file := range files {
+				formatted[file.Name()] = string(file.Bytes())
+				mapFS[file.Name()] = &fstest.MapFile{Data: file.Bytes()}
+			}
+			require.Equal(t, tt.expected, formatted)
+
+			// the generated files are understood by the migration.Migrator
+			migrations, err := migration.LoadFS(nil, mapFS)
+			require.NoError(t, err)
+			require.Len(t, migrations, 1)
+			require.Equal(t, tt.plan.Name, migrations[0].Name())
+		})
+	}
+}
+
+func TestReplayableDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"20240101000000_users.up.sql":   "CREATE TABLE users (id INT);",
+		"20240101000000_users.down.sql": "DROP TABLE users;",
+		"20240102000000_posts.up.sql":   "CREATE TABLE posts (id INT);",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	memDir, err := replayableDir(dir)
+	require.NoError(t, err)
+	require.NoError(t, migrate.Validate(memDir))
+	files, err := memDir.Files()
+	require.NoError(t, err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	require.Equal(t, []string{"20240101000000_users.up.sql", "20240102000000_posts.up.sql"}, names)
+
+	empty, err := replayableDir(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	require.NoError(t, migrate.Validate(empty))
+}
@@ -0,0 +1,81 @@
+package schemadiff
+
+import (
+	"fmt"
+
+	"entgo.io/ent/dialect/sql/schema"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/command"
+	"github.com/kiwiworks/rodent/database/migration"
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+type commandParams struct {
+	fx.In
+	DB     *pg.Database
+	Tables []*schema.Table           `name:"schemadiff.tables"`
+	Config *migration.MigratorConfig `optional:"true"`
+}
+
+// Tables supplies the ent schema tables the migrate diff command compares, such as the `migrate.Tables`
+// of the generated ent package.
+func Tables(tables ...*schema.Table) opt.Option[app.Module] {
+	return func(opt *app.Module) {
+		opt.Instances = append(opt.Instances, fx.Annotated{Name: "schemadiff.tables", Target: tables})
+	}
+}
+
+func diffCommand(params commandParams) *command.Command {
+	dir, devURL, check := migration.DefaultMigratorConfig().Dir, "", false
+	if params.Config != nil {
+		dir = params.Config.Dir
+	}
+	return command.New("migrate.diff", "Generate a migration from the ent schema",
+		"Compare the ent schema with the database, or with the migrations replayed on a dev database, "+
+			"and write the up and down SQL migrations bringing one to the other.",
+		command.StringFlag(command.Flag{Name: "dir", Shorthand: "d", Usage: "directory of the migrations"}, &dir),
+		command.StringFlag(command.Flag{Name: "dev-url", Usage: "clean database to replay the migrations on, instead of inspecting the database"}, &devURL),
+		command.BoolFlag(command.Flag{Name: "check", Usage: "fail when the ent schema and the migrations have drifted, without writing anything, it requires --dev-url"}, &check),
+		command.Example("migrate diff add_users_email --dev-url postgres://localhost:5432/dev"),
+		func(opt *command.Command) {
+			opt.Run = func(cmd *cobra.Command, args []string) error {
+				var paths []string
+				switch {
+				case check:
+					if err := Check(cmd.Context(), params.DB, params.Tables, Dir(dir), DevURL(devURL)); err != nil {
+						return err
+					}
+				case len(args) != 1:
+					return errors.Newf("migrate diff expects the name of the migration as its only argument")
+				default:
+					var err error
+					if paths, err = Generate(cmd.Context(), params.DB, args[0], params.Tables, Dir(dir), DevURL(devURL)); err != nil {
+						return err
+					}
+				}
+				if len(paths) == 0 {
+					_, err := fmt.Fprintln(cmd.OutOrStdout(), "the migrations are up to date with the ent schema")
+					return err
+				}
+				for _, path := range paths {
+					if _, err := fmt.Fprintln(cmd.OutOrStdout(), "created", path); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		},
+	)
+}
+
+// Module adds the migrate diff command, it relies on migration.Module, pg.Module and Tables.
+func Module() app.Module {
+	return app.NewModule(
+		command.Commands(diffCommand),
+	)
+}
@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// AuditHistorySchema creates the table storing the changes made by each mutation of the mixins.AuditHistory schemas.
+const AuditHistorySchema = `
+CREATE TABLE IF NOT EXISTS rodent_audit_history (
+	id         BIGSERIAL PRIMARY KEY,
+	entity     TEXT        NOT NULL,
+	entity_id  TEXT        NOT NULL,
+	operation  TEXT        NOT NULL,
+	actor      TEXT        NOT NULL,
+	before     JSONB,
+	after      JSONB,
+	changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS rodent_audit_history_entity ON rodent_audit_history (entity, entity_id, changed_at);
+`
+
+// AuditHistoryMigration creates the history table of mixins.AuditHistory, register it with
+// `migration.Migrations(migration.AuditHistoryMigration)`.
+func AuditHistoryMigration(db *pg.Database) *Migration {
+	return New(
+		"rodent_audit_history",
+		time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, AuditHistorySchema); err != nil {
+				return errors.Wrapf(err, "failed to create the audit history table")
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, `DROP TABLE IF EXISTS rodent_audit_history`); err != nil {
+				return errors.Wrapf(err, "failed to drop the audit history table")
+			}
+			return nil
+		},
+		ChecksumOf(AuditHistorySchema),
+		AnyOrder(),
+	)
+}
@@ -0,0 +1,292 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/slices"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// BackfillSchema creates the table recording the progress of the backfills, register BackfillCheckpointsMigration
+// to create it.
+const BackfillSchema = `
+CREATE TABLE IF NOT EXISTS rodent_backfill_checkpoints (
+	name         TEXT PRIMARY KEY,
+	cursor       JSONB,
+	processed    BIGINT      NOT NULL DEFAULT 0,
+	completed_at TIMESTAMPTZ,
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+`
+
+type (
+	BackfillOptions struct {
+		// BatchSize is how many rows are processed within a single transaction.
+		BatchSize int
+		// PageBatches is how many batches of keys are read at once, before being split into batches.
+		PageBatches int
+		// Throttle is the pause between two batches, to leave room for the regular traffic.
+		Throttle time.Duration
+		// Down rolls back the backfill, by default it only forgets the checkpoint, so that it runs again once redone.
+		Down Down
+	}
+	// Keys returns up to limit keys coming strictly after the given one, in ascending order.
+	// after is nil for the first page, an empty result ends the backfill.
+	Keys[K any] func(ctx context.Context, after *K, limit int) ([]K, error)
+	// Process updates the rows of a batch of keys, within a transaction carried by ctx.
+	Process[K any]    func(ctx context.Context, batch []K) error
+	checkpoint[K any] struct {
+		cursor    *K
+		processed int64
+		completed bool
+	}
+	checkpointStore[K any] interface {
+		load(ctx context.Context) (checkpoint[K], error)
+		// save runs within the transaction of the batch, so that the checkpoint only moves along with the rows.
+		save(ctx context.Context, checkpoint checkpoint[K]) error
+	}
+	backfill[K any] struct {
+		name        string
+		options     BackfillOptions
+		keys        Keys[K]
+		process     Process[K]
+		checkpoints checkpointStore[K]
+		transact    func(ctx context.Context, fn func(ctx context.Context) error) error
+		rows        metric.Int64Counter
+		batches     metric.Float64Histogram
+	}
+)
+
+func BatchSize(size int) opt.Option[BackfillOptions] {
+	return func(opt *BackfillOptions) {
+		opt.BatchSize = size
+	}
+}
+
+func PageBatches(batches int) opt.Option[BackfillOptions] {
+	return func(opt *BackfillOptions) {
+		opt.PageBatches = batches
+	}
+}
+
+func Throttle(pause time.Duration) opt.Option[BackfillOptions] {
+	return func(opt *BackfillOptions) {
+		opt.Throttle = pause
+	}
+}
+
+func BackfillDown(down Down) opt.Option[BackfillOptions] {
+	return func(opt *BackfillOptions) {
+		opt.Down = down
+	}
+}
+
+// BackfillCheckpointsMigration creates the checkpoints table of the backfills, register it along with them, such as
+// `migration.Migrations(migration.BackfillCheckpointsMigration)`. It has no meaningful time, it sorts before every
+// other migration so that the table exists before the first backfill runs, and it is applied in AnyOrder.
+func BackfillCheckpointsMigration(db *pg.Database) *Migration {
+	return New(
+		"rodent_backfill_checkpoints",
+		time.Time{},
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, BackfillSchema); err != nil {
+				return errors.Wrapf(err, "failed to create the backfill checkpoints table")
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, `DROP TABLE IF EXISTS rodent_backfill_checkpoints`); err != nil {
+				return errors.Wrapf(err, "failed to drop the backfill checkpoints table")
+			}
+			return nil
+		},
+		ChecksumOf(BackfillSchema),
+		AnyOrder(),
+	)
+}
+
+// NewBackfill creates a migration processing rows in keyset-paginated batches, each one within its own transaction.
+// The last processed key is checkpointed along with each batch, so that a restarted backfill resumes after it.
+// K must be JSON serializable, such as the primary key of the rows.
+// A backfill is a Manual migration, auto-migrate stops before it since it can outlast the start timeout.
+// Its checkpoints are stored in the table of BackfillCheckpointsMigration.
+// It panics when the BatchSize or the PageBatches are not positive.
+func NewBackfill[K any](db *pg.Database, name string, migrationTime time.Time, keys Keys[K], process Process[K], opts ...opt.Option[BackfillOptions]) *Migration {
+	options := BackfillOptions{
+		BatchSize:   1000,
+		PageBatches: 10,
+	}
+	opt.Apply(&options, opts...)
+	if options.BatchSize <= 0 || options.PageBatches <= 0 {
+		panic(errors.Newf(
+			"backfill %s requires a positive batch size and page batches, got %d and %d",
+			name, options.BatchSize, options.PageBatches,
+		))
+	}
+	b := newBackfill(name, options, keys, process, &pgCheckpoints[K]{db: db, name: name},
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return db.WithTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+				return fn(ctx)
+			})
+		},
+	)
+	down := options.Down
+	if down == nil {
+		down = func(ctx context.Context) error {
+			_, err := db.Executor(ctx).ExecContext(ctx, `DELETE FROM rodent_backfill_checkpoints WHERE name = $1`, name)
+			if err != nil && !isUndefinedTable(err) {
+				return errors.Wrapf(err, "failed to forget the checkpoint of backfill %s", name)
+			}
+			return nil
+		}
+	}
+	return New(name, migrationTime, b.run, down, NoTransaction(), Manual())
+}
+
+func newBackfill[K any](name string, options BackfillOptions, keys Keys[K], process Process[K], checkpoints checkpointStore[K], transact func(ctx context.Context, fn func(ctx context.Context) error) error) *backfill[K] {
+	meter := otel.Meter("migration")
+	rows, _ := meter.Int64Counter("rodent.backfill.rows",
+		metric.WithDescription("Rows processed by the backfill migrations"),
+		metric.WithUnit("{row}"),
+	)
+	batches, _ := meter.Float64Histogram("rodent.backfill.batch.duration",
+		metric.WithDescription("Duration of the backfill batches"),
+		metric.WithUnit("s"),
+	)
+	return &backfill[K]{
+		name:        name,
+		options:     options,
+		keys:        keys,
+		process:     process,
+		checkpoints: checkpoints,
+		transact:    transact,
+		rows:        rows,
+		batches:     batches,
+	}
+}
+
+func (b *backfill[K]) run(ctx context.Context) error {
+	log := logger.FromContext(ctx).With(props.MigrationName(b.name))
+	state, err := b.checkpoints.load(ctx)
+	if err != nil {
+		return err
+	}
+	if state.completed {
+		return nil
+	}
+	if state.cursor != nil {
+		log.Info("resuming backfill", props.BackfillProcessed(state.processed))
+	}
+	attributes := metric.WithAttributes(attribute.String("migration.name", b.name))
+	startedAt, processedAtStart := time.Now(), state.processed
+	for {
+		page, err := b.keys(ctx, state.cursor, b.options.BatchSize*b.options.PageBatches)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the keys of backfill %s", b.name)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, batch := range slices.Chunk(page, b.options.BatchSize) {
+			batchStartedAt := time.Now()
+			next := checkpoint[K]{cursor: &batch[len(batch)-1], processed: state.processed + int64(len(batch))}
+			err = b.transact(ctx, func(ctx context.Context) error {
+				if err := b.process(ctx, batch); err != nil {
+					return errors.Wrapf(err, "failed to process a batch of backfill %s", b.name)
+				}
+				return b.checkpoints.save(ctx, next)
+			})
+			if err != nil {
+				return err
+			}
+			state = next
+			b.rows.Add(ctx, int64(len(batch)), attributes)
+			b.batches.Record(ctx, time.Since(batchStartedAt).Seconds(), attributes)
+			if b.options.Throttle > 0 {
+				select {
+				case <-ctx.Done():
+					return errors.Wrapf(ctx.Err(), "backfill %s interrupted", b.name)
+				case <-time.After(b.options.Throttle):
+				}
+			}
+		}
+		elapsed := time.Since(startedAt)
+		log.Info("backfill progressed",
+			props.BackfillProcessed(state.processed),
+			props.BackfillRate(float64(state.processed-processedAtStart)/max(elapsed.Seconds(), time.Millisecond.Seconds())),
+		)
+	}
+	state.completed = true
+	if err = b.checkpoints.save(ctx, state); err != nil {
+		return err
+	}
+	log.Info("backfill completed", props.BackfillProcessed(state.processed), props.MigrationDuration(time.Since(startedAt)))
+	return nil
+}
+
+type pgCheckpoints[K any] struct {
+	db   *pg.Database
+	name string
+}
+
+func (c *pgCheckpoints[K]) load(ctx context.Context) (checkpoint[K], error) {
+	var (
+		state       checkpoint[K]
+		cursor      []byte
+		completedAt sql.NullTime
+	)
+	err := c.db.Executor(ctx).QueryRowContext(ctx, `
+		SELECT cursor, processed, completed_at FROM rodent_backfill_checkpoints WHERE name = $1`,
+		c.name,
+	).Scan(&cursor, &state.processed, &completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, nil
+	}
+	if err != nil {
+		return state, errors.Wrapf(err, "failed to load the checkpoint of backfill %s", c.name)
+	}
+	state.completed = completedAt.Valid
+	if cursor != nil {
+		state.cursor = new(K)
+		if err = json.Unmarshal(cursor, state.cursor); err != nil {
+			return state, errors.Wrapf(err, "failed to decode the checkpoint of backfill %s", c.name)
+		}
+	}
+	return state, nil
+}
+
+func (c *pgCheckpoints[K]) save(ctx context.Context, state checkpoint[K]) error {
+	cursor, err := json.Marshal(state.cursor)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode the checkpoint of backfill %s", c.name)
+	}
+	_, err = c.db.Executor(ctx).ExecContext(ctx, `
+		INSERT INTO rodent_backfill_checkpoints (name, cursor, processed, completed_at)
+		VALUES ($1, $2, $3, CASE WHEN $4 THEN now() END)
+		ON CONFLICT (name) DO UPDATE
+		SET cursor = EXCLUDED.cursor, processed = EXCLUDED.processed,
+			completed_at = EXCLUDED.completed_at, updated_at = now()`,
+		c.name, cursor, state.processed, state.completed,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save the checkpoint of backfill %s", c.name)
+	}
+	return nil
+}
+
+func isUndefinedTable(err error) bool {
+	pqErr := errors.As[*pq.Error](err)
+	return pqErr != nil && (*pqErr).Code == "42P01"
+}
@@ -0,0 +1,115 @@
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+type memoryCheckpoints struct {
+	state checkpoint[int]
+	saves int
+}
+
+func (m *memoryCheckpoints) load(context.Context) (checkpoint[int], error) {
+	return m.state, nil
+}
+
+func (m *memoryCheckpoints) save(_ context.Context, state checkpoint[int]) error {
+	m.state = state
+	m.saves++
+	return nil
+}
+
+func TestBackfill(t *testing.T) {
+	rows := []int{1, 2, 3, 4, 5, 6, 7}
+	keys := func(_ context.Context, after *int, limit int) ([]int, error) {
+		var page []int
+		for _, row := range rows {
+			if (after == nil || row > *after) && len(page) < limit {
+				page = append(page, row)
+			}
+		}
+		return page, nil
+	}
+	transact := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}
+	options := BackfillOptions{BatchSize: 2, PageBatches: 2}
+
+	t.Run("processes every row in batches", func(t *testing.T) {
+		var batches [][]int
+		checkpoints := &memoryCheckpoints{}
+		b := newBackfill("users", options, keys, func(_ context.Context, batch []int) error {
+			batches = append(batches, batch)
+			return nil
+		}, checkpoints, transact)
+
+		require.NoError(t, b.run(context.Background()))
+		require.Equal(t, [][]int{{1, 2}, {3, 4}, {5, 6}, {7}}, batches)
+		require.True(t, checkpoints.state.completed)
+		require.Equal(t, int64(7), checkpoints.state.processed)
+
+		batches = nil
+		require.NoError(t, b.run(context.Background()))
+		require.Empty(t, batches, "a completed backfill does not run again")
+	})
+
+	t.Run("resumes after the checkpoint", func(t *testing.T) {
+		var batches [][]int
+		checkpoints := &memoryCheckpoints{}
+		failing := true
+		b := newBackfill("users", options, keys, func(_ context.Context, batch []int) error {
+			if failing && batch[0] == 5 {
+				return errors.Newf("boom")
+			}
+			batches = append(batches, batch)
+			return nil
+		}, checkpoints, transact)
+
+		require.ErrorContains(t, b.run(context.Background()), "failed to process a batch of backfill users")
+		require.Equal(t, 4, *checkpoints.state.cursor)
+		require.Equal(t, int64(4), checkpoints.state.processed)
+		require.False(t, checkpoints.state.completed)
+
+		failing, batches = false, nil
+		require.NoError(t, b.run(context.Background()))
+		require.Equal(t, [][]int{{5, 6}, {7}}, batches)
+		require.Equal(t, int64(7), checkpoints.state.processed)
+	})
+}
+
+func TestNewBackfill(t *testing.T) {
+	keys := func(context.Context, *int, int) ([]int, error) { return nil, nil }
+	process := func(context.Context, []int) error { return nil }
+	at := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	backfill := NewBackfill(nil, "users", at, keys, process)
+	require.True(t, backfill.manual, "auto-migrate stops before backfills")
+	require.True(t, backfill.noTx)
+
+	checkpoints := BackfillCheckpointsMigration(nil)
+	require.True(t, checkpoints.anyOrder)
+	require.True(t, checkpoints.before(at, backfill.name), "the checkpoints table is created before the backfills")
+
+	tests := []struct {
+		name string
+		opts []opt.Option[BackfillOptions]
+	}{
+		{name: "empty batches", opts: []opt.Option[BackfillOptions]{BatchSize(0)}},
+		{name: "negative batches", opts: []opt.Option[BackfillOptions]{BatchSize(-1)}},
+		{name: "empty pages", opts: []opt.Option[BackfillOptions]{PageBatches(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Panics(t, func() {
+				NewBackfill(nil, "users", at, keys, process, tt.opts...)
+			})
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/kiwiworks/rodent/command"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+func migrateCommand() *command.Command {
+	return command.New(commandName, "Manage the database migrations",
+		"Apply, roll back and inspect the database migrations. Migrations are not applied on start when this command runs.",
+		func(opt *command.Command) {
+			opt.Run = func(cmd *cobra.Command, args []string) error {
+				return cmd.Help()
+			}
+		},
+	)
+}
+
+func upCommand(migrator *Migrator) *command.Command {
+	return command.New(commandName+".up", "Apply the pending migrations",
+		"Apply the pending migrations in order, each one within its own transaction.",
+		command.Do(migrator.MigrateUp),
+	)
+}
+
+func downCommand(migrator *Migrator) *command.Command {
+	steps, to, all := 1, "", false
+	return command.New(commandName+".down", "Roll back the applied migrations",
+		"Roll back the last applied migrations, latest first, each one within its own transaction.",
+		command.IntFlag(command.Flag{Name: "steps", Shorthand: "n", Usage: "amount of migrations to roll back", Exclusive: true}, &steps),
+		command.StringFlag(command.Flag{Name: "to", Usage: "roll back every migration applied after this one", Exclusive: true}, &to),
+		command.BoolFlag(command.Flag{Name: "all", Usage: "roll back every applied migration", Exclusive: true}, &all),
+		command.Example("migrate down --steps 2"),
+		command.Do(func(ctx context.Context) error {
+			switch {
+			case all:
+				return migrator.MigrateDown(ctx)
+			case to != "":
+				return migrator.RollbackTo(ctx, to)
+			default:
+				return migrator.Rollback(ctx, steps)
+			}
+		}),
+	)
+}
+
+func redoCommand(migrator *Migrator) *command.Command {
+	steps := 1
+	return command.New(commandName+".redo", "Roll back then apply again the last migrations",
+		"Roll back the last applied migrations, then apply them again, such as to iterate on a migration in development.",
+		command.IntFlag(command.Flag{Name: "steps", Shorthand: "n", Usage: "amount of migrations to redo"}, &steps),
+		command.Do(func(ctx context.Context) error {
+			return migrator.Redo(ctx, steps)
+		}),
+	)
+}
+
+func statusCommand(migrator *Migrator) *command.Command {
+	return command.New(commandName+".status", "Print the state of the migrations",
+		"Print the applied and pending migrations, along with those which were modified, are out of order or are not registered anymore.",
+		func(opt *command.Command) {
+			opt.Run = func(cmd *cobra.Command, args []string) error {
+				statuses, err := migrator.Status(cmd.Context())
+				if err != nil {
+					return err
+				}
+				return writeStatuses(cmd.OutOrStdout(), statuses)
+			}
+		},
+	)
+}
+
+func validateCommand(migrator *Migrator) *command.Command {
+	return command.New(commandName+".validate", "Check that the pending migrations can be applied",
+		"Fail when an applied migration was modified, or when a pending migration comes before an applied one, without applying anything.",
+		func(opt *command.Command) {
+			opt.Run = func(cmd *cobra.Command, args []string) error {
+				if err := migrator.Validate(cmd.Context()); err != nil {
+					return err
+				}
+				if err := writeUnchecked(cmd.OutOrStdout(), migrator.Unchecked()); err != nil {
+					return err
+				}
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), "migrations are valid")
+				return err
+			}
+		},
+	)
+}
+
+// createParams only depends on the configuration, scaffolding a migration does not need the database.
+type createParams struct {
+	fx.In
+	Config *MigratorConfig `optional:"true"`
+}
+
+func createCommand(params createParams) *command.Command {
+	config := params.Config
+	if config == nil {
+		config = DefaultMigratorConfig()
+	}
+	dir, goMigration := config.Dir, false
+	return command.New(commandName+".create", "Scaffold a new migration",
+		"Create the files of a new migration, prefixed with the current time, as SQL up and down files or as a Go migration.",
+		command.StringFlag(command.Flag{Name: "dir", Shorthand: "d", Usage: "directory of the migrations"}, &dir),
+		command.BoolFlag(command.Flag{Name: "go", Usage: "scaffold a Go migration instead of SQL files"}, &goMigration),
+		command.Example("migrate create add_users_email"),
+		func(opt *command.Command) {
+			opt.Run = func(cmd *cobra.Command, args []string) error {
+				if len(args) != 1 {
+					return errors.Newf("migrate create expects the name of the migration as its only argument")
+				}
+				paths, err := Scaffold(dir, args[0], goMigration, time.Now())
+				if err != nil {
+					return err
+				}
+				for _, path := range paths {
+					if _, err = fmt.Fprintln(cmd.OutOrStdout(), "created", path); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		},
+	)
+}
+
+func writeStatuses(w io.Writer, statuses []Status) error {
+	var unchecked []string
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "MIGRATION\tTIME\tSTATE\tAPPLIED AT\tDURATION")
+	for _, status := range statuses {
+		appliedAt, duration := "-", "-"
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.UTC().Format(time.DateTime)
+			duration = status.Duration.String()
+		}
+		_, _ = fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n",
+			status.Name, status.Time.UTC().Format(time.DateTime), status.State, appliedAt, duration,
+		)
+		if status.Unchecked {
+			unchecked = append(unchecked, status.Name)
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	return writeUnchecked(w, unchecked)
+}
+
+// writeUnchecked warns about the migrations whose changes cannot be detected.
+func writeUnchecked(w io.Writer, unchecked []string) error {
+	if len(unchecked) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w,
+		"warning: the changes of these Go migrations are not detected, give them a migration.Checksum: %s\n",
+		strings.Join(unchecked, ", "),
+	)
+	return err
+}
@@ -0,0 +1,38 @@
+package migration
+
+import "github.com/kiwiworks/rodent/system/opt"
+
+// MigratorConfig configures when the Migrator runs, and where the migrate create command scaffolds migrations.
+type MigratorConfig struct {
+	// AutoMigrate applies the pending migrations on start, disable it to run them as a separate deployment job.
+	// They are never applied on start when running the migrate command, and it stops before the Manual migrations,
+	// such as the backfills.
+	AutoMigrate bool
+	// Dir is where the migrate create command writes the migration files.
+	Dir string
+}
+
+func DefaultMigratorConfig() *MigratorConfig {
+	return &MigratorConfig{
+		AutoMigrate: true,
+		Dir:         "migrations",
+	}
+}
+
+func NewMigratorConfig(opts ...opt.Option[MigratorConfig]) *MigratorConfig {
+	config := DefaultMigratorConfig()
+	opt.Apply(config, opts...)
+	return config
+}
+
+func AutoMigrate(enabled bool) opt.Option[MigratorConfig] {
+	return func(opt *MigratorConfig) {
+		opt.AutoMigrate = enabled
+	}
+}
+
+func Dir(dir string) opt.Option[MigratorConfig] {
+	return func(opt *MigratorConfig) {
+		opt.Dir = dir
+	}
+}
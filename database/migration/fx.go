@@ -0,0 +1,40 @@
+package migration
+
+import (
+	"io/fs"
+
+	"go.uber.org/fx"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/assert"
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// Migrations registers Go migration providers, they must only return a *migration.Migration.
+func Migrations(migrationProviders ...any) opt.Option[app.Module] {
+	for _, migrationProvider := range migrationProviders {
+		if err := assert.FuncHasReturn[*Migration](migrationProvider); err != nil {
+			panic(errors.Wrapf(err, "migration.Migrations only accepts function of any arity, which must only return *migration.Migration"))
+		}
+	}
+	return func(opt *app.Module) {
+		opt.Public = append(opt.Public, slices.Map(migrationProviders, func(in any) any {
+			return fx.Annotate(in, fx.ResultTags(`group:"migration.migration"`))
+		})...)
+	}
+}
+
+// SQLFiles registers the SQL migrations of fsys, see LoadFS, they are ordered along with the Go migrations.
+func SQLFiles(fsys fs.FS) opt.Option[app.Module] {
+	return func(opt *app.Module) {
+		opt.Public = append(opt.Public, fx.Annotate(
+			func(db *pg.Database) ([]*Migration, error) {
+				return LoadFS(db, fsys)
+			},
+			fx.ResultTags(`group:"migration.migration,flatten"`),
+		))
+	}
+}
@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// Schema creates the table recording the applied migrations, it is created on demand by the Migrator.
+const Schema = `
+CREATE TABLE IF NOT EXISTS rodent_schema_migrations (
+	name           TEXT PRIMARY KEY,
+	migration_time TIMESTAMPTZ NOT NULL,
+	checksum       TEXT        NOT NULL,
+	duration_ms    BIGINT      NOT NULL,
+	applied_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+`
+
+// Record is an applied migration, as recorded in the schema migrations table.
+type Record struct {
+	Name      string
+	Time      time.Time
+	Checksum  string
+	Duration  time.Duration
+	AppliedAt time.Time
+}
+
+// history returns the applied migrations, in the order they were meant to be applied.
+func history(ctx context.Context, exec pg.Executor) ([]Record, error) {
+	rows, err := exec.QueryContext(ctx, `
+		SELECT name, migration_time, checksum, duration_ms, applied_at
+		FROM rodent_schema_migrations
+		ORDER BY migration_time, name`,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the applied migrations")
+	}
+	defer rows.Close()
+	var records []Record
+	for rows.Next() {
+		var record Record
+		var duration int64
+		if err = rows.Scan(&record.Name, &record.Time, &record.Checksum, &duration, &record.AppliedAt); err != nil {
+			return nil, errors.Wrapf(err, "failed to read an applied migration")
+		}
+		record.Duration = time.Duration(duration) * time.Millisecond
+		records = append(records, record)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to list the applied migrations")
+	}
+	return records, nil
+}
+
+func record(ctx context.Context, exec pg.Executor, migration *Migration, duration time.Duration) error {
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO rodent_schema_migrations (name, migration_time, checksum, duration_ms)
+		VALUES ($1, $2, $3, $4)`,
+		migration.name, migration.recordedTime(), migration.checksum, duration.Milliseconds(),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to record migration %s", migration.name)
+	}
+	return nil
+}
+
+func forget(ctx context.Context, exec pg.Executor, migration *Migration) error {
+	if _, err := exec.ExecContext(ctx, `DELETE FROM rodent_schema_migrations WHERE name = $1`, migration.name); err != nil {
+		return errors.Wrapf(err, "failed to forget migration %s", migration.name)
+	}
+	return nil
+}
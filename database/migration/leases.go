@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// LeaseMigration creates the leases table of pg.Database.AcquireLease and pg.LeaderElector, register it next to
+// the elector, such as `migration.Migrations(migration.LeaseMigration)`.
+func LeaseMigration(db *pg.Database) *Migration {
+	return New(
+		"rodent_leases",
+		time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, pg.LeaseSchema); err != nil {
+				return errors.Wrapf(err, "failed to create the leases table")
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, `DROP TABLE IF EXISTS rodent_leases`); err != nil {
+				return errors.Wrapf(err, "failed to drop the leases table")
+			}
+			return nil
+		},
+		ChecksumOf(pg.LeaseSchema),
+		AnyOrder(),
+	)
+}
@@ -2,25 +2,112 @@ package migration
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
+
+	"github.com/kiwiworks/rodent/system/opt"
 )
 
 type (
 	Up        func(context.Context) error
 	Down      func(context.Context) error
 	Migration struct {
-		name string
-		time time.Time
-		up   func(ctx context.Context) error
-		down func(ctx context.Context) error
+		name     string
+		time     time.Time
+		checksum string
+		// unchecked migrations have no Checksum, changes to their code are not detected
+		unchecked bool
+		noTx      bool
+		manual    bool
+		anyOrder  bool
+		up        func(ctx context.Context) error
+		down      func(ctx context.Context) error
 	}
 )
 
-func New(name string, migrationTime time.Time, up Up, down Down) *Migration {
-	return &Migration{
+// Checksum identifies the content of the migration, the Migrator refuses to run once an applied migration changed.
+// The code of Go migrations cannot be hashed, without a checksum they default to a digest of their name and time,
+// so that changing them goes unnoticed, the status and validate commands list them as unchecked.
+func Checksum(checksum string) opt.Option[Migration] {
+	return func(opt *Migration) {
+		opt.checksum = checksum
+	}
+}
+
+// ChecksumOf sets the Checksum to a digest of the content the migration runs, such as its SQL statements.
+func ChecksumOf(content string) opt.Option[Migration] {
+	return Checksum(digest(content))
+}
+
+// NoTransaction runs the migration outside a transaction, for statements such as `CREATE INDEX CONCURRENTLY`.
+// A failure can leave such a migration partially applied, keep them to a single statement when possible.
+func NoTransaction() opt.Option[Migration] {
+	return func(opt *Migration) {
+		opt.noTx = true
+	}
+}
+
+// Manual marks a migration which can outlast the start timeout of the application, such as a backfill.
+// Auto-migrate stops before it, so that it is only applied by the migrate up command, or Migrator.MigrateUp.
+func Manual() opt.Option[Migration] {
+	return func(opt *Migration) {
+		opt.manual = true
+	}
+}
+
+// AnyOrder exempts a migration from the ordering check, it is applied even when migrations with a later time
+// were applied before it. It suits the migrations of the framework packages, which only create their own tables
+// and can be registered in an application whose migrations are more recent than theirs.
+func AnyOrder() opt.Option[Migration] {
+	return func(opt *Migration) {
+		opt.anyOrder = true
+	}
+}
+
+// New creates a Go migration, up and down run within the transaction carried by their context, unless NoTransaction
+// is given, use pg.Database.Executor to join it.
+func New(name string, migrationTime time.Time, up Up, down Down, opts ...opt.Option[Migration]) *Migration {
+	m := &Migration{
 		name: name,
 		time: migrationTime,
 		up:   up,
 		down: down,
 	}
+	opt.Apply(m, opts...)
+	if m.checksum == "" {
+		m.unchecked = true
+		m.checksum = digest(name + "@" + migrationTime.UTC().Format(time.RFC3339Nano))
+	}
+	return m
+}
+
+func (m *Migration) Name() string {
+	return m.name
+}
+
+func (m *Migration) Time() time.Time {
+	return m.time
+}
+
+func (m *Migration) Checksum() string {
+	return m.checksum
+}
+
+// recordedTime is the time stored in the history, Postgres keeps timestamps to the microsecond.
+func (m *Migration) recordedTime() time.Time {
+	return m.time.Truncate(time.Microsecond)
+}
+
+// before orders migrations by time, then by name.
+func (m *Migration) before(time time.Time, name string) bool {
+	if m.time.Equal(time) {
+		return m.name < name
+	}
+	return m.time.Before(time)
+}
+
+func digest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
 }
@@ -2,52 +2,344 @@ package migration
 
 import (
 	"context"
+	"database/sql"
+	"slices"
 	"sort"
+	"time"
 
 	"go.uber.org/fx"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
 )
 
+// lockName is the advisory lock serializing the migration runs of concurrent replicas.
+const lockName = "rodent.migration"
+
+// commandName is the CLI command managing the migrations, they are never applied on start when it runs.
+const commandName = "migrate"
+
 type Migrator struct {
+	db         *pg.Database
+	config     MigratorConfig
+	activation *app.Activation
 	migrations []*Migration
 }
 
 func (m *Migrator) sortMigrationsByTime() {
 	sort.SliceStable(m.migrations, func(i, j int) bool {
-		return m.migrations[i].time.Before(m.migrations[j].time)
+		return m.migrations[i].before(m.migrations[j].time, m.migrations[j].name)
 	})
 }
 
 type Config struct {
 	fx.In
-	Migrations []*Migration `group:"migration.migration"`
+	DB             *pg.Database
+	MigratorConfig *MigratorConfig `optional:"true"`
+	Activation     *app.Activation `optional:"true"`
+	Migrations     []*Migration    `group:"migration.migration"`
 }
 
-func NewMigrator(cfg Config) *Migrator {
+func NewMigrator(cfg Config) (*Migrator, error) {
+	names := make(map[string]struct{}, len(cfg.Migrations))
+	for _, migration := range cfg.Migrations {
+		if _, duplicate := names[migration.name]; duplicate {
+			return nil, errors.Newf("migration %s is registered more than once", migration.name)
+		}
+		names[migration.name] = struct{}{}
+	}
+	config := cfg.MigratorConfig
+	if config == nil {
+		config = DefaultMigratorConfig()
+	}
 	m := &Migrator{
+		db:         cfg.DB,
+		config:     *config,
+		activation: cfg.Activation,
 		migrations: cfg.Migrations,
 	}
 	m.sortMigrationsByTime()
-	return m
+	return m, nil
 }
 
-func (m *Migrator) MigrateUp(ctx context.Context) error {
-	for _, migration := range m.migrations {
-		if err := migration.up(ctx); err != nil {
+// pending returns the migrations which are not applied yet, in order.
+// It fails when an applied migration was modified, or when a pending migration comes before an applied one,
+// unless either of them was created with AnyOrder.
+func pending(migrations []*Migration, applied []Record) ([]*Migration, error) {
+	records := make(map[string]Record, len(applied))
+	for _, record := range applied {
+		records[record.Name] = record
+	}
+	var (
+		err     error
+		pending []*Migration
+		latest  *Migration
+	)
+	for _, migration := range migrations {
+		record, isApplied := records[migration.name]
+		if !isApplied {
+			pending = append(pending, migration)
+			continue
+		}
+		if record.Checksum != migration.checksum || !record.Time.Equal(migration.recordedTime()) {
+			err = multierr.Append(err, errors.Newf("migration %s was modified after being applied", migration.name))
+		}
+		if !migration.anyOrder {
+			latest = migration
+		}
+	}
+	if latest != nil {
+		for _, migration := range pending {
+			if !migration.anyOrder && migration.before(latest.time, latest.name) {
+				err = multierr.Append(err, errors.Newf(
+					"migration %s is out of order, it comes before the applied migration %s", migration.name, latest.name,
+				))
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// lastApplied returns the last applied records, latest first.
+func lastApplied(records []Record, steps int) []Record {
+	steps = min(max(steps, 0), len(records))
+	selected := slices.Clone(records[len(records)-steps:])
+	slices.Reverse(selected)
+	return selected
+}
+
+// appliedAfter returns the records applied after the target migration, latest first.
+func appliedAfter(records []Record, target string) ([]Record, error) {
+	index := slices.IndexFunc(records, func(record Record) bool {
+		return record.Name == target
+	})
+	if index < 0 {
+		return nil, errors.Newf("migration %s is not applied", target)
+	}
+	return lastApplied(records, len(records)-index-1), nil
+}
+
+// resolve returns the registered migrations of the records, in the same order.
+func resolve(migrations []*Migration, records []Record) ([]*Migration, error) {
+	registered := make(map[string]*Migration, len(migrations))
+	for _, migration := range migrations {
+		registered[migration.name] = migration
+	}
+	resolved := make([]*Migration, 0, len(records))
+	for _, record := range records {
+		migration, ok := registered[record.Name]
+		if !ok {
+			return nil, errors.Newf("migration %s is applied but not registered, it cannot be rolled back", record.Name)
+		}
+		if migration.down == nil {
+			return nil, errors.Newf("migration %s cannot be rolled back", record.Name)
+		}
+		resolved = append(resolved, migration)
+	}
+	return resolved, nil
+}
+
+// locked runs fn with the applied migrations, while holding the lock serializing the migration runs.
+func (m *Migrator) locked(ctx context.Context, fn func(ctx context.Context, applied []Record) error) error {
+	lock, err := m.db.Lock(ctx, lockName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to acquire the migration lock")
+	}
+	defer func() {
+		_ = lock.Release(context.WithoutCancel(ctx))
+	}()
+	if _, err = m.db.DB().ExecContext(ctx, Schema); err != nil {
+		return errors.Wrapf(err, "failed to create the schema migrations table")
+	}
+	applied, err := history(ctx, m.db.DB())
+	if err != nil {
+		return err
+	}
+	return fn(ctx, applied)
+}
+
+// step runs a migration within its own transaction along with its tracking, unless it opted out of transactions.
+func (m *Migrator) step(ctx context.Context, migration *Migration, run func(ctx context.Context) error, track func(ctx context.Context, exec pg.Executor) error) error {
+	if migration.noTx {
+		if err := run(ctx); err != nil {
 			return err
 		}
+		return track(ctx, m.db.DB())
+	}
+	return m.db.WithTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := run(ctx); err != nil {
+			return err
+		}
+		return track(ctx, tx)
+	}, pg.MaxAttempts(1))
+}
+
+func (m *Migrator) apply(ctx context.Context, migration *Migration) error {
+	startedAt := time.Now()
+	err := m.step(ctx, migration,
+		func(ctx context.Context) error {
+			if err := migration.up(ctx); err != nil {
+				return errors.Wrapf(err, "failed to apply migration %s", migration.name)
+			}
+			return nil
+		},
+		func(ctx context.Context, exec pg.Executor) error {
+			return record(ctx, exec, migration, time.Since(startedAt))
+		},
+	)
+	if err != nil {
+		return err
 	}
+	logger.FromContext(ctx).Info("applied migration",
+		props.MigrationName(migration.name),
+		props.MigrationDuration(time.Since(startedAt)),
+	)
 	return nil
 }
 
-func (m *Migrator) MigrateDown(ctx context.Context) error {
+func (m *Migrator) revert(ctx context.Context, migration *Migration) error {
+	startedAt := time.Now()
+	err := m.step(ctx, migration,
+		func(ctx context.Context) error {
+			if err := migration.down(ctx); err != nil {
+				return errors.Wrapf(err, "failed to roll back migration %s", migration.name)
+			}
+			return nil
+		},
+		func(ctx context.Context, exec pg.Executor) error {
+			return forget(ctx, exec, migration)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("rolled back migration",
+		props.MigrationName(migration.name),
+		props.MigrationDuration(time.Since(startedAt)),
+	)
+	return nil
+}
+
+// MigrateUp applies the pending migrations in order, each one within its own transaction.
+func (m *Migrator) MigrateUp(ctx context.Context) error {
+	return m.migrateUp(ctx, false)
+}
+
+// migrateUp applies the pending migrations, stopping before the first Manual one when auto-migrating.
+func (m *Migrator) migrateUp(ctx context.Context, auto bool) error {
+	return m.locked(ctx, func(ctx context.Context, applied []Record) error {
+		m.warnUnknown(ctx, applied)
+		migrations, err := pending(m.migrations, applied)
+		if err != nil {
+			return errors.Wrapf(err, "refusing to migrate")
+		}
+		for _, migration := range migrations {
+			if auto && migration.manual {
+				logger.FromContext(ctx).Warn("auto-migrate stopped before a manual migration, apply it with the migrate up command",
+					props.MigrationName(migration.name),
+				)
+				return nil
+			}
+			if err = m.apply(ctx, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// warnUnknown logs the applied migrations which are not registered, such as after rolling back a deployment.
+func (m *Migrator) warnUnknown(ctx context.Context, applied []Record) {
+	registered := make(map[string]struct{}, len(m.migrations))
 	for _, migration := range m.migrations {
-		if err := migration.down(ctx); err != nil {
+		registered[migration.name] = struct{}{}
+	}
+	for _, record := range applied {
+		if _, ok := registered[record.Name]; !ok {
+			logger.FromContext(ctx).Warn("applied migration is not registered",
+				props.MigrationName(record.Name),
+				zap.Time("migration.applied_at", record.AppliedAt),
+			)
+		}
+	}
+}
+
+func (m *Migrator) rollback(ctx context.Context, selected []Record) error {
+	migrations, err := resolve(m.migrations, selected)
+	if err != nil {
+		return errors.Wrapf(err, "refusing to roll back")
+	}
+	for _, migration := range migrations {
+		if err = m.revert(ctx, migration); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// MigrateDown rolls back every applied migration, latest first.
+func (m *Migrator) MigrateDown(ctx context.Context) error {
+	return m.locked(ctx, func(ctx context.Context, applied []Record) error {
+		return m.rollback(ctx, lastApplied(applied, len(applied)))
+	})
+}
+
+// Rollback rolls back the last steps applied migrations, latest first.
+func (m *Migrator) Rollback(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(ctx context.Context, applied []Record) error {
+		return m.rollback(ctx, lastApplied(applied, steps))
+	})
+}
+
+// RollbackTo rolls back every migration applied after the target one, which stays applied.
+func (m *Migrator) RollbackTo(ctx context.Context, target string) error {
+	return m.locked(ctx, func(ctx context.Context, applied []Record) error {
+		selected, err := appliedAfter(applied, target)
+		if err != nil {
+			return errors.Wrapf(err, "refusing to roll back")
+		}
+		return m.rollback(ctx, selected)
+	})
+}
+
+// Redo rolls back the last steps applied migrations, then applies them again.
+func (m *Migrator) Redo(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(ctx context.Context, applied []Record) error {
+		migrations, err := resolve(m.migrations, lastApplied(applied, steps))
+		if err != nil {
+			return errors.Wrapf(err, "refusing to redo")
+		}
+		for _, migration := range migrations {
+			if err = m.revert(ctx, migration); err != nil {
+				return err
+			}
+		}
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if err = m.apply(ctx, migrations[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// OnStart applies the pending migrations up to the first Manual one, unless auto-migrate is disabled or the migrate
+// command runs.
 func (m *Migrator) OnStart(ctx context.Context) error {
-	return m.MigrateUp(ctx)
+	if !m.config.AutoMigrate {
+		logger.FromContext(ctx).Info("auto-migrate is disabled, skipping migrations")
+		return nil
+	}
+	if m.activation != nil && m.activation.Command() == commandName {
+		return nil
+	}
+	return m.migrateUp(ctx, true)
 }
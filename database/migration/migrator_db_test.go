@@ -0,0 +1,223 @@
+package migration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/database/pg/pgtest"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+func tableMigration(db *pg.Database, table string, day int) *Migration {
+	return New(table, time.Date(2024, time.January, day, 0, 0, 0, 0, time.UTC),
+		func(ctx context.Context) error {
+			_, err := db.Executor(ctx).ExecContext(ctx, `CREATE TABLE `+table+` (id INT)`)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := db.Executor(ctx).ExecContext(ctx, `DROP TABLE `+table)
+			return err
+		},
+		ChecksumOf(table),
+	)
+}
+
+func testMigrator(t *testing.T, db *pg.Database, migrations ...*Migration) *Migrator {
+	t.Helper()
+	migrator, err := NewMigrator(Config{DB: db, Migrations: migrations})
+	require.NoError(t, err)
+	return migrator
+}
+
+func appliedNames(t *testing.T, migrator *Migrator) []string {
+	t.Helper()
+	records, err := migrator.applied(context.Background())
+	require.NoError(t, err)
+	var names []string
+	for _, record := range records {
+		names = append(names, record.Name)
+	}
+	return names
+}
+
+func TestMigratorMigrateUp(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	ctx := context.Background()
+	users, teams := tableMigration(db, "users", 1), tableMigration(db, "teams", 2)
+
+	migrator := testMigrator(t, db, teams, users)
+	r.NoError(migrator.MigrateUp(ctx))
+	r.Equal([]string{"users", "teams"}, appliedNames(t, migrator))
+	r.NoError(migrator.MigrateUp(ctx), "applying again is a no-op")
+
+	invites := tableMigration(db, "invites", 3)
+	migrator = testMigrator(t, db, users, teams, invites)
+	r.NoError(migrator.Validate(ctx))
+	r.NoError(migrator.MigrateUp(ctx))
+	r.Equal([]string{"users", "teams", "invites"}, appliedNames(t, migrator))
+
+	r.NoError(migrator.Rollback(ctx, 2))
+	r.Equal([]string{"users"}, appliedNames(t, migrator))
+	r.NoError(migrator.Redo(ctx, 1))
+	r.Equal([]string{"users"}, appliedNames(t, migrator))
+	r.NoError(migrator.MigrateDown(ctx))
+	r.Empty(appliedNames(t, migrator))
+}
+
+func TestMigratorRefusesModifiedMigrations(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	ctx := context.Background()
+	r.NoError(testMigrator(t, db, tableMigration(db, "users", 1)).MigrateUp(ctx))
+
+	modified := New("users", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), nil, nil, ChecksumOf("changed"))
+	migrator := testMigrator(t, db, modified, tableMigration(db, "teams", 2))
+	r.ErrorContains(migrator.Validate(ctx), "migration users was modified after being applied")
+	r.ErrorContains(migrator.MigrateUp(ctx), "refusing to migrate")
+	r.Equal([]string{"users"}, appliedNames(t, migrator))
+}
+
+func TestMigratorAppliesFrameworkMigrationsInAnyOrder(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	ctx := context.Background()
+	users := New("users", time.Now(), func(ctx context.Context) error {
+		_, err := db.Executor(ctx).ExecContext(ctx, `CREATE TABLE users (id INT)`)
+		return err
+	}, nil, ChecksumOf("users"))
+	r.NoError(testMigrator(t, db, users).MigrateUp(ctx))
+
+	// the application starts using the leader election of pg, long after its own migrations
+	migrator := testMigrator(t, db, users, LeaseMigration(db))
+	r.NoError(migrator.Validate(ctx))
+	r.NoError(migrator.OnStart(ctx))
+	r.Equal([]string{"users", "rodent_leases"}, appliedNames(t, migrator))
+	r.NoError(testMigrator(t, db, users, LeaseMigration(db)).Validate(ctx), "sub-microsecond times match once applied")
+}
+
+func TestMigratorRollsBackFailedMigrations(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	ctx := context.Background()
+	failing := New("failing", time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, `CREATE TABLE partial (id INT)`); err != nil {
+				return err
+			}
+			return errors.Newf("boom")
+		},
+		nil,
+	)
+	migrator := testMigrator(t, db, tableMigration(db, "users", 1), failing)
+	r.ErrorContains(migrator.MigrateUp(ctx), "failed to apply migration failing")
+	r.Equal([]string{"users"}, appliedNames(t, migrator))
+	var exists bool
+	r.NoError(db.DB().QueryRowContext(ctx, `SELECT to_regclass('partial') IS NOT NULL`).Scan(&exists))
+	r.False(exists, "the migration is rolled back along with its tracking")
+}
+
+func TestMigratorLock(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	ctx := context.Background()
+
+	var (
+		mu   sync.Mutex
+		runs int
+	)
+	counted := New("counted", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		func(ctx context.Context) error {
+			mu.Lock()
+			runs++
+			mu.Unlock()
+			time.Sleep(time.Millisecond * 100)
+			_, err := db.Executor(ctx).ExecContext(ctx, `CREATE TABLE counted (id INT)`)
+			return err
+		},
+		nil,
+		ChecksumOf("counted"),
+	)
+	migrators := []*Migrator{testMigrator(t, db, counted), testMigrator(t, db, counted), testMigrator(t, db, counted)}
+	var wg sync.WaitGroup
+	errs := make([]error, len(migrators))
+	for i, migrator := range migrators {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = migrator.MigrateUp(ctx)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		r.NoError(err)
+	}
+	r.Equal(1, runs, "concurrent replicas apply each migration once")
+}
+
+func TestMigratorOnStartStopsBeforeManualMigrations(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	ctx := context.Background()
+	backfill := New("backfill", time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+		func(ctx context.Context) error { return nil }, nil, Manual(), ChecksumOf("backfill"),
+	)
+	migrator := testMigrator(t, db, tableMigration(db, "users", 1), backfill, tableMigration(db, "teams", 3))
+
+	r.NoError(migrator.OnStart(ctx))
+	r.Equal([]string{"users"}, appliedNames(t, migrator))
+	r.NoError(migrator.MigrateUp(ctx))
+	r.Equal([]string{"users", "backfill", "teams"}, appliedNames(t, migrator))
+}
+
+func TestMigratorBackfill(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	ctx := context.Background()
+	pgtest.Exec(t, db, `CREATE TABLE users (id INT PRIMARY KEY, active BOOLEAN NOT NULL DEFAULT false)`,
+		`INSERT INTO users (id) SELECT generate_series(1, 25)`)
+	backfill := NewBackfill(db, "activate_users", time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+		func(ctx context.Context, after *int, limit int) ([]int, error) {
+			var from int
+			if after != nil {
+				from = *after
+			}
+			rows, err := db.Executor(ctx).QueryContext(ctx, `SELECT id FROM users WHERE id > $1 ORDER BY id LIMIT $2`, from, limit)
+			if err != nil {
+				return nil, err
+			}
+			defer rows.Close()
+			var ids []int
+			for rows.Next() {
+				var id int
+				if err = rows.Scan(&id); err != nil {
+					return nil, err
+				}
+				ids = append(ids, id)
+			}
+			return ids, rows.Err()
+		},
+		func(ctx context.Context, batch []int) error {
+			_, err := db.Executor(ctx).ExecContext(ctx, `UPDATE users SET active = true WHERE id = ANY($1)`, pq.Array(batch))
+			return err
+		},
+		BatchSize(10),
+	)
+
+	migrator := testMigrator(t, db, backfill, BackfillCheckpointsMigration(db))
+	r.NoError(migrator.MigrateUp(ctx))
+	r.Equal([]string{"rodent_backfill_checkpoints", "activate_users"}, appliedNames(t, migrator))
+	var inactive, processed int
+	r.NoError(db.Executor(ctx).QueryRowContext(ctx, `SELECT count(*) FROM users WHERE NOT active`).Scan(&inactive))
+	r.Zero(inactive)
+	r.NoError(db.Executor(ctx).QueryRowContext(ctx,
+		`SELECT processed FROM rodent_backfill_checkpoints WHERE name = 'activate_users'`,
+	).Scan(&processed))
+	r.Equal(25, processed)
+}
@@ -0,0 +1,185 @@
+package migration
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPending(t *testing.T) {
+	at := func(day int) time.Time {
+		return time.Date(2024, time.January, day, 0, 0, 0, 0, time.UTC)
+	}
+	first := New("first", at(1), nil, nil)
+	second := New("second", at(2), nil, nil)
+	third := New("third", at(3), nil, nil)
+	applied := func(migration *Migration) Record {
+		return Record{Name: migration.name, Time: migration.time, Checksum: migration.checksum}
+	}
+
+	tests := []struct {
+		name     string
+		applied  []Record
+		expected []string
+		errors   []string
+	}{
+		{
+			name:     "nothing applied",
+			expected: []string{"first", "second", "third"},
+		},
+		{
+			name:     "partially applied",
+			applied:  []Record{applied(first)},
+			expected: []string{"second", "third"},
+		},
+		{
+			name:    "fully applied",
+			applied: []Record{applied(first), applied(second), applied(third)},
+		},
+		{
+			name:     "unknown applied migration",
+			applied:  []Record{applied(first), {Name: "removed", Time: at(2)}},
+			expected: []string{"second", "third"},
+		},
+		{
+			name:    "out of order",
+			applied: []Record{applied(first), applied(third)},
+			errors:  []string{"migration second is out of order, it comes before the applied migration third"},
+		},
+		{
+			name:    "modified checksum",
+			applied: []Record{{Name: "first", Time: at(1), Checksum: "stale"}},
+			errors:  []string{"migration first was modified after being applied"},
+		},
+		{
+			name:    "modified time",
+			applied: []Record{{Name: "first", Time: at(4), Checksum: first.checksum}},
+			errors:  []string{"migration first was modified after being applied"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := pending([]*Migration{first, second, third}, tt.applied)
+			if len(tt.errors) > 0 {
+				for _, message := range tt.errors {
+					require.ErrorContains(t, err, message)
+				}
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, migration := range migrations {
+				names = append(names, migration.name)
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestPendingAnyOrder(t *testing.T) {
+	r := require.New(t)
+	app := New("app", time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), nil, nil)
+	framework := New("framework", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), nil, nil, AnyOrder())
+	applied := []Record{{Name: app.name, Time: app.time, Checksum: app.checksum}}
+
+	migrations, err := pending([]*Migration{framework, app}, applied)
+	r.NoError(err)
+	r.Equal([]*Migration{framework}, migrations)
+
+	applied = append(applied, Record{Name: framework.name, Time: framework.time, Checksum: framework.checksum})
+	late := New("late", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), nil, nil)
+	_, err = pending([]*Migration{framework, late, app}, applied)
+	r.ErrorContains(err, "migration late is out of order, it comes before the applied migration app")
+}
+
+func TestPendingTimePrecision(t *testing.T) {
+	r := require.New(t)
+	migration := New("precise", time.Date(2024, time.January, 1, 0, 0, 0, 123456789, time.UTC), nil, nil)
+	// Postgres keeps the microseconds only
+	applied := []Record{{Name: migration.name, Time: migration.time.Truncate(time.Microsecond), Checksum: migration.checksum}}
+
+	migrations, err := pending([]*Migration{migration}, applied)
+	r.NoError(err)
+	r.Empty(migrations)
+}
+
+func TestNewMigratorRejectsDuplicates(t *testing.T) {
+	_, err := NewMigrator(Config{Migrations: []*Migration{
+		New("same", time.Now(), nil, nil),
+		New("same", time.Now(), nil, nil),
+	}})
+	require.ErrorContains(t, err, "migration same is registered more than once")
+}
+
+func TestRollbackSelection(t *testing.T) {
+	at := func(day int) time.Time {
+		return time.Date(2024, time.January, day, 0, 0, 0, 0, time.UTC)
+	}
+	noop := func(context.Context) error { return nil }
+	migrations := []*Migration{
+		New("first", at(1), noop, noop),
+		New("second", at(2), noop, noop),
+		New("third", at(3), noop, noop),
+		New("irreversible", at(4), noop, nil),
+	}
+	records := []Record{{Name: "first"}, {Name: "second"}, {Name: "third"}}
+
+	tests := []struct {
+		name     string
+		records  []Record
+		steps    int
+		target   string
+		expected []string
+		error    string
+	}{
+		{name: "last step", records: records, steps: 1, expected: []string{"third"}},
+		{name: "last steps", records: records, steps: 2, expected: []string{"third", "second"}},
+		{name: "more steps than applied", records: records, steps: 5, expected: []string{"third", "second", "first"}},
+		{name: "no step", records: records, steps: 0},
+		{name: "down to target", records: records, target: "first", expected: []string{"third", "second"}},
+		{name: "down to latest", records: records, target: "third"},
+		{name: "target not applied", records: records, target: "fourth", error: "migration fourth is not applied"},
+		{
+			name:    "unknown migration",
+			records: append(slices.Clone(records), Record{Name: "removed"}),
+			steps:   1,
+			error:   "migration removed is applied but not registered, it cannot be rolled back",
+		},
+		{
+			name:    "irreversible migration",
+			records: append(slices.Clone(records), Record{Name: "irreversible"}),
+			steps:   1,
+			error:   "migration irreversible cannot be rolled back",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				selected []Record
+				err      error
+			)
+			if tt.target != "" {
+				selected, err = appliedAfter(tt.records, tt.target)
+			} else {
+				selected = lastApplied(tt.records, tt.steps)
+			}
+			var resolved []*Migration
+			if err == nil {
+				resolved, err = resolve(migrations, selected)
+			}
+			if tt.error != "" {
+				require.ErrorContains(t, err, tt.error)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, migration := range resolved {
+				names = append(names, migration.name)
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
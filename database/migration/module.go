@@ -2,12 +2,23 @@ package migration
 
 import (
 	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/lifecycle"
 	"github.com/kiwiworks/rodent/app/module"
+	"github.com/kiwiworks/rodent/command"
 )
 
 func Module() app.Module {
 	return app.NewModule(
 		module.Public(NewMigrator),
-		module.Service[Migrator](),
+		module.Service[Migrator](module.InPhase(lifecycle.PhaseMigration)),
+		command.Commands(
+			migrateCommand,
+			upCommand,
+			downCommand,
+			redoCommand,
+			statusCommand,
+			validateCommand,
+			createCommand,
+		),
 	)
 }
@@ -0,0 +1,123 @@
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+var (
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	goTemplate  = template.Must(template.New("migration").Parse(`package {{ .Package }}
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiwiworks/rodent/database/migration"
+	"github.com/kiwiworks/rodent/database/pg"
+)
+
+// {{ .Func }} is registered with migration.Migrations({{ .Package }}.{{ .Func }}).
+func {{ .Func }}(db *pg.Database) *migration.Migration {
+	return migration.New(
+		"{{ .Name }}",
+		time.Date({{ .Time.Year }}, time.{{ .Time.Month }}, {{ .Time.Day }}, {{ .Time.Hour }}, {{ .Time.Minute }}, {{ .Time.Second }}, 0, time.UTC),
+		func(ctx context.Context) error {
+			_, err := db.Executor(ctx).ExecContext(ctx, ` + "``" + `)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := db.Executor(ctx).ExecContext(ctx, ` + "``" + `)
+			return err
+		},
+	)
+}
+`))
+)
+
+type scaffoldFile struct {
+	path    string
+	content []byte
+}
+
+// Scaffold writes the files of a new migration into dir, timestamped with now, and returns their paths.
+// It writes a Go migration when goMigration is set, an up and a down SQL files otherwise.
+func Scaffold(dir, name string, goMigration bool, now time.Time) ([]string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, errors.Newf("migration name %s must only contain letters, digits, underscores and dashes", name)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the migrations directory %s", dir)
+	}
+	now = now.UTC().Truncate(time.Second)
+	prefix := filepath.Join(dir, now.Format(TimestampLayout)+"_"+name)
+	var files []scaffoldFile
+	if goMigration {
+		source, err := goSource(filepath.Base(dir), name, now)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, scaffoldFile{path: prefix + ".go", content: source})
+	} else {
+		files = append(files,
+			scaffoldFile{path: prefix + ".up.sql", content: []byte(fmt.Sprintf("-- %s, applied by migrate up\n", name))},
+			scaffoldFile{path: prefix + ".down.sql", content: []byte(fmt.Sprintf("-- %s, rolled back by migrate down\n", name))},
+		)
+	}
+	paths := make([]string, 0, len(files))
+	for _, scaffolded := range files {
+		file, err := os.OpenFile(scaffolded.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create migration file %s", scaffolded.path)
+		}
+		_, err = file.Write(scaffolded.content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to write migration file %s", scaffolded.path)
+		}
+		paths = append(paths, scaffolded.path)
+	}
+	return paths, nil
+}
+
+func goSource(pkg, name string, now time.Time) ([]byte, error) {
+	var source bytes.Buffer
+	err := goTemplate.Execute(&source, map[string]any{
+		"Package": strings.ReplaceAll(strings.ToLower(pkg), "-", "_"),
+		"Func":    goIdentifier(name),
+		"Name":    name,
+		"Time":    now,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to render the Go migration %s", name)
+	}
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to format the Go migration %s", name)
+	}
+	return formatted, nil
+}
+
+// goIdentifier turns a migration name such as create_users into CreateUsers.
+func goIdentifier(name string) string {
+	var identifier strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		identifier.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	result := identifier.String()
+	if result == "" || (result[0] >= '0' && result[0] <= '9') {
+		result = "Migration" + result
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffold(t *testing.T) {
+	now := time.Date(2024, time.March, 2, 10, 4, 5, 0, time.UTC)
+
+	t.Run("SQL files", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "migrations")
+		paths, err := Scaffold(dir, "create_users", false, now)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			filepath.Join(dir, "20240302100405_create_users.up.sql"),
+			filepath.Join(dir, "20240302100405_create_users.down.sql"),
+		}, paths)
+
+		migrations, err := LoadFS(nil, os.DirFS(dir))
+		require.NoError(t, err)
+		require.Len(t, migrations, 1)
+		require.Equal(t, "create_users", migrations[0].Name())
+		require.Equal(t, now, migrations[0].Time())
+
+		_, err = Scaffold(dir, "create_users", false, now)
+		require.ErrorContains(t, err, "failed to create migration file")
+	})
+
+	t.Run("Go file", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "schema-migrations")
+		paths, err := Scaffold(dir, "create_users", true, now)
+		require.NoError(t, err)
+		require.Equal(t, []string{filepath.Join(dir, "20240302100405_create_users.go")}, paths)
+
+		source, err := os.ReadFile(paths[0])
+		require.NoError(t, err)
+		require.Contains(t, string(source), "package schema_migrations\n")
+		require.Contains(t, string(source), "func CreateUsers(db *pg.Database) *migration.Migration {")
+		require.Contains(t, string(source), "time.Date(2024, time.March, 2, 10, 4, 5, 0, time.UTC)")
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := Scaffold(t.TempDir(), "create users", false, now)
+		require.ErrorContains(t, err, "migration name create users must only contain")
+	})
+}
@@ -0,0 +1,251 @@
+package migration
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+const (
+	// TimestampLayout is the layout of the timestamp prefixing the SQL migration files.
+	TimestampLayout = "20060102150405"
+	// NoTransactionAnnotation marks a SQL migration to run outside a transaction, it must be in its leading comments.
+	NoTransactionAnnotation = "-- rodent:no-transaction"
+)
+
+var sqlFilePattern = regexp.MustCompile(`^(\d{14})_([A-Za-z0-9_-]+)\.(up|down)\.sql$`)
+
+type sqlFile struct {
+	time time.Time
+	up   *string
+	down *string
+}
+
+// LoadFS reads the SQL migrations at the root of fsys, named `<timestamp>_<name>.up.sql` and `<timestamp>_<name>.down.sql`,
+// where timestamp follows TimestampLayout. The down file is optional, without it the migration cannot be rolled back.
+// Use fs.Sub to load migrations from a directory of an embed.FS.
+func LoadFS(db *pg.Database, fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the SQL migrations")
+	}
+	files := make(map[string]*sqlFile)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := sqlFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, errors.Newf("SQL migration %s must be named <timestamp>_<name>.up.sql or <timestamp>_<name>.down.sql", entry.Name())
+		}
+		migrationTime, err := time.Parse(TimestampLayout, match[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "SQL migration %s has an invalid timestamp", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read SQL migration %s", entry.Name())
+		}
+		name, sql := match[2], string(content)
+		file, ok := files[name]
+		if !ok {
+			file = &sqlFile{time: migrationTime}
+			files[name] = file
+		}
+		if !file.time.Equal(migrationTime) {
+			return nil, errors.Newf("SQL migration %s has files with different timestamps", name)
+		}
+		if match[3] == "up" {
+			file.up = &sql
+		} else {
+			file.down = &sql
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	migrations := make([]*Migration, 0, len(files))
+	for _, name := range names {
+		migration, err := newSQLMigration(db, name, files[name])
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
+
+func newSQLMigration(db *pg.Database, name string, file *sqlFile) (*Migration, error) {
+	if file.up == nil {
+		return nil, errors.Newf("SQL migration %s has no up file", name)
+	}
+	up, err := splitStatements(*file.up)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse SQL migration %s", name)
+	}
+	checksum := *file.up
+	opts := []opt.Option[Migration]{}
+	if noTransaction(*file.up) {
+		opts = append(opts, NoTransaction())
+	}
+	var down Down
+	if file.down != nil {
+		statements, err := splitStatements(*file.down)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse SQL migration %s", name)
+		}
+		if noTransaction(*file.down) {
+			opts = append(opts, NoTransaction())
+		}
+		checksum += "\x00" + *file.down
+		down = execStatements(db, name, statements)
+	}
+	opts = append(opts, Checksum(digest(checksum)))
+	return New(name, file.time, execStatements(db, name, up), down, opts...), nil
+}
+
+func execStatements(db *pg.Database, name string, statements []string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for i, statement := range statements {
+			if _, err := db.Executor(ctx).ExecContext(ctx, statement); err != nil {
+				return errors.Wrapf(err, "statement %d of SQL migration %s failed: %s", i+1, name, abbreviate(statement))
+			}
+		}
+		return nil
+	}
+}
+
+// noTransaction reports whether the annotation is among the leading comments of the SQL.
+func noTransaction(sql string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			return false
+		}
+		if line == NoTransactionAnnotation {
+			return true
+		}
+	}
+	return false
+}
+
+func abbreviate(statement string) string {
+	statement = strings.Join(strings.Fields(statement), " ")
+	if len(statement) > 80 {
+		return statement[:77] + "..."
+	}
+	return statement
+}
+
+// splitStatements splits the SQL on semicolons, ignoring those within quotes, dollar quotes and comments.
+// Statements made only of comments are dropped.
+func splitStatements(sql string) ([]string, error) {
+	var (
+		statements []string
+		start      int
+		hasCode    bool
+	)
+	flush := func(end int) {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(sql[start:end]))
+		}
+		start, hasCode = end+1, false
+	}
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			depth := 0
+			for ; i < len(sql); i++ {
+				if strings.HasPrefix(sql[i:], "/*") {
+					depth++
+					i++
+				} else if strings.HasPrefix(sql[i:], "*/") {
+					depth--
+					i++
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if depth > 0 {
+				return nil, errors.Newf("unterminated block comment")
+			}
+		case c == '\'' || c == '"':
+			escapes := c == '\'' && i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e')
+			i++
+			for ; i < len(sql); i++ {
+				if escapes && sql[i] == '\\' {
+					i++
+					continue
+				}
+				if sql[i] == c {
+					if i+1 < len(sql) && sql[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			if i >= len(sql) {
+				return nil, errors.Newf("unterminated quote %c", c)
+			}
+			hasCode = true
+		case c == '$':
+			tag := dollarQuoteTag(sql[i:])
+			if tag == "" {
+				hasCode = true
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				return nil, errors.Newf("unterminated dollar quote %s", tag)
+			}
+			i += 2*len(tag) + end - 1
+			hasCode = true
+		case c == ';':
+			flush(i)
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			hasCode = true
+		}
+	}
+	if start < len(sql) {
+		flush(len(sql))
+	}
+	return statements, nil
+}
+
+// dollarQuoteTag returns the opening dollar quote tag the SQL starts with, such as `$$` or `$body$`.
+func dollarQuoteTag(sql string) string {
+	for i := 1; i < len(sql); i++ {
+		c := sql[i]
+		if c == '$' {
+			return sql[:i+1]
+		}
+		isLetter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+		if !isLetter && (i == 1 || c < '0' || c > '9') {
+			return ""
+		}
+	}
+	return ""
+}
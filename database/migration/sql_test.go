@@ -0,0 +1,141 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected []string
+		error    string
+	}{
+		{
+			name:     "single statement without semicolon",
+			sql:      "CREATE TABLE a (id INT)",
+			expected: []string{"CREATE TABLE a (id INT)"},
+		},
+		{
+			name:     "multiple statements",
+			sql:      "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			expected: []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:     "semicolons in quotes",
+			sql:      `INSERT INTO a VALUES ('a;b', 'it''s;'); SELECT "weird;column" FROM a`,
+			expected: []string{`INSERT INTO a VALUES ('a;b', 'it''s;')`, `SELECT "weird;column" FROM a`},
+		},
+		{
+			name:     "escaped string",
+			sql:      `SELECT E'\';'; SELECT 1`,
+			expected: []string{`SELECT E'\';'`, `SELECT 1`},
+		},
+		{
+			name:     "dollar quotes",
+			sql:      "CREATE FUNCTION f() RETURNS INT AS $body$ SELECT 1; $body$ LANGUAGE sql; SELECT $$;$$",
+			expected: []string{"CREATE FUNCTION f() RETURNS INT AS $body$ SELECT 1; $body$ LANGUAGE sql", "SELECT $$;$$"},
+		},
+		{
+			name:     "positional parameters are not dollar quotes",
+			sql:      "PREPARE p AS SELECT $1; SELECT 2",
+			expected: []string{"PREPARE p AS SELECT $1", "SELECT 2"},
+		},
+		{
+			name:     "comments",
+			sql:      "-- leading; comment\nSELECT 1; /* block; /* nested; */ comment */ SELECT 2;\n-- trailing comment",
+			expected: []string{"-- leading; comment\nSELECT 1", "/* block; /* nested; */ comment */ SELECT 2"},
+		},
+		{
+			name:  "unterminated quote",
+			sql:   "SELECT 'a",
+			error: "unterminated quote '",
+		},
+		{
+			name:  "unterminated dollar quote",
+			sql:   "SELECT $tag$ a",
+			error: "unterminated dollar quote $tag$",
+		},
+		{
+			name:  "unterminated block comment",
+			sql:   "SELECT 1 /* a",
+			error: "unterminated block comment",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements, err := splitStatements(tt.sql)
+			if tt.error != "" {
+				require.ErrorContains(t, err, tt.error)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, statements)
+		})
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20240102000000_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INT);")},
+		"20240102000000_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"20240101000000_index_users.up.sql": {Data: []byte(
+			"-- concurrent indexes cannot be created in a transaction\n-- rodent:no-transaction\nCREATE INDEX CONCURRENTLY i ON users (id);",
+		)},
+		"README.md": {Data: []byte("ignored")},
+	}
+	migrations, err := LoadFS(nil, fsys)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	users, index := migrations[0], migrations[1]
+	require.Equal(t, "create_users", users.Name())
+	require.Equal(t, time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC), users.Time())
+	require.NotNil(t, users.down)
+	require.False(t, users.noTx)
+	require.Equal(t, "index_users", index.Name())
+	require.Nil(t, index.down)
+	require.True(t, index.noTx)
+
+	fsys["20240102000000_create_users.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE users (id BIGINT);")}
+	modified, err := LoadFS(nil, fsys)
+	require.NoError(t, err)
+	require.NotEqual(t, users.Checksum(), modified[0].Checksum())
+}
+
+func TestLoadFSErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		fsys  fstest.MapFS
+		error string
+	}{
+		{
+			name:  "invalid name",
+			fsys:  fstest.MapFS{"create_users.sql": {}},
+			error: "SQL migration create_users.sql must be named",
+		},
+		{
+			name:  "missing up file",
+			fsys:  fstest.MapFS{"20240101000000_users.down.sql": {}},
+			error: "SQL migration users has no up file",
+		},
+		{
+			name: "mismatching timestamps",
+			fsys: fstest.MapFS{
+				"20240101000000_users.up.sql":   {},
+				"20240102000000_users.down.sql": {},
+			},
+			error: "SQL migration users has files with different timestamps",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFS(nil, tt.fsys)
+			require.ErrorContains(t, err, tt.error)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package migration
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+)
+
+type State string
+
+const (
+	StateApplied State = "applied"
+	StatePending State = "pending"
+	// StateModified is an applied migration which changed since, the Migrator refuses to run until it is reverted.
+	StateModified State = "modified"
+	// StateOutOfOrder is a pending migration which comes before an applied one, the Migrator refuses to apply it.
+	StateOutOfOrder State = "out of order"
+	// StateMissing is an applied migration which is not registered anymore.
+	StateMissing State = "missing"
+)
+
+// Status is the state of a migration, either registered or recorded as applied.
+type Status struct {
+	Name      string
+	Time      time.Time
+	State     State
+	AppliedAt time.Time
+	Duration  time.Duration
+	// Unchecked is a Go migration without Checksum, its changes are not detected.
+	Unchecked bool
+}
+
+// statuses merges the registered migrations and the applied records, in order.
+func statuses(migrations []*Migration, applied []Record) []Status {
+	records := make(map[string]Record, len(applied))
+	for _, record := range applied {
+		records[record.Name] = record
+	}
+	var latest *Migration
+	for _, migration := range migrations {
+		if _, ok := records[migration.name]; ok {
+			latest = migration
+		}
+	}
+	result := make([]Status, 0, len(migrations)+len(applied))
+	registered := make(map[string]struct{}, len(migrations))
+	for _, migration := range migrations {
+		registered[migration.name] = struct{}{}
+		status := Status{Name: migration.name, Time: migration.time, State: StatePending, Unchecked: migration.unchecked}
+		record, isApplied := records[migration.name]
+		switch {
+		case isApplied && (record.Checksum != migration.checksum || !record.Time.Equal(migration.time)):
+			status.State = StateModified
+		case isApplied:
+			status.State = StateApplied
+		case latest != nil && migration.before(latest.time, latest.name):
+			status.State = StateOutOfOrder
+		}
+		if isApplied {
+			status.AppliedAt, status.Duration = record.AppliedAt, record.Duration
+		}
+		result = append(result, status)
+	}
+	for _, record := range applied {
+		if _, ok := registered[record.Name]; ok {
+			continue
+		}
+		result = append(result, Status{
+			Name:      record.Name,
+			Time:      record.Time,
+			State:     StateMissing,
+			AppliedAt: record.AppliedAt,
+			Duration:  record.Duration,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Time.Equal(result[j].Time) {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result
+}
+
+// applied returns the applied migrations without holding the migration lock.
+func (m *Migrator) applied(ctx context.Context) ([]Record, error) {
+	if _, err := m.db.DB().ExecContext(ctx, Schema); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the schema migrations table")
+	}
+	return history(ctx, m.db.DB())
+}
+
+// Status returns the state of every migration, without applying any.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return statuses(m.migrations, applied), nil
+}
+
+// Validate checks that the pending migrations can be applied, without applying them.
+// The changes of the Unchecked migrations cannot be detected, they are logged as a warning.
+func (m *Migrator) Validate(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range m.Unchecked() {
+		logger.FromContext(ctx).Warn("migration has no checksum, its changes cannot be detected", props.MigrationName(name))
+	}
+	_, err = pending(m.migrations, applied)
+	return err
+}
+
+// Unchecked returns the names of the Go migrations without Checksum, in order.
+func (m *Migrator) Unchecked() []string {
+	var names []string
+	for _, migration := range m.migrations {
+		if migration.unchecked {
+			names = append(names, migration.name)
+		}
+	}
+	return names
+}
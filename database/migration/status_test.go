@@ -0,0 +1,44 @@
+package migration
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatuses(t *testing.T) {
+	at := func(day int) time.Time {
+		return time.Date(2024, time.January, day, 0, 0, 0, 0, time.UTC)
+	}
+	first := New("first", at(1), nil, nil)
+	second := New("second", at(2), nil, nil)
+	third := New("third", at(4), nil, nil)
+	fourth := New("fourth", at(5), nil, nil, ChecksumOf("CREATE TABLE fourth ()"))
+	appliedAt := at(10)
+	applied := []Record{
+		{Name: "first", Time: at(1), Checksum: "stale", AppliedAt: appliedAt},
+		{Name: "removed", Time: at(3), AppliedAt: appliedAt},
+		{Name: "third", Time: at(4), Checksum: third.checksum, AppliedAt: appliedAt, Duration: time.Second},
+	}
+
+	result := statuses([]*Migration{first, second, third, fourth}, applied)
+	require.Equal(t, []Status{
+		{Name: "first", Time: at(1), State: StateModified, AppliedAt: appliedAt, Unchecked: true},
+		{Name: "second", Time: at(2), State: StateOutOfOrder, Unchecked: true},
+		{Name: "removed", Time: at(3), State: StateMissing, AppliedAt: appliedAt},
+		{Name: "third", Time: at(4), State: StateApplied, AppliedAt: appliedAt, Duration: time.Second, Unchecked: true},
+		{Name: "fourth", Time: at(5), State: StatePending},
+	}, result)
+
+	var out bytes.Buffer
+	require.NoError(t, writeStatuses(&out, result[3:]))
+	require.Equal(t, ""+
+		"MIGRATION  TIME                 STATE    APPLIED AT           DURATION\n"+
+		"third      2024-01-04 00:00:00  applied  2024-01-10 00:00:00  1s\n"+
+		"fourth     2024-01-05 00:00:00  pending  -                    -\n"+
+		"warning: the changes of these Go migrations are not detected, give them a migration.Checksum: third\n",
+		out.String(),
+	)
+}
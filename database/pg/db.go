@@ -1,6 +1,7 @@
 package pg
 
 import (
+	"context"
 	"database/sql"
 
 	entsql "entgo.io/ent/dialect/sql"
@@ -8,9 +9,17 @@ import (
 
 const Dialect = "postgres"
 
-type Database struct {
-	db *sql.DB
-}
+type (
+	Database struct {
+		db *sql.DB
+	}
+	// Executor is implemented by both *sql.DB and *sql.Tx, so that a statement can join an existing transaction.
+	Executor interface {
+		ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+		QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	}
+)
 
 func NewDatabase(db *sql.DB) *Database {
 	return &Database{db: db}
@@ -19,3 +28,8 @@ func NewDatabase(db *sql.DB) *Database {
 func (d Database) Driver() *entsql.Driver {
 	return entsql.OpenDB(Dialect, d.db)
 }
+
+// DB returns the underlying connection pool.
+func (d Database) DB() *sql.DB {
+	return d.db
+}
@@ -0,0 +1,229 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/app/module"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+type (
+	ElectorOptions struct {
+		// Holder identifies this replica, it defaults to the hostname suffixed with a random id.
+		Holder string
+		// TTL is how long the leadership survives without being renewed.
+		TTL time.Duration
+		// RenewInterval is how often the leader renews its lease, and the followers campaign.
+		RenewInterval time.Duration
+		// OnElected is called when this replica becomes the leader, ctx is cancelled as soon as the leadership is lost.
+		OnElected func(ctx context.Context, lease Lease)
+		// OnLost is called when this replica stops being the leader.
+		OnLost func(lease Lease)
+	}
+	// LeaderElector campaigns for a named lease, the replica holding it is the leader.
+	// It is a module.Service, it starts campaigning on OnStart, and steps down on OnStop.
+	// The leases table is created by migration.LeaseMigration.
+	LeaderElector struct {
+		db        *Database
+		name      string
+		options   ElectorOptions
+		mu        sync.RWMutex
+		lease     *Lease
+		resign    context.CancelFunc
+		stopping  chan struct{}
+		done      chan struct{}
+		startedAt time.Time
+		// renewedAt is when the last successful renewal was sent, the lease is valid for TTL from then on.
+		// It is only accessed by the campaign goroutine.
+		renewedAt time.Time
+	}
+)
+
+func LeaseHolder(holder string) opt.Option[ElectorOptions] {
+	return func(opt *ElectorOptions) {
+		opt.Holder = holder
+	}
+}
+
+func LeaseTTL(ttl time.Duration) opt.Option[ElectorOptions] {
+	return func(opt *ElectorOptions) {
+		opt.TTL = ttl
+		opt.RenewInterval = ttl / 3
+	}
+}
+
+func OnElected(callback func(ctx context.Context, lease Lease)) opt.Option[ElectorOptions] {
+	return func(opt *ElectorOptions) {
+		opt.OnElected = callback
+	}
+}
+
+func OnLost(callback func(lease Lease)) opt.Option[ElectorOptions] {
+	return func(opt *ElectorOptions) {
+		opt.OnLost = callback
+	}
+}
+
+func defaultHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8])
+}
+
+func NewLeaderElector(db *Database, name string, opts ...opt.Option[ElectorOptions]) *LeaderElector {
+	options := ElectorOptions{
+		Holder:        defaultHolder(),
+		TTL:           15 * time.Second,
+		RenewInterval: 5 * time.Second,
+		OnElected:     func(context.Context, Lease) {},
+		OnLost:        func(Lease) {},
+	}
+	opt.Apply(&options, opts...)
+	return &LeaderElector{
+		db:      db,
+		name:    name,
+		options: options,
+	}
+}
+
+// IsLeader reports whether this replica currently holds the lease.
+func (e *LeaderElector) IsLeader() bool {
+	_, leading := e.Lease()
+	return leading
+}
+
+// Lease returns the lease held by this replica, its token can be used as a fencing token.
+func (e *LeaderElector) Lease() (Lease, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.lease == nil {
+		return Lease{}, false
+	}
+	return *e.lease, true
+}
+
+func (e *LeaderElector) OnStart(ctx context.Context) error {
+	e.mu.Lock()
+	e.stopping = make(chan struct{})
+	e.done = make(chan struct{})
+	e.startedAt = time.Now()
+	stopping, done := e.stopping, e.done
+	e.mu.Unlock()
+	go e.campaign(context.WithoutCancel(ctx), stopping, done)
+	return nil
+}
+
+// OnStop stops campaigning, and releases the lease if this replica is the leader.
+func (e *LeaderElector) OnStop(ctx context.Context) error {
+	e.mu.Lock()
+	stopping, done := e.stopping, e.done
+	e.stopping = nil
+	e.mu.Unlock()
+	if stopping == nil {
+		return nil
+	}
+	close(stopping)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "failed to stop campaigning for %s", e.name)
+	}
+	lease, leading := e.Lease()
+	if !leading {
+		return nil
+	}
+	e.stepDown(ctx, nil)
+	return e.db.ReleaseLease(ctx, &lease)
+}
+
+func (e *LeaderElector) Inspect() module.HealthCheckManifest {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	role := "follower"
+	if e.lease != nil {
+		role = "leader"
+	}
+	return module.HealthCheckManifest{
+		Name:        "pg.leader." + e.name,
+		Description: fmt.Sprintf("%s campaigning for %s, currently %s", e.options.Holder, e.name, role),
+		Optional:    true,
+		StartedAt:   e.startedAt,
+	}
+}
+
+func (e *LeaderElector) campaign(ctx context.Context, stopping <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	log := logger.FromContext(ctx).With(props.LeaseName(e.name), props.LeaseHolder(e.options.Holder))
+	for {
+		if lease, leading := e.Lease(); leading {
+			renewed := lease
+			sentAt := time.Now()
+			err := e.db.Renew(ctx, &renewed, e.options.TTL)
+			switch {
+			case err == nil:
+				e.renewedAt = sentAt
+				e.mu.Lock()
+				e.lease = &renewed
+				e.mu.Unlock()
+			case errors.Is(err, ErrLeaseLost) || time.Since(e.renewedAt) >= e.options.TTL:
+				log.Warn("lost leadership", props.LeaseToken(lease.Token), zap.Error(err))
+				e.stepDown(ctx, err)
+			default:
+				log.Error("failed to renew leadership, retrying until the lease expires", props.LeaseToken(lease.Token), zap.Error(err))
+			}
+		} else {
+			sentAt := time.Now()
+			lease, acquired, err := e.db.AcquireLease(ctx, e.name, e.options.Holder, e.options.TTL)
+			if err != nil {
+				log.Error("failed to campaign for leadership", zap.Error(err))
+			}
+			if acquired {
+				log.Info("elected leader", props.LeaseToken(lease.Token))
+				e.renewedAt = sentAt
+				e.elect(ctx, lease)
+			}
+		}
+		select {
+		case <-stopping:
+			return
+		case <-time.After(e.options.RenewInterval):
+		}
+	}
+}
+
+func (e *LeaderElector) elect(ctx context.Context, lease *Lease) {
+	leadership, resign := context.WithCancel(ctx)
+	e.mu.Lock()
+	e.lease = lease
+	e.resign = resign
+	e.mu.Unlock()
+	go e.options.OnElected(leadership, *lease)
+}
+
+// stepDown cancels the leadership context and notifies the OnLost callback.
+func (e *LeaderElector) stepDown(ctx context.Context, cause error) {
+	e.mu.Lock()
+	lease, resign := e.lease, e.resign
+	e.lease, e.resign = nil, nil
+	e.mu.Unlock()
+	if lease == nil {
+		return
+	}
+	resign()
+	if cause == nil {
+		logger.FromContext(ctx).Info("resigned leadership", props.LeaseName(e.name), props.LeaseToken(lease.Token))
+	}
+	e.options.OnLost(*lease)
+}
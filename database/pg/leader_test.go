@@ -0,0 +1,113 @@
+package pg_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/database/pg/pgtest"
+)
+
+func TestLeaderElector(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, pg.LeaseSchema)
+	ctx := context.Background()
+
+	var lost atomic.Int32
+	resigned := make(chan struct{})
+	a := pg.NewLeaderElector(db, "reports",
+		pg.LeaseHolder("replica-a"),
+		pg.LeaseTTL(time.Second),
+		pg.OnElected(func(ctx context.Context, lease pg.Lease) {
+			<-ctx.Done()
+			close(resigned)
+		}),
+		pg.OnLost(func(pg.Lease) { lost.Add(1) }),
+	)
+	b := pg.NewLeaderElector(db, "reports", pg.LeaseHolder("replica-b"), pg.LeaseTTL(time.Second))
+
+	r.NoError(a.OnStart(ctx))
+	r.Eventually(a.IsLeader, time.Second*5, time.Millisecond*10)
+	r.NoError(b.OnStart(ctx))
+	t.Cleanup(func() { _ = b.OnStop(ctx) })
+	time.Sleep(time.Millisecond * 500)
+	r.False(b.IsLeader(), "a single replica leads")
+	first, _ := a.Lease()
+
+	r.NoError(a.OnStop(ctx))
+	r.NoError(a.OnStop(ctx), "stopping twice is a no-op")
+	r.False(a.IsLeader())
+	<-resigned
+	r.EqualValues(1, lost.Load())
+
+	r.Eventually(b.IsLeader, time.Second*5, time.Millisecond*10)
+	second, _ := b.Lease()
+	r.Greater(second.Token, first.Token)
+}
+
+func TestLeaderElectorStepsDown(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, pg.LeaseSchema)
+	ctx := context.Background()
+
+	lost := make(chan pg.Lease, 1)
+	elector := pg.NewLeaderElector(db, "reports",
+		pg.LeaseHolder("replica-a"),
+		pg.LeaseTTL(time.Millisecond*300),
+		pg.OnLost(func(lease pg.Lease) { lost <- lease }),
+	)
+	r.NoError(elector.OnStart(ctx))
+	t.Cleanup(func() { _ = elector.OnStop(ctx) })
+	r.Eventually(elector.IsLeader, time.Second*5, time.Millisecond*10)
+	lease, _ := elector.Lease()
+
+	// another holder took over, as if this replica had been partitioned away past its TTL
+	pgtest.Exec(t, db, `UPDATE rodent_leases SET holder = 'replica-b', token = token + 1, expires_at = now() + interval '1 hour'`)
+	select {
+	case stale := <-lost:
+		r.Equal(lease.Token, stale.Token)
+	case <-time.After(time.Second * 5):
+		r.Fail("the leader did not step down")
+	}
+	r.False(elector.IsLeader())
+}
+
+func TestLeaderElectorRetriesTransientErrors(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, pg.LeaseSchema)
+	ctx := context.Background()
+
+	lost := make(chan pg.Lease, 1)
+	elector := pg.NewLeaderElector(db, "digests",
+		pg.LeaseHolder("replica-a"),
+		pg.LeaseTTL(time.Second*3),
+		pg.OnLost(func(lease pg.Lease) { lost <- lease }),
+	)
+	r.NoError(elector.OnStart(ctx))
+	t.Cleanup(func() { _ = elector.OnStop(ctx) })
+	r.Eventually(elector.IsLeader, time.Second*5, time.Millisecond*10)
+
+	// the renewals fail, but not because the lease was lost: the leader keeps it until its TTL passed
+	pgtest.Exec(t, db, `ALTER TABLE rodent_leases RENAME TO rodent_leases_away`)
+	time.Sleep(time.Millisecond * 1500)
+	r.True(elector.IsLeader())
+	pgtest.Exec(t, db, `ALTER TABLE rodent_leases_away RENAME TO rodent_leases`)
+	time.Sleep(time.Millisecond * 1500)
+	r.True(elector.IsLeader())
+
+	pgtest.Exec(t, db, `ALTER TABLE rodent_leases RENAME TO rodent_leases_away`)
+	t.Cleanup(func() { pgtest.Exec(t, db, `ALTER TABLE IF EXISTS rodent_leases_away RENAME TO rodent_leases`) })
+	select {
+	case <-lost:
+	case <-time.After(time.Second * 6):
+		r.Fail("the leader did not step down once its lease expired")
+	}
+	r.False(elector.IsLeader())
+}
@@ -0,0 +1,100 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// LeaseSchema creates the leases table, register migration.LeaseMigration to create it.
+const LeaseSchema = `
+CREATE TABLE IF NOT EXISTS rodent_leases (
+	name       TEXT PRIMARY KEY,
+	holder     TEXT        NOT NULL,
+	token      BIGINT      NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+);
+`
+
+// Lease is a time-bound ownership of a name, its Token strictly increases every time the lease changes hands,
+// so that it can be used as a fencing token by the resources the holder writes to.
+type Lease struct {
+	Name      string
+	Holder    string
+	Token     int64
+	ExpiresAt time.Time
+}
+
+var ErrLeaseLost = errors.Newf("lease is no longer held")
+
+// AcquireLease takes the named lease if it is free or expired, it returns false if another holder owns it.
+func (d Database) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (*Lease, bool, error) {
+	lease := &Lease{Name: name, Holder: holder}
+	err := d.db.QueryRowContext(ctx, `
+		INSERT INTO rodent_leases (name, holder, token, expires_at)
+		VALUES ($1, $2, 1, now() + $3 * interval '1 millisecond')
+		ON CONFLICT (name) DO UPDATE
+		SET holder = EXCLUDED.holder, token = rodent_leases.token + 1, expires_at = EXCLUDED.expires_at
+		WHERE rodent_leases.expires_at < now() OR rodent_leases.holder = EXCLUDED.holder
+		RETURNING token, expires_at`,
+		name, holder, ttl.Milliseconds(),
+	).Scan(&lease.Token, &lease.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to acquire lease %s", name)
+	}
+	return lease, true, nil
+}
+
+// Renew extends the lease, it fails with ErrLeaseLost if the lease expired or changed hands meanwhile.
+func (d Database) Renew(ctx context.Context, lease *Lease, ttl time.Duration) error {
+	err := d.db.QueryRowContext(ctx, `
+		UPDATE rodent_leases SET expires_at = now() + $4 * interval '1 millisecond'
+		WHERE name = $1 AND holder = $2 AND token = $3 AND expires_at > now()
+		RETURNING expires_at`,
+		lease.Name, lease.Holder, lease.Token, ttl.Milliseconds(),
+	).Scan(&lease.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.Wrapf(ErrLeaseLost, "failed to renew lease %s", lease.Name)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to renew lease %s", lease.Name)
+	}
+	return nil
+}
+
+// ReleaseLease expires the lease right away, the token is kept so that it keeps increasing.
+func (d Database) ReleaseLease(ctx context.Context, lease *Lease) error {
+	_, err := d.db.ExecContext(ctx, `
+		UPDATE rodent_leases SET expires_at = now()
+		WHERE name = $1 AND holder = $2 AND token = $3`,
+		lease.Name, lease.Holder, lease.Token,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to release lease %s", lease.Name)
+	}
+	return nil
+}
+
+// CheckFence fails with ErrLeaseLost unless the lease is still held with the same token.
+// Run it with the transaction of a protected write, the lease row stays locked until the transaction ends.
+func CheckFence(ctx context.Context, exec Executor, lease *Lease) error {
+	var token int64
+	err := exec.QueryRowContext(ctx, `
+		SELECT token FROM rodent_leases
+		WHERE name = $1 AND holder = $2 AND expires_at > now()
+		FOR SHARE`,
+		lease.Name, lease.Holder,
+	).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && token != lease.Token) {
+		return errors.Wrapf(ErrLeaseLost, "fencing token %d of lease %s is stale", lease.Token, lease.Name)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to check the fencing token of lease %s", lease.Name)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package pg_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/database/pg/pgtest"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+func TestLease(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, pg.LeaseSchema)
+	ctx := context.Background()
+
+	first, acquired, err := db.AcquireLease(ctx, "reports", "replica-a", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+	_, acquired, err = db.AcquireLease(ctx, "reports", "replica-b", time.Minute)
+	r.NoError(err)
+	r.False(acquired, "the lease is held by replica-a")
+
+	expiresAt := first.ExpiresAt
+	r.NoError(db.Renew(ctx, first, time.Hour))
+	r.True(first.ExpiresAt.After(expiresAt))
+	r.NoError(pg.CheckFence(ctx, db.DB(), first))
+
+	r.NoError(db.ReleaseLease(ctx, first))
+	r.True(errors.Is(db.Renew(ctx, first, time.Minute), pg.ErrLeaseLost), "a released lease cannot be renewed")
+	second, acquired, err := db.AcquireLease(ctx, "reports", "replica-b", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+	r.Greater(second.Token, first.Token, "the token increases every time the lease changes hands")
+
+	r.True(errors.Is(pg.CheckFence(ctx, db.DB(), first), pg.ErrLeaseLost), "the previous holder is fenced off")
+	r.NoError(pg.CheckFence(ctx, db.DB(), second))
+}
+
+func TestLeaseExpires(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, pg.LeaseSchema)
+	ctx := context.Background()
+
+	first, acquired, err := db.AcquireLease(ctx, "reports", "replica-a", time.Millisecond*50)
+	r.NoError(err)
+	r.True(acquired)
+	time.Sleep(time.Millisecond * 100)
+	r.True(errors.Is(db.Renew(ctx, first, time.Minute), pg.ErrLeaseLost), "an expired lease cannot be renewed")
+
+	second, acquired, err := db.AcquireLease(ctx, "reports", "replica-b", time.Minute)
+	r.NoError(err)
+	r.True(acquired)
+	r.Greater(second.Token, first.Token)
+}
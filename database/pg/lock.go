@@ -0,0 +1,93 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"sync"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// Lock is a session-level advisory lock, it is held by a dedicated connection until it is released.
+type Lock struct {
+	Name string
+	Key  int64
+	mu   sync.Mutex
+	conn *sql.Conn
+	stop func() bool
+}
+
+// LockKey derives the advisory lock key of a name.
+func LockKey(name string) int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(name))
+	return int64(hash.Sum64())
+}
+
+// Lock waits until the named advisory lock is acquired, or the context is done.
+// The lock is released when the context is done, or when Release is called, whichever comes first.
+func (d Database) Lock(ctx context.Context, name string) (*Lock, error) {
+	lock, acquired, err := d.lock(ctx, name, `SELECT true FROM pg_advisory_lock($1)`)
+	if err == nil && !acquired {
+		err = errors.Newf("advisory lock %s was not acquired", name)
+	}
+	return lock, err
+}
+
+// TryLock acquires the named advisory lock if it is free, without waiting.
+// The lock is released when the context is done, or when Release is called, whichever comes first.
+func (d Database) TryLock(ctx context.Context, name string) (*Lock, bool, error) {
+	return d.lock(ctx, name, `SELECT pg_try_advisory_lock($1)`)
+}
+
+func (d Database) lock(ctx context.Context, name string, query string) (*Lock, bool, error) {
+	// session locks belong to a connection, the same one must release it
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to get a connection to lock %s", name)
+	}
+	key := LockKey(name)
+	var acquired bool
+	if err = conn.QueryRowContext(ctx, query, key).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return nil, false, errors.Wrapf(err, "failed to acquire advisory lock %s", name)
+	}
+	if !acquired {
+		_ = conn.Close()
+		return nil, false, nil
+	}
+	lock := &Lock{
+		Name: name,
+		Key:  key,
+		conn: conn,
+	}
+	lock.stop = context.AfterFunc(ctx, func() {
+		_ = lock.release(context.WithoutCancel(ctx))
+	})
+	return lock, true, nil
+}
+
+// Release unlocks the advisory lock and returns its connection to the pool, it is safe to call more than once.
+func (l *Lock) Release(ctx context.Context) error {
+	l.stop()
+	return l.release(ctx)
+}
+
+func (l *Lock) release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return nil
+	}
+	conn := l.conn
+	l.conn = nil
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, l.Key); err != nil {
+		// discarding the connection ends the session, which releases the lock anyway
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		return errors.Wrapf(err, "failed to release advisory lock %s", l.Name)
+	}
+	return nil
+}
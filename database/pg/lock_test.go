@@ -0,0 +1,53 @@
+package pg_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/pg/pgtest"
+)
+
+func TestTryLock(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	ctx := context.Background()
+
+	lock, acquired, err := db.TryLock(ctx, "reports")
+	r.NoError(err)
+	r.True(acquired)
+	_, acquired, err = db.TryLock(ctx, "reports")
+	r.NoError(err)
+	r.False(acquired, "another session holds the lock")
+	other, acquired, err := db.TryLock(ctx, "invoices")
+	r.NoError(err)
+	r.True(acquired, "locks are keyed by name")
+	r.NoError(other.Release(ctx))
+
+	r.NoError(lock.Release(ctx))
+	r.NoError(lock.Release(ctx), "releasing twice is a no-op")
+	lock, acquired, err = db.TryLock(ctx, "reports")
+	r.NoError(err)
+	r.True(acquired)
+	r.NoError(lock.Release(ctx))
+}
+
+func TestLockReleasedWithContext(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, acquired, err := db.TryLock(ctx, "reports")
+	r.NoError(err)
+	r.True(acquired)
+	cancel()
+	r.Eventually(func() bool {
+		lock, acquired, err := db.TryLock(context.Background(), "reports")
+		if err != nil || !acquired {
+			return false
+		}
+		return lock.Release(context.Background()) == nil
+	}, time.Second*5, time.Millisecond*10)
+}
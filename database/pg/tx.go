@@ -0,0 +1,249 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+type (
+	// Backoff computes the delay to wait before each retry of a transaction, attempts start at 1.
+	Backoff func(attempt int) time.Duration
+	// TxFunc is run within a transaction, ctx carries the transaction so that nested calls join it.
+	TxFunc    func(ctx context.Context, tx *sql.Tx) error
+	TxOptions struct {
+		Isolation sql.IsolationLevel
+		ReadOnly  bool
+		// MaxAttempts is how many times the transaction runs when it fails with a serialization failure or a deadlock.
+		MaxAttempts int
+		Backoff     Backoff
+	}
+	txState struct {
+		tx         *sql.Tx
+		mu         sync.Mutex
+		savepoints int
+		hooks      []func(ctx context.Context)
+	}
+	txKey       struct{}
+	databaseKey struct{}
+)
+
+func Isolation(level sql.IsolationLevel) opt.Option[TxOptions] {
+	return func(opt *TxOptions) {
+		opt.Isolation = level
+	}
+}
+
+func ReadOnly() opt.Option[TxOptions] {
+	return func(opt *TxOptions) {
+		opt.ReadOnly = true
+	}
+}
+
+func MaxAttempts(attempts int) opt.Option[TxOptions] {
+	return func(opt *TxOptions) {
+		opt.MaxAttempts = attempts
+	}
+}
+
+func RetryBackoff(initial, max time.Duration, multiplier float64) opt.Option[TxOptions] {
+	return func(opt *TxOptions) {
+		opt.Backoff = ExponentialBackoff(initial, max, multiplier)
+	}
+}
+
+// ExponentialBackoff waits initial after the first failure, and multiplies the delay after every other one, up to max.
+func ExponentialBackoff(initial, max time.Duration, multiplier float64) Backoff {
+	return func(attempt int) time.Duration {
+		if attempt < 1 {
+			attempt = 1
+		}
+		delay := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+		if max > 0 && delay > float64(max) {
+			return max
+		}
+		return time.Duration(delay)
+	}
+}
+
+// WithDatabase attaches the database to the context, the transactions started by WithTx run on it.
+func WithDatabase(ctx context.Context, db *Database) context.Context {
+	return context.WithValue(ctx, databaseKey{}, db)
+}
+
+// DatabaseFromContext returns the database attached by WithDatabase, if any.
+func DatabaseFromContext(ctx context.Context) *Database {
+	if db, ok := ctx.Value(databaseKey{}).(*Database); ok {
+		return db
+	}
+	return nil
+}
+
+// TxFromContext returns the transaction carried by the context, if any.
+func TxFromContext(ctx context.Context) *sql.Tx {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	return nil
+}
+
+// Executor returns the transaction carried by the context, or the connection pool outside a transaction.
+func (d Database) Executor(ctx context.Context) Executor {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return d.db
+}
+
+// AfterCommit runs the hook once the transaction of the context committed, it is dropped if the transaction,
+// or the savepoint it was registered in, is rolled back. The hook runs right away outside a transaction.
+func AfterCommit(ctx context.Context, hook func(ctx context.Context)) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		hook(ctx)
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.hooks = append(state.hooks, hook)
+}
+
+// IsRetryable reports whether the error is a serialization failure or a deadlock, which succeed when retried.
+func IsRetryable(err error) bool {
+	pqErr := errors.As[*pq.Error](err)
+	if pqErr == nil {
+		return false
+	}
+	code := string((*pqErr).Code)
+	return code == sqlStateSerializationFailure || code == sqlStateDeadlockDetected
+}
+
+// WithTx runs fn within a transaction, which is committed if fn succeeds and rolled back otherwise.
+// When the context already carries a transaction, fn joins it through a savepoint, and the options are ignored.
+// Otherwise, the transaction is started on the database attached by WithDatabase.
+// Serialization failures and deadlocks are retried with a backoff, so fn must be safe to run more than once.
+func WithTx(ctx context.Context, fn TxFunc, opts ...opt.Option[TxOptions]) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.savepoint(ctx, fn)
+	}
+	db := DatabaseFromContext(ctx)
+	if db == nil {
+		return errors.Newf("pg.WithTx requires a database, attach one with pg.WithDatabase")
+	}
+	return db.WithTx(ctx, fn, opts...)
+}
+
+// WithTx runs fn within a transaction of the database, see the WithTx function.
+func (d Database) WithTx(ctx context.Context, fn TxFunc, opts ...opt.Option[TxOptions]) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.savepoint(ctx, fn)
+	}
+	options := TxOptions{
+		Isolation:   sql.LevelDefault,
+		MaxAttempts: 3,
+		Backoff:     ExponentialBackoff(10*time.Millisecond, time.Second, 2),
+	}
+	opt.Apply(&options, opts...)
+
+	for attempt := 1; ; attempt++ {
+		hooks, err := d.runTx(ctx, fn, options)
+		if err == nil {
+			for _, hook := range hooks {
+				hook(ctx)
+			}
+			return nil
+		}
+		if !IsRetryable(err) || attempt >= options.MaxAttempts {
+			return err
+		}
+		delay := options.Backoff(attempt)
+		logger.FromContext(ctx).Debug("retrying transaction",
+			zap.Int("tx.attempt", attempt),
+			zap.Duration("tx.backoff", delay),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "transaction retry aborted")
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (d Database) runTx(ctx context.Context, fn TxFunc, options TxOptions) (_ []func(ctx context.Context), err error) {
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: options.Isolation,
+		ReadOnly:  options.ReadOnly,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to begin transaction")
+	}
+	state := &txState{tx: tx}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			_ = tx.Rollback()
+			panic(recovered)
+		}
+	}()
+	if err = fn(context.WithValue(ctx, txKey{}, state), tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, errors.Wrapf(err, "failed to rollback transaction: %s", rollbackErr)
+		}
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return state.hooks, nil
+}
+
+// savepoint runs fn within a savepoint of the transaction, a failure only rolls back what fn did.
+func (s *txState) savepoint(ctx context.Context, fn TxFunc) (err error) {
+	s.mu.Lock()
+	s.savepoints++
+	name := fmt.Sprintf("rodent_savepoint_%d", s.savepoints)
+	hooks := len(s.hooks)
+	s.mu.Unlock()
+
+	if _, err = s.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return errors.Wrapf(err, "failed to create savepoint %s", name)
+	}
+	rollback := func() error {
+		s.mu.Lock()
+		s.hooks = s.hooks[:hooks]
+		s.mu.Unlock()
+		_, err := s.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		return err
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			_ = rollback()
+			panic(recovered)
+		}
+	}()
+	if err = fn(ctx, s.tx); err != nil {
+		if rollbackErr := rollback(); rollbackErr != nil {
+			return errors.Wrapf(err, "failed to rollback to savepoint %s: %s", name, rollbackErr)
+		}
+		return err
+	}
+	if _, err = s.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return errors.Wrapf(err, "failed to release savepoint %s", name)
+	}
+	return nil
+}
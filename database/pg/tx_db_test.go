@@ -0,0 +1,147 @@
+package pg_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/database/pg/pgtest"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+func names(t *testing.T, db *pg.Database) []string {
+	t.Helper()
+	rows, err := db.DB().QueryContext(context.Background(), `SELECT name FROM tx_test ORDER BY name`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Err())
+	return names
+}
+
+func insert(ctx context.Context, db *pg.Database, name string) error {
+	_, err := db.Executor(ctx).ExecContext(ctx, `INSERT INTO tx_test (name) VALUES ($1)`, name)
+	return err
+}
+
+func TestWithTxSavepoints(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, `CREATE TABLE tx_test (name TEXT PRIMARY KEY)`)
+	ctx := pg.WithDatabase(context.Background(), db)
+
+	var committed []string
+	err := pg.WithTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		r.Same(tx, pg.TxFromContext(ctx))
+		if err := insert(ctx, db, "outer"); err != nil {
+			return err
+		}
+		pg.AfterCommit(ctx, func(context.Context) { committed = append(committed, "outer") })
+		err := pg.WithTx(ctx, func(ctx context.Context, nested *sql.Tx) error {
+			r.Same(tx, nested, "nested calls join the transaction")
+			if err := insert(ctx, db, "rolled back"); err != nil {
+				return err
+			}
+			pg.AfterCommit(ctx, func(context.Context) { committed = append(committed, "rolled back") })
+			return errors.Newf("nested failure")
+		})
+		r.ErrorContains(err, "nested failure")
+		r.Empty(committed, "hooks wait for the commit")
+		return pg.WithTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+			pg.AfterCommit(ctx, func(context.Context) { committed = append(committed, "inner") })
+			return insert(ctx, db, "inner")
+		})
+	})
+	r.NoError(err)
+	r.Equal([]string{"inner", "outer"}, names(t, db))
+	r.Equal([]string{"outer", "inner"}, committed, "the hooks of a rolled back savepoint are dropped")
+}
+
+func TestWithTxRollback(t *testing.T) {
+	r := require.New(t)
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, `CREATE TABLE tx_test (name TEXT PRIMARY KEY)`)
+	ctx := context.Background()
+
+	hooked := false
+	err := db.WithTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+		pg.AfterCommit(ctx, func(context.Context) { hooked = true })
+		if err := insert(ctx, db, "ada"); err != nil {
+			return err
+		}
+		return errors.Newf("failure")
+	})
+	r.ErrorContains(err, "failure")
+	r.Empty(names(t, db))
+	r.False(hooked)
+
+	r.Panics(func() {
+		_ = db.WithTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+			if err := insert(ctx, db, "ada"); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	})
+	r.Empty(names(t, db))
+}
+
+func TestWithTxRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		err      error
+		attempts int
+		fails    bool
+	}{
+		{name: "serialization failure", failures: 1, err: &pq.Error{Code: "40001"}, attempts: 2},
+		{name: "deadlock", failures: 2, err: &pq.Error{Code: "40P01"}, attempts: 3},
+		{name: "attempts exhausted", failures: 3, err: &pq.Error{Code: "40001"}, attempts: 3, fails: true},
+		{name: "not retryable", failures: 1, err: &pq.Error{Code: "23505"}, attempts: 1, fails: true},
+	}
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, `CREATE TABLE tx_test (name TEXT PRIMARY KEY)`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			pgtest.Exec(t, db, `TRUNCATE tx_test`)
+			attempts := 0
+			err := db.WithTx(context.Background(), func(ctx context.Context, _ *sql.Tx) error {
+				attempts++
+				if err := insert(ctx, db, "ada"); err != nil {
+					return err
+				}
+				if attempts <= tt.failures {
+					return tt.err
+				}
+				return nil
+			}, pg.Isolation(sql.LevelSerializable), pg.MaxAttempts(3))
+			r.Equal(tt.attempts, attempts)
+			if tt.fails {
+				r.Error(err)
+				r.Empty(names(t, db), "every failed attempt is rolled back")
+				return
+			}
+			r.NoError(err)
+			r.Equal([]string{"ada"}, names(t, db))
+		})
+	}
+}
+
+func TestWithTxReadOnly(t *testing.T) {
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, `CREATE TABLE tx_test (name TEXT PRIMARY KEY)`)
+	err := db.WithTx(context.Background(), func(ctx context.Context, _ *sql.Tx) error {
+		return insert(ctx, db, "ada")
+	}, pg.ReadOnly())
+	require.Error(t, err)
+}
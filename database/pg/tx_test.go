@@ -0,0 +1,37 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"unrelated", errors.Newf("boom"), false},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"serialization failure", &pq.Error{Code: "40001"}, true},
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"wrapped", errors.Wrapf(&pq.Error{Code: "40001"}, "failed to commit"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestWithTxRequiresDatabase(t *testing.T) {
+	err := WithTx(context.Background(), func(context.Context, *sql.Tx) error { return nil })
+	require.ErrorContains(t, err, "pg.WithTx requires a database")
+}
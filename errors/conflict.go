@@ -0,0 +1,32 @@
+package errors
+
+import "fmt"
+
+// ConflictError is returned when a resource was modified since the version the change was based on.
+type ConflictError struct {
+	Entity              string
+	RequestedIdentifier string
+	Expected            int
+	// Actual is the current version of the resource, zero when it is unknown.
+	Actual int
+}
+
+func (e ConflictError) Error() string {
+	if e.Actual == 0 {
+		return fmt.Sprintf("Entity '%s' with identifier '%s' is no longer at version %d", e.Entity, e.RequestedIdentifier, e.Expected)
+	}
+	return fmt.Sprintf("Entity '%s' with identifier '%s' is at version %d, not %d", e.Entity, e.RequestedIdentifier, e.Actual, e.Expected)
+}
+
+func Conflict(entity string, requestedIdentifier string, expected int, actual int) ConflictError {
+	return ConflictError{
+		Entity:              entity,
+		RequestedIdentifier: requestedIdentifier,
+		Expected:            expected,
+		Actual:              actual,
+	}
+}
+
+func IsConflict(err error) bool {
+	return As[ConflictError](err) != nil
+}
@@ -0,0 +1,219 @@
+package events
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/telemetry"
+)
+
+type (
+	// Bus delivers the published events to every Handler accepting them.
+	Bus struct {
+		handlers []*Handler
+		config   BusConfig
+		tracer   trace.Tracer
+		mu       sync.RWMutex
+		closed   bool
+		queue    chan delivery
+		workers  sync.WaitGroup
+	}
+	BusParams struct {
+		fx.In
+		Config    *BusConfig           `optional:"true"`
+		Telemetry *telemetry.Telemetry `optional:"true"`
+		Handlers  []*Handler           `group:"events.handler"`
+	}
+	delivery struct {
+		ctx     context.Context
+		handler *Handler
+		event   any
+		link    trace.Link
+	}
+	busKey struct{}
+)
+
+var (
+	ErrBusClosed = errors.Newf("event bus is closed")
+	defaultBus   atomic.Pointer[Bus]
+)
+
+func NewBus(params BusParams) *Bus {
+	if params.Config == nil {
+		params.Config = DefaultBusConfig()
+	}
+	var tracer trace.Tracer
+	if params.Telemetry != nil {
+		tracer = params.Telemetry.Tracer("events")
+	} else {
+		tracer = otel.Tracer("events")
+	}
+	log := logger.New()
+	for _, handler := range params.Handlers {
+		log.Debug("registered event handler",
+			props.EventHandler(handler.Name),
+			props.EventType(handler.EventType.String()),
+			zap.Bool("event.handler.async", handler.Async),
+		)
+	}
+	return &Bus{
+		handlers: params.Handlers,
+		config:   *params.Config,
+		tracer:   tracer,
+		queue:    make(chan delivery, max(params.Config.BufferSize, 0)),
+	}
+}
+
+// WithBus makes Publish use the given bus instead of the application one.
+func WithBus(ctx context.Context, bus *Bus) context.Context {
+	return context.WithValue(ctx, busKey{}, bus)
+}
+
+// FromContext returns the bus carried by the context, or the bus of the running application.
+func FromContext(ctx context.Context) *Bus {
+	if bus, ok := ctx.Value(busKey{}).(*Bus); ok {
+		return bus
+	}
+	return defaultBus.Load()
+}
+
+// Publish delivers the event through the bus of the context, see Bus.Publish.
+func Publish(ctx context.Context, event any) error {
+	bus := FromContext(ctx)
+	if bus == nil {
+		return errors.Newf("no event bus to publish %T to, is events.Module part of the application?", event)
+	}
+	return bus.Publish(ctx, event)
+}
+
+// Publish delivers the event to the synchronous handlers right away, and queues it for the asynchronous ones.
+// Every handler is isolated from the others, the returned error combines the failures of the synchronous handlers,
+// and of the asynchronous deliveries which could not be queued.
+func (b *Bus) Publish(ctx context.Context, event any) error {
+	eventType := reflect.TypeOf(event)
+	if eventType == nil {
+		return errors.Newf("cannot publish a nil event")
+	}
+	ctx, span := b.tracer.Start(ctx, "events.publish "+eventType.String(),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("event.type", eventType.String())),
+	)
+	defer span.End()
+
+	var err error
+	for _, handler := range b.handlers {
+		if !handler.accepts(eventType) {
+			continue
+		}
+		if handler.Async {
+			err = multierr.Append(err, b.enqueue(ctx, delivery{
+				ctx:     context.WithoutCancel(ctx),
+				handler: handler,
+				event:   event,
+				link:    trace.LinkFromContext(ctx),
+			}))
+			continue
+		}
+		err = multierr.Append(err, b.deliver(ctx, handler, event))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func (b *Bus) enqueue(ctx context.Context, d delivery) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return errors.Wrapf(ErrBusClosed, "cannot deliver %T to %s", d.event, d.handler.Name)
+	}
+	select {
+	case b.queue <- d:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "cannot queue %T for %s", d.event, d.handler.Name)
+	}
+}
+
+func (b *Bus) deliver(ctx context.Context, handler *Handler, event any, opts ...trace.SpanStartOption) error {
+	opts = append(opts,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("event.type", handler.EventType.String()),
+			attribute.String("event.handler", handler.Name),
+			attribute.Bool("event.handler.async", handler.Async),
+		),
+	)
+	ctx, span := b.tracer.Start(ctx, "events.handle "+handler.Name, opts...)
+	defer span.End()
+	if err := handler.deliver(ctx, event); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (b *Bus) work() {
+	defer b.workers.Done()
+	for d := range b.queue {
+		// asynchronous deliveries get their own trace, linked to the one of the publisher
+		err := b.deliver(d.ctx, d.handler, d.event, trace.WithNewRoot(), trace.WithLinks(d.link))
+		if err != nil {
+			logger.FromContext(d.ctx).Error("asynchronous event delivery failed",
+				props.EventHandler(d.handler.Name),
+				props.EventType(d.handler.EventType.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (b *Bus) OnStart(context.Context) error {
+	for range max(b.config.Workers, 1) {
+		b.workers.Add(1)
+		go b.work()
+	}
+	defaultBus.Store(b)
+	return nil
+}
+
+// OnStop stops accepting asynchronous deliveries, and drains the pending ones until the context expires.
+func (b *Bus) OnStop(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+	defaultBus.CompareAndSwap(b, nil)
+
+	drained := make(chan struct{})
+	go func() {
+		b.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		log.Warn("event bus stopped before draining every pending event", zap.Int("event.pending", len(b.queue)))
+		return errors.Wrapf(ctx.Err(), "failed to drain the event bus")
+	}
+}
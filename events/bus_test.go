@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+type (
+	userCreated struct{ Name string }
+	userDeleted struct{ Name string }
+	named       interface{ name() string }
+)
+
+func (u userCreated) name() string { return u.Name }
+func (u userDeleted) name() string { return u.Name }
+
+type recorder struct {
+	mu       sync.Mutex
+	received []string
+}
+
+func (r *recorder) record(value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received = append(r.received, value)
+}
+
+func (r *recorder) values() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.received...)
+}
+
+func TestBus(t *testing.T) {
+	r := require.New(t)
+	spans := tracetest.NewInMemoryExporter()
+	tracerProvider := trace.NewTracerProvider(trace.WithSyncer(spans))
+	synced, queued, matched := &recorder{}, &recorder{}, &recorder{}
+	bus := NewBus(BusParams{
+		Config: NewBusConfig(BufferSize(8), Workers(1)),
+		Handlers: []*Handler{
+			NewHandler("failing", func(ctx context.Context, event userCreated) error {
+				return errors.Newf("boom")
+			}),
+			NewHandler("panicking", func(ctx context.Context, event userCreated) error {
+				panic("kaboom")
+			}),
+			NewHandler("sync", func(ctx context.Context, event userCreated) error {
+				synced.record(event.Name)
+				return nil
+			}),
+			NewHandler("async", func(ctx context.Context, event userCreated) error {
+				queued.record(event.Name)
+				return nil
+			}, Async()),
+			NewHandler("named", func(ctx context.Context, event named) error {
+				matched.record(event.name())
+				return nil
+			}),
+		},
+	})
+	bus.tracer = tracerProvider.Tracer("events")
+	r.NoError(bus.OnStart(context.Background()))
+	r.Same(bus, FromContext(context.Background()))
+
+	err := Publish(context.Background(), userCreated{Name: "ada"})
+	r.ErrorContains(err, "boom")
+	r.ErrorContains(err, "handler failing failed")
+	r.ErrorContains(err, "handler panicking panicked: kaboom")
+	r.NoError(Publish(context.Background(), userDeleted{Name: "bob"}))
+	r.Equal([]string{"ada"}, synced.values())
+	r.Equal([]string{"ada", "bob"}, matched.values())
+
+	r.NoError(bus.OnStop(context.Background()))
+	r.Equal([]string{"ada"}, queued.values())
+	r.Nil(FromContext(context.Background()))
+	r.ErrorIs(bus.Publish(context.Background(), userCreated{Name: "eve"}), ErrBusClosed)
+
+	var publish, asyncHandle trace.ReadOnlySpan
+	for _, span := range spans.GetSpans().Snapshots() {
+		switch span.Name() {
+		case "events.publish events.userCreated":
+			if publish == nil {
+				publish = span
+			}
+		case "events.handle async":
+			asyncHandle = span
+		}
+	}
+	r.NotNil(publish)
+	r.NotNil(asyncHandle)
+	r.NotEqual(publish.SpanContext().TraceID(), asyncHandle.SpanContext().TraceID())
+	r.Len(asyncHandle.Links(), 1)
+	r.Equal(publish.SpanContext().SpanID(), asyncHandle.Links()[0].SpanContext.SpanID())
+}
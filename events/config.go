@@ -0,0 +1,36 @@
+package events
+
+import "github.com/kiwiworks/rodent/system/opt"
+
+// BusConfig configures the asynchronous delivery of the Bus.
+type BusConfig struct {
+	// BufferSize is the amount of pending asynchronous deliveries, publishers block once it is full.
+	BufferSize int
+	// Workers is the amount of goroutines delivering the asynchronous events.
+	Workers int
+}
+
+func DefaultBusConfig() *BusConfig {
+	return &BusConfig{
+		BufferSize: 1024,
+		Workers:    4,
+	}
+}
+
+func NewBusConfig(opts ...opt.Option[BusConfig]) *BusConfig {
+	config := DefaultBusConfig()
+	opt.Apply(config, opts...)
+	return config
+}
+
+func BufferSize(size int) opt.Option[BusConfig] {
+	return func(opt *BusConfig) {
+		opt.BufferSize = size
+	}
+}
+
+func Workers(workers int) opt.Option[BusConfig] {
+	return func(opt *BusConfig) {
+		opt.Workers = workers
+	}
+}
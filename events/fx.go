@@ -0,0 +1,25 @@
+package events
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/assert"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// Handlers registers event handler providers, they must only return an *events.Handler.
+func Handlers(handlerProviders ...any) opt.Option[app.Module] {
+	for _, handlerProvider := range handlerProviders {
+		if err := assert.FuncHasReturn[*Handler](handlerProvider); err != nil {
+			panic(errors.Wrapf(err, "events.Handlers only accepts function of any arity, which must only return *events.Handler"))
+		}
+	}
+	return func(opt *app.Module) {
+		opt.Public = append(opt.Public, slices.Map(handlerProviders, func(in any) any {
+			return fx.Annotate(in, fx.ResultTags(`group:"events.handler"`))
+		})...)
+	}
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+type (
+	// Handler reacts to the events of a single Go type, it is registered through events.Handlers.
+	Handler struct {
+		Name      string
+		EventType reflect.Type
+		// Async handlers are delivered from the bus buffer, they never block nor fail the publisher.
+		Async  bool
+		handle func(ctx context.Context, event any) error
+	}
+)
+
+// NewHandler creates a Handler for the events of type E, when E is an interface, every event implementing it is delivered.
+func NewHandler[E any](name string, handle func(ctx context.Context, event E) error, opts ...opt.Option[Handler]) *Handler {
+	handler := &Handler{
+		Name:      name,
+		EventType: reflect.TypeFor[E](),
+		handle: func(ctx context.Context, event any) error {
+			return handle(ctx, event.(E))
+		},
+	}
+	opt.Apply(handler, opts...)
+	return handler
+}
+
+// Async delivers the events to the handler from the bus buffer, instead of the publisher goroutine.
+func Async() opt.Option[Handler] {
+	return func(opt *Handler) {
+		opt.Async = true
+	}
+}
+
+func (h *Handler) accepts(eventType reflect.Type) bool {
+	if h.EventType.Kind() == reflect.Interface {
+		return eventType.Implements(h.EventType)
+	}
+	return eventType == h.EventType
+}
+
+// deliver isolates the handler, a panic is turned into an error instead of crashing the publisher or the bus.
+func (h *Handler) deliver(ctx context.Context, event any) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.Newf("handler %s panicked: %v", h.Name, recovered)
+		}
+	}()
+	if err = h.handle(ctx, event); err != nil {
+		return errors.Wrapf(err, "handler %s failed", h.Name)
+	}
+	return nil
+}
+
+func (h *Handler) String() string {
+	return fmt.Sprintf("%s(%s)", h.Name, h.EventType)
+}
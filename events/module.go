@@ -0,0 +1,14 @@
+package events
+
+import (
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/lifecycle"
+	"github.com/kiwiworks/rodent/app/module"
+)
+
+func Module() app.Module {
+	return app.NewModule(
+		module.Public(NewBus),
+		module.Service[Bus](module.InPhase(lifecycle.PhaseInfrastructure)),
+	)
+}
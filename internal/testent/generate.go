@@ -0,0 +1,4 @@
+// Package testent is an ent client generated from the schemas of the tests, backed by an in-memory SQLite.
+package testent
+
+//go:generate go run -mod=mod entgo.io/ent/cmd/ent generate --feature intercept,sql/execquery ./schema
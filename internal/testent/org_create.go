@@ -0,0 +1,215 @@
+// Code generated by ent, DO NOT EDIT.
+
+package testent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/schema/field"
+	"github.com/google/uuid"
+	"github.com/kiwiworks/rodent/internal/testent/org"
+)
+
+// OrgCreate is the builder for creating a Org entity.
+type OrgCreate struct {
+	config
+	mutation *OrgMutation
+	hooks    []Hook
+}
+
+// SetPublicID sets the "public_id" field.
+func (oc *OrgCreate) SetPublicID(u uuid.UUID) *OrgCreate {
+	oc.mutation.SetPublicID(u)
+	return oc
+}
+
+// SetNillablePublicID sets the "public_id" field if the given value is not nil.
+func (oc *OrgCreate) SetNillablePublicID(u *uuid.UUID) *OrgCreate {
+	if u != nil {
+		oc.SetPublicID(*u)
+	}
+	return oc
+}
+
+// SetName sets the "name" field.
+func (oc *OrgCreate) SetName(s string) *OrgCreate {
+	oc.mutation.SetName(s)
+	return oc
+}
+
+// Mutation returns the OrgMutation object of the builder.
+func (oc *OrgCreate) Mutation() *OrgMutation {
+	return oc.mutation
+}
+
+// Save creates the Org in the database.
+func (oc *OrgCreate) Save(ctx context.Context) (*Org, error) {
+	oc.defaults()
+	return withHooks(ctx, oc.sqlSave, oc.mutation, oc.hooks)
+}
+
+// SaveX calls Save and panics if Save returns an error.
+func (oc *OrgCreate) SaveX(ctx context.Context) *Org {
+	v, err := oc.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Exec executes the query.
+func (oc *OrgCreate) Exec(ctx context.Context) error {
+	_, err := oc.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (oc *OrgCreate) ExecX(ctx context.Context) {
+	if err := oc.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// defaults sets the default values of the builder before save.
+func (oc *OrgCreate) defaults() {
+	if _, ok := oc.mutation.PublicID(); !ok {
+		v := org.DefaultPublicID()
+		oc.mutation.SetPublicID(v)
+	}
+}
+
+// check runs all checks and user-defined validators on the builder.
+func (oc *OrgCreate) check() error {
+	if _, ok := oc.mutation.PublicID(); !ok {
+		return &ValidationError{Name: "public_id", err: errors.New(`testent: missing required field "Org.public_id"`)}
+	}
+	if _, ok := oc.mutation.Name(); !ok {
+		return &ValidationError{Name: "name", err: errors.New(`testent: missing required field "Org.name"`)}
+	}
+	return nil
+}
+
+func (oc *OrgCreate) sqlSave(ctx context.Context) (*Org, error) {
+	if err := oc.check(); err != nil {
+		return nil, err
+	}
+	_node, _spec := oc.createSpec()
+	if err := sqlgraph.CreateNode(ctx, oc.driver, _spec); err != nil {
+		if sqlgraph.IsConstraintError(err) {
+			err = &ConstraintError{msg: err.Error(), wrap: err}
+		}
+		return nil, err
+	}
+	id := _spec.ID.Value.(int64)
+	_node.ID = int(id)
+	oc.mutation.id = &_node.ID
+	oc.mutation.done = true
+	return _node, nil
+}
+
+func (oc *OrgCreate) createSpec() (*Org, *sqlgraph.CreateSpec) {
+	var (
+		_node = &Org{config: oc.config}
+		_spec = sqlgraph.NewCreateSpec(org.Table, sqlgraph.NewFieldSpec(org.FieldID, field.TypeInt))
+	)
+	if value, ok := oc.mutation.PublicID(); ok {
+		_spec.SetField(org.FieldPublicID, field.TypeUUID, value)
+		_node.PublicID = value
+	}
+	if value, ok := oc.mutation.Name(); ok {
+		_spec.SetField(org.FieldName, field.TypeString, value)
+		_node.Name = value
+	}
+	return _node, _spec
+}
+
+// OrgCreateBulk is the builder for creating many Org entities in bulk.
+type OrgCreateBulk struct {
+	config
+	err      error
+	builders []*OrgCreate
+}
+
+// Save creates the Org entities in the database.
+func (ocb *OrgCreateBulk) Save(ctx context.Context) ([]*Org, error) {
+	if ocb.err != nil {
+		return nil, ocb.err
+	}
+	specs := make([]*sqlgraph.CreateSpec, len(ocb.builders))
+	nodes := make([]*Org, len(ocb.builders))
+	mutators := make([]Mutator, len(ocb.builders))
+	for i := range ocb.builders {
+		func(i int, root context.Context) {
+			builder := ocb.builders[i]
+			builder.defaults()
+			var mut Mutator = MutateFunc(func(ctx context.Context, m Mutation) (Value, error) {
+				mutation, ok := m.(*OrgMutation)
+				if !ok {
+					return nil, fmt.Errorf("unexpected mutation type %T", m)
+				}
+				if err := builder.check(); err != nil {
+					return nil, err
+				}
+				builder.mutation = mutation
+				var err error
+				nodes[i], specs[i] = builder.createSpec()
+				if i < len(mutators)-1 {
+					_, err = mutators[i+1].Mutate(root, ocb.builders[i+1].mutation)
+				} else {
+					spec := &sqlgraph.BatchCreateSpec{Nodes: specs}
+					// Invoke the actual operation on the latest mutation in the chain.
+					if err = sqlgraph.BatchCreate(ctx, ocb.driver, spec); err != nil {
+						if sqlgraph.IsConstraintError(err) {
+							err = &ConstraintError{msg: err.Error(), wrap: err}
+						}
+					}
+				}
+				if err != nil {
+					return nil, err
+				}
+				mutation.id = &nodes[i].ID
+				if specs[i].ID.Value != nil {
+					id := specs[i].ID.Value.(int64)
+					nodes[i].ID = int(id)
+				}
+				mutation.done = true
+				return nodes[i], nil
+			})
+			for i := len(builder.hooks) - 1; i >= 0; i-- {
+				mut = builder.hooks[i](mut)
+			}
+			mutators[i] = mut
+		}(i, ctx)
+	}
+	if len(mutators) > 0 {
+		if _, err := mutators[0].Mutate(ctx, ocb.builders[0].mutation); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// SaveX is like Save, but panics if an error occurs.
+func (ocb *OrgCreateBulk) SaveX(ctx context.Context) []*Org {
+	v, err := ocb.Save(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Exec executes the query.
+func (ocb *OrgCreateBulk) Exec(ctx context.Context) error {
+	_, err := ocb.Save(ctx)
+	return err
+}
+
+// ExecX is like Exec, but panics if an error occurs.
+func (ocb *OrgCreateBulk) ExecX(ctx context.Context) {
+	if err := ocb.Exec(ctx); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,107 @@
+// Code generated by ent, DO NOT EDIT.
+
+package runtime
+
+import (
+	"github.com/google/uuid"
+	"github.com/kiwiworks/rodent/internal/testent/account"
+	"github.com/kiwiworks/rodent/internal/testent/document"
+	"github.com/kiwiworks/rodent/internal/testent/invoice"
+	"github.com/kiwiworks/rodent/internal/testent/org"
+	"github.com/kiwiworks/rodent/internal/testent/post"
+	"github.com/kiwiworks/rodent/internal/testent/project"
+	"github.com/kiwiworks/rodent/internal/testent/schema"
+	"github.com/kiwiworks/rodent/internal/testent/user"
+)
+
+// The init function reads all schema descriptors with runtime code
+// (default values, validators, hooks and policies) and stitches it
+// to their package variables.
+func init() {
+	accountMixin := schema.Account{}.Mixin()
+	accountMixinHooks0 := accountMixin[0].Hooks()
+	accountMixinHooks1 := accountMixin[1].Hooks()
+	account.Hooks[0] = accountMixinHooks0[0]
+	account.Hooks[1] = accountMixinHooks1[0]
+	documentMixin := schema.Document{}.Mixin()
+	documentMixinHooks0 := documentMixin[0].Hooks()
+	document.Hooks[0] = documentMixinHooks0[0]
+	documentMixinFields0 := documentMixin[0].Fields()
+	_ = documentMixinFields0
+	documentFields := schema.Document{}.Fields()
+	_ = documentFields
+	// documentDescVersion is the schema descriptor for version field.
+	documentDescVersion := documentMixinFields0[0].Descriptor()
+	// document.DefaultVersion holds the default value on creation for the version field.
+	document.DefaultVersion = documentDescVersion.Default.(int)
+	// document.VersionValidator is a validator for the "version" field. It is called by the builders before save.
+	document.VersionValidator = documentDescVersion.Validators[0].(func(int) error)
+	invoiceMixin := schema.Invoice{}.Mixin()
+	invoiceMixinHooks0 := invoiceMixin[0].Hooks()
+	invoiceMixinHooks1 := invoiceMixin[1].Hooks()
+	invoice.Hooks[0] = invoiceMixinHooks0[0]
+	invoice.Hooks[1] = invoiceMixinHooks1[0]
+	invoiceMixinInters1 := invoiceMixin[1].Interceptors()
+	invoice.Interceptors[0] = invoiceMixinInters1[0]
+	orgMixin := schema.Org{}.Mixin()
+	orgMixinFields0 := orgMixin[0].Fields()
+	_ = orgMixinFields0
+	orgFields := schema.Org{}.Fields()
+	_ = orgFields
+	// orgDescPublicID is the schema descriptor for public_id field.
+	orgDescPublicID := orgMixinFields0[0].Descriptor()
+	// org.DefaultPublicID holds the default value on creation for the public_id field.
+	org.DefaultPublicID = orgDescPublicID.Default.(func() uuid.UUID)
+	postMixin := schema.Post{}.Mixin()
+	postMixinHooks0 := postMixin[0].Hooks()
+	post.Hooks[0] = postMixinHooks0[0]
+	postMixinInters0 := postMixin[0].Interceptors()
+	post.Interceptors[0] = postMixinInters0[0]
+	projectMixin := schema.Project{}.Mixin()
+	projectMixinHooks0 := projectMixin[0].Hooks()
+	project.Hooks[0] = projectMixinHooks0[0]
+	projectMixinInters0 := projectMixin[0].Interceptors()
+	project.Interceptors[0] = projectMixinInters0[0]
+	projectMixinFields0 := projectMixin[0].Fields()
+	_ = projectMixinFields0
+	projectFields := schema.Project{}.Fields()
+	_ = projectFields
+	// projectDescTenantID is the schema descriptor for tenant_id field.
+	projectDescTenantID := projectMixinFields0[0].Descriptor()
+	// project.TenantIDValidator is a validator for the "tenant_id" field. It is called by the builders before save.
+	project.TenantIDValidator = projectDescTenantID.Validators[0].(func(string) error)
+	userMixin := schema.User{}.Mixin()
+	userMixinHooks1 := userMixin[1].Hooks()
+	userMixinHooks2 := userMixin[2].Hooks()
+	user.Hooks[0] = userMixinHooks1[0]
+	user.Hooks[1] = userMixinHooks2[0]
+	userMixinInters1 := userMixin[1].Interceptors()
+	user.Interceptors[0] = userMixinInters1[0]
+	userMixinFields0 := userMixin[0].Fields()
+	_ = userMixinFields0
+	userMixinFields2 := userMixin[2].Fields()
+	_ = userMixinFields2
+	userFields := schema.User{}.Fields()
+	_ = userFields
+	// userDescPublicID is the schema descriptor for public_id field.
+	userDescPublicID := userMixinFields0[0].Descriptor()
+	// user.DefaultPublicID holds the default value on creation for the public_id field.
+	user.DefaultPublicID = userDescPublicID.Default.(func() string)
+	// user.PublicIDValidator is a validator for the "public_id" field. It is called by the builders before save.
+	user.PublicIDValidator = userDescPublicID.Validators[0].(func(string) error)
+	// userDescVersion is the schema descriptor for version field.
+	userDescVersion := userMixinFields2[0].Descriptor()
+	// user.DefaultVersion holds the default value on creation for the version field.
+	user.DefaultVersion = userDescVersion.Default.(int)
+	// user.VersionValidator is a validator for the "version" field. It is called by the builders before save.
+	user.VersionValidator = userDescVersion.Validators[0].(func(int) error)
+	// userDescEmail is the schema descriptor for email field.
+	userDescEmail := userFields[0].Descriptor()
+	// user.EmailValidator is a validator for the "email" field. It is called by the builders before save.
+	user.EmailValidator = userDescEmail.Validators[0].(func(string) error)
+}
+
+const (
+	Version = "v0.14.3"                                         // Version of ent codegen.
+	Sum     = "h1:wokAV/kIlH9TeklJWGGS7AYJdVckr0DloWjIcO9iIIQ=" // Sum of ent codegen.
+)
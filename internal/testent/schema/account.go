@@ -0,0 +1,28 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+)
+
+// Account is an Audited schema, with an AuditHistory.
+type Account struct {
+	ent.Schema
+}
+
+func (Account) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		mixins.Audited{},
+		mixins.AuditHistory{},
+	}
+}
+
+func (Account) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("email"),
+		field.String("name").
+			Optional(),
+	}
+}
@@ -0,0 +1,25 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+)
+
+// Document is a Versioned schema.
+type Document struct {
+	ent.Schema
+}
+
+func (Document) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		mixins.Versioned{},
+	}
+}
+
+func (Document) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("title"),
+	}
+}
@@ -0,0 +1,26 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+)
+
+// Invoice is a SoftDeletable schema with an AuditHistory, the history hook runs around the soft-deletion.
+type Invoice struct {
+	ent.Schema
+}
+
+func (Invoice) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		mixins.AuditHistory{},
+		mixins.SoftDeletable{},
+	}
+}
+
+func (Invoice) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("number"),
+	}
+}
@@ -0,0 +1,25 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+)
+
+// Org is a Resource with sortable UUID public IDs.
+type Org struct {
+	ent.Schema
+}
+
+func (Org) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		mixins.Resource{IDs: mixins.SortableUUID},
+	}
+}
+
+func (Org) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name"),
+	}
+}
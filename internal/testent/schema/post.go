@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+)
+
+// Post is a SoftDeletable schema.
+type Post struct {
+	ent.Schema
+}
+
+func (Post) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		mixins.SoftDeletable{},
+	}
+}
+
+func (Post) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("title"),
+	}
+}
+
+func (Post) Indexes() []ent.Index {
+	return []ent.Index{
+		mixins.UniqueWhenNotDeleted("title"),
+	}
+}
@@ -0,0 +1,25 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+)
+
+// Project is a Tenant schema.
+type Project struct {
+	ent.Schema
+}
+
+func (Project) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		mixins.Tenant{},
+	}
+}
+
+func (Project) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name"),
+	}
+}
@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+)
+
+// User is a SoftDeletable and Versioned Resource, with prefixed public IDs.
+type User struct {
+	ent.Schema
+}
+
+func (User) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		mixins.Resource{IDs: mixins.PrefixedID, Prefix: "usr"},
+		mixins.SoftDeletable{},
+		mixins.Versioned{},
+	}
+}
+
+func (User) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("email").
+			NotEmpty(),
+		field.String("password").
+			Sensitive().
+			Optional(),
+		field.String("nickname").
+			Optional().
+			Nillable(),
+		field.Enum("role").
+			Values("admin", "member").
+			Default("member"),
+	}
+}
+
+func (User) Indexes() []ent.Index {
+	return []ent.Index{
+		mixins.UniqueWhenNotDeleted("email"),
+	}
+}
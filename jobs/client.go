@@ -0,0 +1,436 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+type (
+	// Client enqueues jobs, and runs the workers of every queue which has handlers.
+	Client struct {
+		db       *pg.Database
+		config   Config
+		handlers map[string]*Handler
+		kinds    map[reflect.Type]*Handler
+		mu       sync.Mutex
+		stopping chan struct{}
+		cancel   context.CancelFunc
+		workers  sync.WaitGroup
+	}
+	ClientParams struct {
+		fx.In
+		Database *pg.Database
+		Config   *Config    `optional:"true"`
+		Handlers []*Handler `group:"jobs.handler"`
+	}
+	EnqueueOptions struct {
+		Queue       string
+		RunAt       time.Time
+		MaxAttempts int
+		UniqueKey   string
+	}
+)
+
+func NewClient(params ClientParams) (*Client, error) {
+	if params.Config == nil {
+		params.Config = DefaultConfig()
+	}
+	c := &Client{
+		db:       params.Database,
+		config:   *params.Config,
+		handlers: make(map[string]*Handler, len(params.Handlers)),
+		kinds:    make(map[reflect.Type]*Handler, len(params.Handlers)),
+	}
+	for _, handler := range params.Handlers {
+		if _, exists := c.handlers[handler.Kind]; exists {
+			return nil, errors.Newf("job kind %s has more than one handler", handler.Kind)
+		}
+		if existing, exists := c.kinds[handler.PayloadType]; exists {
+			return nil, errors.Newf(
+				"jobs %s and %s share the payload type %s, which makes enqueuing them ambiguous",
+				existing.Kind, handler.Kind, handler.PayloadType,
+			)
+		}
+		c.handlers[handler.Kind] = handler
+		c.kinds[handler.PayloadType] = handler
+	}
+	return c, nil
+}
+
+// ToQueue enqueues the job on the given queue, instead of the queue of its handler.
+func ToQueue(queue string) opt.Option[EnqueueOptions] {
+	return func(opt *EnqueueOptions) {
+		opt.Queue = queue
+	}
+}
+
+// RunAt schedules the job, it is not run before the given time.
+func RunAt(at time.Time) opt.Option[EnqueueOptions] {
+	return func(opt *EnqueueOptions) {
+		opt.RunAt = at
+	}
+}
+
+// RunIn schedules the job, it is not run before the given delay elapsed.
+func RunIn(delay time.Duration) opt.Option[EnqueueOptions] {
+	return func(opt *EnqueueOptions) {
+		opt.RunAt = time.Now().Add(delay)
+	}
+}
+
+// Unique deduplicates the job, it is not enqueued while another job with the same key is pending or running.
+func Unique(key string) opt.Option[EnqueueOptions] {
+	return func(opt *EnqueueOptions) {
+		opt.UniqueKey = key
+	}
+}
+
+func WithMaxAttempts(attempts int) opt.Option[EnqueueOptions] {
+	return func(opt *EnqueueOptions) {
+		opt.MaxAttempts = attempts
+	}
+}
+
+// Enqueue schedules the job handling the payload type, it joins the transaction of the context, see pg.WithTx,
+// so that the job is only enqueued if the transaction commits.
+// It returns the id of the job, or the one of the pending job with the same unique key.
+func (c *Client) Enqueue(ctx context.Context, payload any, opts ...opt.Option[EnqueueOptions]) (int64, error) {
+	handler, exists := c.kinds[reflect.TypeOf(payload)]
+	if !exists {
+		return 0, errors.Newf("no job handler is registered for the payload type %T", payload)
+	}
+	return c.EnqueueKind(ctx, handler.Kind, payload, opts...)
+}
+
+// EnqueueKind schedules a job by kind, it allows enqueuing jobs whose handler runs in another application.
+func (c *Client) EnqueueKind(ctx context.Context, kind string, payload any, opts ...opt.Option[EnqueueOptions]) (int64, error) {
+	options := EnqueueOptions{
+		Queue:       DefaultQueue,
+		MaxAttempts: c.config.MaxAttempts,
+	}
+	if handler, exists := c.handlers[kind]; exists {
+		options.Queue = handler.Queue
+		if handler.MaxAttempts > 0 {
+			options.MaxAttempts = handler.MaxAttempts
+		}
+	}
+	opt.Apply(&options, opts...)
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to encode the payload of job %s", kind)
+	}
+	runAt := sql.NullTime{Time: options.RunAt, Valid: !options.RunAt.IsZero()}
+
+	var id int64
+	err = c.db.Executor(ctx).QueryRowContext(ctx, `
+		INSERT INTO rodent_jobs (queue, kind, payload, max_attempts, run_at, unique_key)
+		VALUES ($1, $2, $3, $4, COALESCE($5, now()), NULLIF($6, ''))
+		ON CONFLICT (unique_key) WHERE state IN ('pending', 'running')
+		DO UPDATE SET unique_key = EXCLUDED.unique_key
+		RETURNING id`,
+		options.Queue, kind, raw, max(options.MaxAttempts, 1), runAt, options.UniqueKey,
+	).Scan(&id)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to enqueue job %s", kind)
+	}
+	return id, nil
+}
+
+// Retry puts a dead-lettered job back in its queue, with a fresh attempts budget.
+func (c *Client) Retry(ctx context.Context, id int64) error {
+	result, err := c.db.DB().ExecContext(ctx, `
+		UPDATE rodent_jobs
+		SET state = 'pending', attempts = 0, run_at = now(), finished_at = NULL, updated_at = now()
+		WHERE id = $1 AND state = 'dead'`,
+		id,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retry job %d", id)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return errors.Newf("job %d is not dead-lettered", id)
+	}
+	return nil
+}
+
+// queues returns the kinds handled on each queue.
+func (c *Client) queues() map[string][]string {
+	queues := make(map[string][]string)
+	for kind, handler := range c.handlers {
+		queues[handler.Queue] = append(queues[handler.Queue], kind)
+	}
+	for _, kinds := range queues {
+		sort.Strings(kinds)
+	}
+	return queues
+}
+
+func (c *Client) OnStart(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c.mu.Lock()
+	c.stopping = make(chan struct{})
+	c.cancel = cancel
+	stopping := c.stopping
+	c.mu.Unlock()
+
+	for queue, kinds := range c.queues() {
+		concurrency := c.config.concurrency(queue)
+		log.Info("starting job workers",
+			props.JobQueue(queue),
+			zap.Int("job.queue.concurrency", concurrency),
+			zap.Strings("job.kinds", kinds),
+		)
+		for range concurrency {
+			c.workers.Add(1)
+			go c.work(runCtx, stopping, queue, kinds)
+		}
+	}
+	if len(c.handlers) > 0 {
+		c.workers.Add(1)
+		go c.rescueLoop(runCtx, stopping)
+	}
+	return nil
+}
+
+// OnStop stops fetching jobs and waits for the running ones, they are interrupted and retried later
+// if the context expires first.
+func (c *Client) OnStop(ctx context.Context) error {
+	c.mu.Lock()
+	stopping, cancel := c.stopping, c.cancel
+	c.mu.Unlock()
+	if stopping == nil {
+		return nil
+	}
+	close(stopping)
+
+	drained := make(chan struct{})
+	go func() {
+		c.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		cancel()
+		return nil
+	case <-ctx.Done():
+		cancel()
+		logger.FromContext(ctx).Warn("interrupting running jobs, they will be retried")
+		return errors.Wrapf(ctx.Err(), "failed to drain the job workers")
+	}
+}
+
+func (c *Client) work(ctx context.Context, stopping <-chan struct{}, queue string, kinds []string) {
+	defer c.workers.Done()
+	log := logger.FromContext(ctx).With(props.JobQueue(queue))
+	for {
+		select {
+		case <-stopping:
+			return
+		default:
+		}
+		job, err := c.fetch(ctx, queue, kinds)
+		if err != nil {
+			log.Error("failed to fetch job", zap.Error(err))
+		}
+		if job != nil {
+			c.execute(ctx, job)
+			continue
+		}
+		select {
+		case <-stopping:
+			return
+		case <-time.After(c.config.PollInterval):
+		}
+	}
+}
+
+// fetch locks the next job of the queue which is due, skipping the ones locked by other workers.
+func (c *Client) fetch(ctx context.Context, queue string, kinds []string) (*Job, error) {
+	var (
+		job       Job
+		uniqueKey sql.NullString
+	)
+	err := c.db.DB().QueryRowContext(ctx, `
+		UPDATE rodent_jobs
+		SET state = 'running', attempts = attempts + 1, locked_at = now(), updated_at = now()
+		WHERE id = (
+			SELECT id FROM rodent_jobs
+			WHERE queue = $1 AND kind = ANY($2) AND state = 'pending' AND run_at <= now()
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, queue, kind, payload, attempts, max_attempts, run_at, unique_key, created_at, locked_at`,
+		queue, pq.Array(kinds),
+	).Scan(
+		&job.ID, &job.Queue, &job.Kind, &job.Payload, &job.Attempt, &job.MaxAttempts,
+		&job.RunAt, &uniqueKey, &job.CreatedAt, &job.lockedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	job.UniqueKey = uniqueKey.String
+	return &job, nil
+}
+
+func (c *Client) execute(ctx context.Context, job *Job) {
+	log := logger.FromContext(ctx).With(
+		props.JobID(job.ID),
+		props.JobKind(job.Kind),
+		props.JobQueue(job.Queue),
+		props.JobAttempt(job.Attempt),
+	)
+	startedAt := time.Now()
+	runCtx, cancel := context.WithCancel(ctx)
+	stopHeartbeat := c.heartbeat(runCtx, job, cancel)
+	err := c.handlers[job.Kind].run(runCtx, job)
+	stopHeartbeat()
+	cancel()
+	// the outcome is recorded even when the workers are interrupted, as long as the job was not rescued since
+	ctx = context.WithoutCancel(ctx)
+	var result sql.Result
+	if err == nil {
+		log.Info("job succeeded", zap.Duration("job.duration", time.Since(startedAt)))
+		result, err = c.db.DB().ExecContext(ctx, `
+			UPDATE rodent_jobs
+			SET state = 'succeeded', locked_at = NULL, finished_at = now(), updated_at = now()
+			WHERE id = $1 AND state = 'running' AND locked_at = $2`,
+			job.ID, job.lockedAt,
+		)
+		recorded(log, result, err)
+		return
+	}
+
+	if job.IsLastAttempt() {
+		log.Error("job failed, moving it to the dead-letter state", zap.Error(err))
+		result, err = c.db.DB().ExecContext(ctx, `
+			UPDATE rodent_jobs
+			SET state = 'dead', last_error = $3, locked_at = NULL, finished_at = now(), updated_at = now()
+			WHERE id = $1 AND state = 'running' AND locked_at = $2`,
+			job.ID, job.lockedAt, err.Error(),
+		)
+	} else {
+		delay := c.retryDelay(job)
+		log.Warn("job failed, retrying", zap.Error(err), zap.Duration("job.retry.backoff", delay))
+		result, err = c.db.DB().ExecContext(ctx, `
+			UPDATE rodent_jobs
+			SET state = 'pending', last_error = $3, run_at = $4, locked_at = NULL, updated_at = now()
+			WHERE id = $1 AND state = 'running' AND locked_at = $2`,
+			job.ID, job.lockedAt, err.Error(), time.Now().Add(delay),
+		)
+	}
+	recorded(log, result, err)
+}
+
+// recorded logs the outcome of a job which could not be recorded, because of an error or because the job was
+// rescued while it ran, in which case the outcome of the attempt running it since wins.
+func recorded(log *zap.Logger, result sql.Result, err error) {
+	if err != nil {
+		log.Error("failed to record the outcome of the job", zap.Error(err))
+		return
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		log.Warn("job was rescued while it ran, its outcome is discarded")
+	}
+}
+
+// heartbeat renews the lock of the running job every quarter of RescueAfter, so that it is not rescued while its
+// worker is alive. The job is cancelled when its lock is lost, as another worker runs it. Stop waits for the
+// heartbeat to be over, job.lockedAt is the lock to record the outcome with afterward.
+func (c *Client) heartbeat(ctx context.Context, job *Job, lost context.CancelFunc) (stop func()) {
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		log := logger.FromContext(ctx).With(props.JobID(job.ID), props.JobKind(job.Kind))
+		ticker := time.NewTicker(max(c.config.RescueAfter/4, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			var lockedAt time.Time
+			err := c.db.DB().QueryRowContext(ctx, `
+				UPDATE rodent_jobs SET locked_at = now(), updated_at = now()
+				WHERE id = $1 AND state = 'running' AND locked_at = $2
+				RETURNING locked_at`,
+				job.ID, job.lockedAt,
+			).Scan(&lockedAt)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				log.Warn("job was rescued while it ran, cancelling it")
+				lost()
+				return
+			case err != nil:
+				log.Error("failed to renew the lock of the job", zap.Error(err))
+			default:
+				job.lockedAt = lockedAt
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (c *Client) retryDelay(job *Job) time.Duration {
+	if handler := c.handlers[job.Kind]; handler.Retry != nil {
+		return handler.Retry(job.Attempt)
+	}
+	return c.config.Retry(job.Attempt)
+}
+
+// rescueLoop puts back in their queue the jobs abandoned by a worker which died while running them.
+func (c *Client) rescueLoop(ctx context.Context, stopping <-chan struct{}) {
+	defer c.workers.Done()
+	log := logger.FromContext(ctx)
+	interval := min(c.config.RescueAfter, time.Minute)
+	for {
+		rescued, err := c.rescue(ctx)
+		if err != nil {
+			log.Error("failed to rescue abandoned jobs", zap.Error(err))
+		} else if rescued > 0 {
+			log.Warn("rescued abandoned jobs", zap.Int64("job.rescued", rescued))
+		}
+		select {
+		case <-stopping:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// rescue puts back in their queue the jobs whose lock was not renewed for RescueAfter, it returns how many it rescued.
+func (c *Client) rescue(ctx context.Context) (int64, error) {
+	result, err := c.db.DB().ExecContext(ctx, `
+		UPDATE rodent_jobs
+		SET state = 'pending', locked_at = NULL, updated_at = now()
+		WHERE state = 'running' AND locked_at < $1`,
+		time.Now().Add(-c.config.RescueAfter),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
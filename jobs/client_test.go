@@ -0,0 +1,242 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/database/pg/pgtest"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+func testClient(t *testing.T, handlers ...*Handler) (*Client, *pg.Database) {
+	t.Helper()
+	return testClientWith(t, NewConfig(RetryBackoff(time.Minute, time.Minute, 1)), handlers...)
+}
+
+func testClientWith(t *testing.T, config *Config, handlers ...*Handler) (*Client, *pg.Database) {
+	t.Helper()
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, Schema)
+	client, err := NewClient(ClientParams{
+		Database: db,
+		Config:   config,
+		Handlers: handlers,
+	})
+	require.NoError(t, err)
+	return client, db
+}
+
+func jobState(t *testing.T, db *pg.Database, id int64) (state State, attempts int, runAt time.Time) {
+	t.Helper()
+	require.NoError(t, db.DB().QueryRowContext(context.Background(),
+		`SELECT state, attempts, run_at FROM rodent_jobs WHERE id = $1`, id,
+	).Scan(&state, &attempts, &runAt))
+	return state, attempts, runAt
+}
+
+func TestClientEnqueueUnique(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client, db := testClient(t, NewHandler("email", func(ctx context.Context, payload sendEmail) error {
+		return nil
+	}))
+
+	first, err := client.Enqueue(ctx, sendEmail{To: "ada@example.com"}, Unique("welcome:ada"))
+	r.NoError(err)
+	duplicate, err := client.Enqueue(ctx, sendEmail{To: "ada@example.com"}, Unique("welcome:ada"))
+	r.NoError(err)
+	r.Equal(first, duplicate)
+	other, err := client.Enqueue(ctx, sendEmail{To: "bob@example.com"}, Unique("welcome:bob"))
+	r.NoError(err)
+	r.NotEqual(first, other)
+
+	job, err := client.fetch(ctx, DefaultQueue, []string{"email"})
+	r.NoError(err)
+	r.Equal(first, job.ID)
+	client.execute(ctx, job)
+	state, _, _ := jobState(t, db, first)
+	r.Equal(StateSucceeded, state)
+
+	again, err := client.Enqueue(ctx, sendEmail{To: "ada@example.com"}, Unique("welcome:ada"))
+	r.NoError(err)
+	r.NotEqual(first, again)
+
+	_, err = client.Enqueue(ctx, resizeImage{})
+	r.ErrorContains(err, "no job handler is registered")
+}
+
+func TestClientFetch(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client, _ := testClient(t)
+
+	later, err := client.EnqueueKind(ctx, "email", sendEmail{}, RunIn(time.Hour))
+	r.NoError(err)
+	first, err := client.EnqueueKind(ctx, "email", sendEmail{})
+	r.NoError(err)
+	second, err := client.EnqueueKind(ctx, "email", sendEmail{})
+	r.NoError(err)
+	_, err = client.EnqueueKind(ctx, "resize", resizeImage{})
+	r.NoError(err)
+
+	// a job locked by another worker is skipped instead of waited for
+	tx, err := client.db.DB().BeginTx(ctx, nil)
+	r.NoError(err)
+	defer func() { _ = tx.Rollback() }()
+	_, err = tx.ExecContext(ctx, `SELECT id FROM rodent_jobs WHERE id = $1 FOR UPDATE`, first)
+	r.NoError(err)
+
+	job, err := client.fetch(ctx, DefaultQueue, []string{"email"})
+	r.NoError(err)
+	r.Equal(second, job.ID)
+	r.Equal(1, job.Attempt)
+	job, err = client.fetch(ctx, DefaultQueue, []string{"email"})
+	r.NoError(err)
+	r.Nil(job, "the locked job is skipped and job %d is not due", later)
+
+	r.NoError(tx.Rollback())
+	job, err = client.fetch(ctx, DefaultQueue, []string{"email"})
+	r.NoError(err)
+	r.Equal(first, job.ID)
+}
+
+func TestClientFailures(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client, db := testClient(t, NewHandler("email", func(ctx context.Context, payload sendEmail) error {
+		return errors.Newf("smtp unavailable")
+	}, Attempts(2)))
+
+	id, err := client.Enqueue(ctx, sendEmail{})
+	r.NoError(err)
+	job, err := client.fetch(ctx, DefaultQueue, []string{"email"})
+	r.NoError(err)
+	client.execute(ctx, job)
+	state, attempts, runAt := jobState(t, db, id)
+	r.Equal(StatePending, state)
+	r.Equal(1, attempts)
+	r.WithinDuration(time.Now().Add(time.Minute), runAt, time.Second*10)
+
+	_, err = db.DB().ExecContext(ctx, `UPDATE rodent_jobs SET run_at = now() WHERE id = $1`, id)
+	r.NoError(err)
+	job, err = client.fetch(ctx, DefaultQueue, []string{"email"})
+	r.NoError(err)
+	r.True(job.IsLastAttempt())
+	client.execute(ctx, job)
+	state, _, _ = jobState(t, db, id)
+	r.Equal(StateDead, state)
+
+	r.NoError(client.Retry(ctx, id))
+	state, attempts, _ = jobState(t, db, id)
+	r.Equal(StatePending, state)
+	r.Zero(attempts)
+	r.Error(client.Retry(ctx, id), "only dead jobs can be retried")
+}
+
+func TestClientRescue(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client, db := testClient(t)
+
+	abandoned, err := client.EnqueueKind(ctx, "email", sendEmail{})
+	r.NoError(err)
+	running, err := client.EnqueueKind(ctx, "email", sendEmail{})
+	r.NoError(err)
+	_, err = db.DB().ExecContext(ctx, `
+		UPDATE rodent_jobs SET state = 'running', locked_at = now() - $2::interval WHERE id = $1`,
+		abandoned, "2 hours",
+	)
+	r.NoError(err)
+	_, err = db.DB().ExecContext(ctx, `UPDATE rodent_jobs SET state = 'running', locked_at = now() WHERE id = $1`, running)
+	r.NoError(err)
+
+	rescued, err := client.rescue(ctx)
+	r.NoError(err)
+	r.EqualValues(1, rescued)
+	state, _, _ := jobState(t, db, abandoned)
+	r.Equal(StatePending, state)
+	state, _, _ = jobState(t, db, running)
+	r.Equal(StateRunning, state)
+}
+
+func TestClientEnqueueJoinsTransaction(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client, db := testClient(t)
+
+	err := db.WithTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+		if _, err := client.EnqueueKind(ctx, "email", sendEmail{}); err != nil {
+			return err
+		}
+		return errors.Newf("signup failed")
+	})
+	r.ErrorContains(err, "signup failed")
+	var count int
+	r.NoError(db.DB().QueryRowContext(ctx, `SELECT count(*) FROM rodent_jobs`).Scan(&count))
+	r.Zero(count, "the job is rolled back with the transaction")
+}
+
+func TestClientFencesRescuedJobs(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	client, db := testClient(t, NewHandler("email", func(ctx context.Context, payload sendEmail) error {
+		return nil
+	}))
+
+	id, err := client.Enqueue(ctx, sendEmail{})
+	r.NoError(err)
+	stale, err := client.fetch(ctx, DefaultQueue, []string{"email"})
+	r.NoError(err)
+	_, err = db.DB().ExecContext(ctx, `UPDATE rodent_jobs SET locked_at = now() - interval '2 hours' WHERE id = $1`, id)
+	r.NoError(err)
+	rescued, err := client.rescue(ctx)
+	r.NoError(err)
+	r.EqualValues(1, rescued)
+	current, err := client.fetch(ctx, DefaultQueue, []string{"email"})
+	r.NoError(err)
+	r.Equal(2, current.Attempt)
+
+	client.execute(ctx, stale)
+	state, attempts, _ := jobState(t, db, id)
+	r.Equal(StateRunning, state, "the outcome of the rescued attempt is discarded")
+	r.Equal(2, attempts)
+	client.execute(ctx, current)
+	state, _, _ = jobState(t, db, id)
+	r.Equal(StateSucceeded, state)
+}
+
+func TestClientHeartbeat(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	release := make(chan struct{})
+	client, db := testClientWith(t, NewConfig(RescueAfter(200*time.Millisecond)), NewHandler("email",
+		func(ctx context.Context, payload sendEmail) error {
+			<-release
+			return nil
+		},
+	))
+
+	id, err := client.Enqueue(ctx, sendEmail{})
+	r.NoError(err)
+	job, err := client.fetch(ctx, DefaultQueue, []string{"email"})
+	r.NoError(err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.execute(ctx, job)
+	}()
+
+	time.Sleep(500 * time.Millisecond)
+	rescued, err := client.rescue(ctx)
+	r.NoError(err)
+	r.Zero(rescued, "a job whose worker is alive outlives RescueAfter")
+	close(release)
+	<-done
+	state, _, _ := jobState(t, db, id)
+	r.Equal(StateSucceeded, state)
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// Config configures the workers of the Client.
+type Config struct {
+	// Queues is the worker concurrency of each queue, a queue with handlers but no entry gets a single worker.
+	Queues map[string]int
+	// PollInterval is how long an idle worker waits before looking for jobs again.
+	PollInterval time.Duration
+	// MaxAttempts is the default attempts budget of a job, before it is dead-lettered.
+	MaxAttempts int
+	// Retry computes the delay before each retry, a Handler can override it.
+	Retry RetryPolicy
+	// RescueAfter is how long the lock of a running job can go without being renewed before the job is considered
+	// abandoned by a dead worker, and put back in its queue. The workers renew the locks of their jobs every quarter
+	// of it, so that the jobs running for longer are not run twice.
+	RescueAfter time.Duration
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Queues:       map[string]int{},
+		PollInterval: time.Second,
+		MaxAttempts:  25,
+		Retry:        ExponentialBackoff(time.Second, time.Hour, 2),
+		RescueAfter:  time.Hour,
+	}
+}
+
+func NewConfig(opts ...opt.Option[Config]) *Config {
+	config := DefaultConfig()
+	opt.Apply(config, opts...)
+	return config
+}
+
+func (c *Config) concurrency(queue string) int {
+	if concurrency := c.Queues[queue]; concurrency > 0 {
+		return concurrency
+	}
+	return 1
+}
+
+// Queue sets the amount of workers running the jobs of a queue concurrently.
+func Queue(queue string, concurrency int) opt.Option[Config] {
+	return func(opt *Config) {
+		opt.Queues[queue] = concurrency
+	}
+}
+
+func PollInterval(interval time.Duration) opt.Option[Config] {
+	return func(opt *Config) {
+		opt.PollInterval = interval
+	}
+}
+
+func MaxAttempts(attempts int) opt.Option[Config] {
+	return func(opt *Config) {
+		opt.MaxAttempts = attempts
+	}
+}
+
+func RetryBackoff(initial, max time.Duration, multiplier float64) opt.Option[Config] {
+	return func(opt *Config) {
+		opt.Retry = ExponentialBackoff(initial, max, multiplier)
+	}
+}
+
+func RescueAfter(after time.Duration) opt.Option[Config] {
+	return func(opt *Config) {
+		opt.RescueAfter = after
+	}
+}
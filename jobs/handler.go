@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+type (
+	// Handler runs the jobs of a single kind, their payload is decoded from JSON into a Go type.
+	Handler struct {
+		Kind        string
+		Queue       string
+		PayloadType reflect.Type
+		// MaxAttempts overrides the default attempts budget of the Client, when positive.
+		MaxAttempts int
+		// Timeout bounds every attempt, when positive.
+		Timeout time.Duration
+		// Retry overrides the retry policy of the Client, when set.
+		Retry  RetryPolicy
+		handle func(ctx context.Context, payload json.RawMessage) error
+	}
+)
+
+// NewHandler creates the Handler of the jobs of the given kind, enqueuing a T through the Client schedules such a job.
+func NewHandler[T any](kind string, handle func(ctx context.Context, payload T) error, opts ...opt.Option[Handler]) *Handler {
+	handler := &Handler{
+		Kind:        kind,
+		Queue:       DefaultQueue,
+		PayloadType: reflect.TypeFor[T](),
+		handle: func(ctx context.Context, raw json.RawMessage) error {
+			var payload T
+			if err := json.Unmarshal(raw, &payload); err != nil {
+				return errors.Wrapf(err, "failed to decode the payload of job %s", kind)
+			}
+			return handle(ctx, payload)
+		},
+	}
+	opt.Apply(handler, opts...)
+	return handler
+}
+
+// OnQueue runs the jobs on the given queue, instead of the default one.
+func OnQueue(queue string) opt.Option[Handler] {
+	return func(opt *Handler) {
+		opt.Queue = queue
+	}
+}
+
+func Attempts(attempts int) opt.Option[Handler] {
+	return func(opt *Handler) {
+		opt.MaxAttempts = attempts
+	}
+}
+
+func Timeout(timeout time.Duration) opt.Option[Handler] {
+	return func(opt *Handler) {
+		opt.Timeout = timeout
+	}
+}
+
+// RetryWith computes the delay before each retry of the jobs with the given policy, instead of the one of the Client.
+func RetryWith(policy RetryPolicy) opt.Option[Handler] {
+	return func(opt *Handler) {
+		opt.Retry = policy
+	}
+}
+
+// run isolates the handler, a panic is turned into an error so that the job gets retried.
+func (h *Handler) run(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.Newf("job %s panicked: %v", h.Kind, recovered)
+		}
+	}()
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	return h.handle(withJob(ctx, job), job.Payload)
+}
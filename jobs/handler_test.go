@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	sendEmail struct {
+		To string `json:"to"`
+	}
+	resizeImage struct {
+		Path string `json:"path"`
+	}
+)
+
+func TestHandlerRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler *Handler
+		payload string
+		err     string
+	}{
+		{
+			name: "decodes the payload",
+			handler: NewHandler("email", func(ctx context.Context, payload sendEmail) error {
+				require.Equal(t, "ada@example.com", payload.To)
+				require.Equal(t, int64(7), FromContext(ctx).ID)
+				return nil
+			}),
+			payload: `{"to":"ada@example.com"}`,
+		},
+		{
+			name: "invalid payload",
+			handler: NewHandler("email", func(ctx context.Context, payload sendEmail) error {
+				return nil
+			}),
+			payload: `[]`,
+			err:     "failed to decode the payload of job email",
+		},
+		{
+			name: "panic",
+			handler: NewHandler("email", func(ctx context.Context, payload sendEmail) error {
+				panic("boom")
+			}),
+			payload: `{}`,
+			err:     "job email panicked: boom",
+		},
+		{
+			name: "timeout",
+			handler: NewHandler("email", func(ctx context.Context, payload sendEmail) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}, Timeout(time.Millisecond)),
+			payload: `{}`,
+			err:     context.DeadlineExceeded.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.handler.run(context.Background(), &Job{ID: 7, Payload: json.RawMessage(tt.payload)})
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	noop := func(context.Context, sendEmail) error { return nil }
+	tests := []struct {
+		name     string
+		handlers []*Handler
+		queues   map[string][]string
+		err      string
+	}{
+		{
+			name: "groups kinds by queue",
+			handlers: []*Handler{
+				NewHandler("email", noop),
+				NewHandler("resize", func(context.Context, resizeImage) error { return nil }, OnQueue("media")),
+			},
+			queues: map[string][]string{DefaultQueue: {"email"}, "media": {"resize"}},
+		},
+		{
+			name:     "duplicate kind",
+			handlers: []*Handler{NewHandler("email", noop), NewHandler("email", noop)},
+			err:      "job kind email has more than one handler",
+		},
+		{
+			name:     "duplicate payload type",
+			handlers: []*Handler{NewHandler("email", noop), NewHandler("newsletter", noop)},
+			err:      "jobs email and newsletter share the payload type jobs.sendEmail",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(ClientParams{Handlers: tt.handlers})
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.queues, client.queues())
+		})
+	}
+}
+
+func TestConfigConcurrency(t *testing.T) {
+	config := NewConfig(Queue("media", 4))
+	require.Equal(t, 4, config.concurrency("media"))
+	require.Equal(t, 1, config.concurrency(DefaultQueue))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	policy := ExponentialBackoff(time.Second, time.Second*5, 2)
+	tests := []struct {
+		attempt int
+		delay   time.Duration
+	}{
+		{attempt: 0, delay: time.Second},
+		{attempt: 1, delay: time.Second},
+		{attempt: 2, delay: time.Second * 2},
+		{attempt: 3, delay: time.Second * 4},
+		{attempt: 4, delay: time.Second * 5},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.delay, policy(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
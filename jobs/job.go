@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type State string
+
+const (
+	// StatePending jobs wait for their run-at time, including the failed ones waiting for a retry.
+	StatePending State = "pending"
+	// StateRunning jobs are locked by a worker.
+	StateRunning State = "running"
+	// StateSucceeded jobs completed successfully.
+	StateSucceeded State = "succeeded"
+	// StateDead jobs exhausted their attempts, they are kept as a dead-letter until they are retried manually.
+	StateDead State = "dead"
+)
+
+const DefaultQueue = "default"
+
+type (
+	// Job is a row of the jobs table, as seen by the handler running it.
+	Job struct {
+		ID          int64
+		Queue       string
+		Kind        string
+		Payload     json.RawMessage
+		Attempt     int
+		MaxAttempts int
+		RunAt       time.Time
+		UniqueKey   string
+		CreatedAt   time.Time
+		// lockedAt fences the updates of the worker running the job, it is renewed by its heartbeat.
+		lockedAt time.Time
+	}
+	jobKey struct{}
+)
+
+func withJob(ctx context.Context, job *Job) context.Context {
+	return context.WithValue(ctx, jobKey{}, job)
+}
+
+// FromContext returns the job being run, it is nil outside a job handler.
+func FromContext(ctx context.Context) *Job {
+	if job, ok := ctx.Value(jobKey{}).(*Job); ok {
+		return job
+	}
+	return nil
+}
+
+// IsLastAttempt reports whether the job is dead-lettered if this attempt fails.
+func (j *Job) IsLastAttempt() bool {
+	return j.Attempt >= j.MaxAttempts
+}
@@ -0,0 +1,17 @@
+package jobs
+
+import (
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/module"
+	"github.com/kiwiworks/rodent/database/migration"
+)
+
+// Module provides the jobs Client, it relies on pg.Module for the database,
+// and on migration.Module to create the jobs table.
+func Module() app.Module {
+	return app.NewModule(
+		module.Public(NewClient),
+		migration.Migrations(schemaMigration),
+		module.Service[Client](),
+	)
+}
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"math"
+	"time"
+)
+
+// RetryPolicy computes how long a failed job waits before its next attempt, attempt is the one which just failed.
+type RetryPolicy func(attempt int) time.Duration
+
+// ExponentialBackoff waits initial after the first failure, and multiplies the delay after every other one, up to max.
+func ExponentialBackoff(initial, max time.Duration, multiplier float64) RetryPolicy {
+	return func(attempt int) time.Duration {
+		if attempt < 1 {
+			attempt = 1
+		}
+		delay := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+		if max > 0 && delay > float64(max) {
+			return max
+		}
+		return time.Duration(delay)
+	}
+}
@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiwiworks/rodent/database/migration"
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// Schema creates the jobs table, a partial unique index deduplicates the jobs which are not completed yet.
+const Schema = `
+CREATE TABLE IF NOT EXISTS rodent_jobs (
+	id           BIGSERIAL PRIMARY KEY,
+	queue        TEXT        NOT NULL,
+	kind         TEXT        NOT NULL,
+	payload      JSONB       NOT NULL,
+	state        TEXT        NOT NULL DEFAULT 'pending',
+	attempts     INTEGER     NOT NULL DEFAULT 0,
+	max_attempts INTEGER     NOT NULL,
+	run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+	unique_key   TEXT,
+	last_error   TEXT,
+	locked_at    TIMESTAMPTZ,
+	finished_at  TIMESTAMPTZ,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS rodent_jobs_fetch ON rodent_jobs (queue, run_at, id) WHERE state = 'pending';
+CREATE UNIQUE INDEX IF NOT EXISTS rodent_jobs_unique_key ON rodent_jobs (unique_key) WHERE state IN ('pending', 'running');
+`
+
+// schemaMigration creates the jobs table through the migration.Migrator.
+func schemaMigration(db *pg.Database) *migration.Migration {
+	return migration.New(
+		"rodent_jobs",
+		time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, Schema); err != nil {
+				return errors.Wrapf(err, "failed to create the jobs table")
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, `DROP TABLE IF EXISTS rodent_jobs`); err != nil {
+				return errors.Wrapf(err, "failed to drop the jobs table")
+			}
+			return nil
+		},
+		migration.ChecksumOf(Schema),
+		migration.AnyOrder(),
+	)
+}
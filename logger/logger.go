@@ -21,10 +21,38 @@ var (
 	logLevel zap.AtomicLevel
 )
 
+type coreKey struct{}
+
 func SetLevel(level Level) {
 	logLevel.SetLevel(level)
 }
 
+// GetLevel returns the level currently used by every logger.
+func GetLevel() Level {
+	return logLevel.Level()
+}
+
+// ParseLevel parses a level name, such as `debug` or `warn`.
+func ParseLevel(level string) (Level, error) {
+	return zapcore.ParseLevel(level)
+}
+
+// WithCore makes the loggers created from the context, see FromContext, write to core instead of the configured
+// output. It only captures the logs of what runs with the context, so that several applications of one process,
+// such as test harnesses, log apart.
+func WithCore(ctx context.Context, core zapcore.Core) context.Context {
+	return context.WithValue(ctx, coreKey{}, core)
+}
+
+// Scope makes ctx log to the same output as from, such as a background context outliving the context it was
+// created from.
+func Scope(ctx context.Context, from context.Context) context.Context {
+	if core, ok := from.Value(coreKey{}).(zapcore.Core); ok {
+		return WithCore(ctx, core)
+	}
+	return ctx
+}
+
 type (
 	Level = zapcore.Level
 )
@@ -61,6 +89,10 @@ func New(opts ...Option) *zap.Logger {
 	var logger *zap.Logger
 	var err error
 
+	if options.Core != nil {
+		return zap.New(options.Core, zap.AddCaller())
+	}
+
 	switch logMode {
 	case "PROD", "PRODUCTION":
 		cfg := zap.NewProductionConfig()
@@ -107,6 +139,9 @@ func FromContext(ctx context.Context, opts ...Option) *zap.Logger {
 		props.HttpRequestID(requestId),
 	))
 	opts = append(opts, ctxDecorator(ctx)...)
+	if core, ok := ctx.Value(coreKey{}).(zapcore.Core); ok {
+		opts = append(opts, Core(core))
+	}
 	return New(opts...)
 }
 
@@ -1,12 +1,17 @@
 package logger
 
-import "go.uber.org/zap"
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
 
 type Options struct {
 	SkipCallFrame   int
 	Name            string
 	NamingDecorator func(string) string
 	Fields          []zap.Field
+	// Core replaces the configured output, see WithCore.
+	Core zapcore.Core
 }
 
 type Option func(options *Options)
@@ -52,3 +57,9 @@ func Fields(fields ...zap.Field) Option {
 		options.Fields = append(options.Fields, fields...)
 	}
 }
+
+func Core(core zapcore.Core) Option {
+	return func(options *Options) {
+		options.Core = core
+	}
+}
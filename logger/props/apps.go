@@ -21,3 +21,23 @@ func AppStartTimeout(timeout time.Duration) zap.Field {
 func AppStopTimeout(timeout time.Duration) zap.Field {
 	return zap.Duration("app.timeouts.stop", timeout)
 }
+
+func AppPhase(phase string) zap.Field {
+	return zap.String("app.phase", phase)
+}
+
+func AppPhaseDuration(duration time.Duration) zap.Field {
+	return zap.Duration("app.phase.duration", duration)
+}
+
+func AppPhaseServices(count int) zap.Field {
+	return zap.Int("app.phase.services", count)
+}
+
+func AppRole(role string) zap.Field {
+	return zap.String("app.role", role)
+}
+
+func AppModule(module string) zap.Field {
+	return zap.String("app.module", module)
+}
@@ -0,0 +1,11 @@
+package props
+
+import "go.uber.org/zap"
+
+func EventType(eventType string) zap.Field {
+	return zap.String("event.type", eventType)
+}
+
+func EventHandler(handler string) zap.Field {
+	return zap.String("event.handler", handler)
+}
@@ -0,0 +1,19 @@
+package props
+
+import "go.uber.org/zap"
+
+func JobID(id int64) zap.Field {
+	return zap.Int64("job.id", id)
+}
+
+func JobKind(kind string) zap.Field {
+	return zap.String("job.kind", kind)
+}
+
+func JobQueue(queue string) zap.Field {
+	return zap.String("job.queue", queue)
+}
+
+func JobAttempt(attempt int) zap.Field {
+	return zap.Int("job.attempt", attempt)
+}
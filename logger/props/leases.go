@@ -0,0 +1,15 @@
+package props
+
+import "go.uber.org/zap"
+
+func LeaseName(name string) zap.Field {
+	return zap.String("lease.name", name)
+}
+
+func LeaseHolder(holder string) zap.Field {
+	return zap.String("lease.holder", holder)
+}
+
+func LeaseToken(token int64) zap.Field {
+	return zap.Int64("lease.token", token)
+}
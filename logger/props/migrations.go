@@ -0,0 +1,23 @@
+package props
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func MigrationName(name string) zap.Field {
+	return zap.String("migration.name", name)
+}
+
+func MigrationDuration(duration time.Duration) zap.Field {
+	return zap.Duration("migration.duration", duration)
+}
+
+func BackfillProcessed(rows int64) zap.Field {
+	return zap.Int64("backfill.processed", rows)
+}
+
+func BackfillRate(rowsPerSecond float64) zap.Field {
+	return zap.Float64("backfill.rate", rowsPerSecond)
+}
@@ -0,0 +1,19 @@
+package props
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TaskName(name string) zap.Field {
+	return zap.String("task.name", name)
+}
+
+func TaskOutcome(outcome string) zap.Field {
+	return zap.String("task.outcome", outcome)
+}
+
+func TaskDuration(duration time.Duration) zap.Field {
+	return zap.Duration("task.duration", duration)
+}
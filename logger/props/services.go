@@ -0,0 +1,19 @@
+package props
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func ServiceName(name string) zap.Field {
+	return zap.String("service.name", name)
+}
+
+func ServiceRestartAttempt(attempt int) zap.Field {
+	return zap.Int("service.restart.attempt", attempt)
+}
+
+func ServiceRestartBackoff(backoff time.Duration) zap.Field {
+	return zap.Duration("service.restart.backoff", backoff)
+}
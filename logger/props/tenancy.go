@@ -0,0 +1,15 @@
+package props
+
+import "go.uber.org/zap"
+
+func TenantId(id string) zap.Field {
+	return zap.String("tenant.id", id)
+}
+
+func TenancyReason(reason string) zap.Field {
+	return zap.String("tenancy.reason", reason)
+}
+
+func TenancyActor(actor string) zap.Field {
+	return zap.String("tenancy.actor", actor)
+}
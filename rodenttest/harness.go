@@ -0,0 +1,89 @@
+package rodenttest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/fx"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/system/opt"
+	"github.com/kiwiworks/rodent/telemetry"
+	"github.com/kiwiworks/rodent/web/sdk"
+	"github.com/kiwiworks/rodent/web/server"
+)
+
+// Harness is a started rodent application, it is stopped automatically when the test ends.
+type Harness struct {
+	App *app.App
+	// Logs holds the logs of the application, and those emitted through the contexts it handed to its hooks
+	// and requests. Loggers created without a context, see logger.New, write to the configured output instead.
+	Logs *observer.ObservedLogs
+	// Spans holds every span exported by the application tracers.
+	Spans *tracetest.InMemoryExporter
+	// BaseURL and Client are only set when the modules include web.Module.
+	BaseURL string
+	Client  *sdk.Client
+}
+
+type serverParams struct {
+	fx.In
+	Server *server.Server `optional:"true"`
+}
+
+// Start creates and starts an application made of the configured modules, failing the test on any error.
+func Start(t testing.TB, opts ...opt.Option[Options]) *Harness {
+	t.Helper()
+	options := newOptions()
+	opt.Apply(&options, opts...)
+
+	core, logs := observer.New(options.LogLevel)
+	spans := tracetest.NewInMemoryExporter()
+	var webServer *server.Server
+	instance, err := app.TryNew(options.Name, options.Version,
+		app.Modules(options.Modules...),
+		app.LogTo(core),
+		app.Telemetry(telemetry.SpanExporter(spans)),
+		app.FxOptions(
+			// the tests go through the real listener and its handler chain, on a free port
+			fx.Supply(server.ListenAddress("127.0.0.1:0")),
+			fx.Invoke(func(params serverParams) {
+				webServer = params.Server
+			}),
+		),
+		app.FxOptions(options.FxOptions...),
+	)
+	if err != nil {
+		t.Fatalf("rodenttest: failed to create application: %+v", err)
+	}
+
+	harness := &Harness{
+		App:   instance,
+		Logs:  logs,
+		Spans: spans,
+	}
+	manifest := instance.Manifest()
+	startCtx, cancel := context.WithTimeout(context.Background(), manifest.Timeouts.Start)
+	defer cancel()
+	if err = instance.Start(startCtx); err != nil {
+		t.Fatalf("rodenttest: failed to start application: %+v", err)
+	}
+	t.Cleanup(func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), manifest.Timeouts.Stop)
+		defer cancel()
+		if err := instance.Stop(stopCtx); err != nil {
+			t.Errorf("rodenttest: failed to stop application: %+v", err)
+		}
+	})
+
+	if webServer != nil {
+		harness.BaseURL = "http://" + webServer.Address()
+		harness.Client, err = sdk.New(harness.BaseURL, options.ClientOptions...)
+		if err != nil {
+			t.Fatalf("rodenttest: failed to create sdk client: %+v", err)
+		}
+	}
+	return harness
+}
@@ -0,0 +1,89 @@
+package rodenttest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/module"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/web"
+	"github.com/kiwiworks/rodent/web/api"
+	"github.com/kiwiworks/rodent/web/http"
+	"github.com/kiwiworks/rodent/web/sdk"
+)
+
+type greeting struct {
+	Message string `json:"message"`
+}
+
+type greeter struct {
+	message string
+}
+
+func newGreeter() *greeter {
+	return &greeter{message: "hello"}
+}
+
+func greet(g *greeter) *api.Handler {
+	return api.NewHandler(http.GET, "/greet", func(ctx context.Context, _ *http.Empty) (*api.Response[greeting], error) {
+		return api.Ok(greeting{Message: g.message})
+	})
+}
+
+func greeterModule() app.Module {
+	return app.NewModule(
+		module.Public(newGreeter),
+		module.Handlers(greet),
+	)
+}
+
+func TestStart(t *testing.T) {
+	r := require.New(t)
+	harness := Start(t,
+		Modules(web.Module, greeterModule),
+		Replace(&greeter{message: "hello from the test"}),
+	)
+	r.NotEmpty(harness.BaseURL)
+	r.NotNil(harness.Client)
+
+	response, err := sdk.Execute[greeting](context.Background(), *harness.Client, harness.Client.Request("GET", "/greet"))
+	r.NoError(err)
+	r.Equal("hello from the test", response.Message)
+
+	r.NotZero(harness.Logs.FilterMessage("application created").Len())
+	r.NotEmpty(harness.Spans.GetSpans())
+}
+
+func TestParallelHarnessesLogApart(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			r := require.New(t)
+			harness := Start(t, Named(name, "0.0.0"), Modules(web.Module, greeterModule))
+			_, err := sdk.Execute[greeting](context.Background(), *harness.Client, harness.Client.Request("GET", "/greet"))
+			r.NoError(err)
+
+			created := harness.Logs.FilterMessage("application created").AllUntimed()
+			r.Len(created, 1)
+			r.Equal(name, created[0].ContextMap()["app.name"])
+			r.NotZero(harness.Logs.FilterMessage("ok").FilterField(props.HttpPath("/greet")).Len())
+		})
+	}
+}
+
+func TestStartWithoutWeb(t *testing.T) {
+	r := require.New(t)
+	var g *greeter
+	harness := Start(t,
+		Modules(func() app.Module {
+			return app.NewModule(module.Public(newGreeter))
+		}),
+		Populate(&g),
+	)
+	r.Empty(harness.BaseURL)
+	r.Nil(harness.Client)
+	r.Equal("hello", g.message)
+}
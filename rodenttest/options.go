@@ -0,0 +1,94 @@
+package rodenttest
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/system/opt"
+	"github.com/kiwiworks/rodent/web/sdk"
+)
+
+type Options struct {
+	Name          string
+	Version       string
+	Modules       []func() app.Module
+	FxOptions     []fx.Option
+	LogLevel      logger.Level
+	ClientOptions []opt.Option[sdk.Config]
+}
+
+func newOptions() Options {
+	return Options{
+		Name:          "rodenttest",
+		Version:       "0.0.0",
+		Modules:       []func() app.Module{},
+		FxOptions:     []fx.Option{},
+		LogLevel:      logger.DebugLevel,
+		ClientOptions: []opt.Option[sdk.Config]{},
+	}
+}
+
+// Named sets the name and version of the application under test.
+func Named(name, version string) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.Name = name
+		opt.Version = version
+	}
+}
+
+// Modules adds the modules to start.
+func Modules(modules ...func() app.Module) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.Modules = append(opt.Modules, modules...)
+	}
+}
+
+// Replace replaces values provided by the modules with the given ones, see fx.Replace.
+func Replace(values ...any) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.FxOptions = append(opt.FxOptions, fx.Replace(values...))
+	}
+}
+
+// Decorate overrides values provided by the modules through decorators, see fx.Decorate.
+func Decorate(decorators ...any) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.FxOptions = append(opt.FxOptions, fx.Decorate(decorators...))
+	}
+}
+
+// Provide adds providers which are not part of any module, such as fakes of missing dependencies.
+func Provide(providers ...any) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.FxOptions = append(opt.FxOptions, fx.Provide(providers...))
+	}
+}
+
+// Supply adds instances which are not part of any module.
+func Supply(values ...any) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.FxOptions = append(opt.FxOptions, fx.Supply(values...))
+	}
+}
+
+// Populate extracts values from the dependency graph into the given pointers, see fx.Populate.
+func Populate(targets ...any) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.FxOptions = append(opt.FxOptions, fx.Populate(targets...))
+	}
+}
+
+// LogLevel sets the minimum level of the captured logs.
+func LogLevel(level logger.Level) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.LogLevel = level
+	}
+}
+
+// ClientOptions configures the sdk.Client pointing to the application under test.
+func ClientOptions(options ...opt.Option[sdk.Config]) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.ClientOptions = append(opt.ClientOptions, options...)
+	}
+}
@@ -0,0 +1,25 @@
+package scheduler
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/assert"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/slices"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// Tasks registers task providers, they must only return a *scheduler.Task.
+func Tasks(taskProviders ...any) opt.Option[app.Module] {
+	for _, taskProvider := range taskProviders {
+		if err := assert.FuncHasReturn[*Task](taskProvider); err != nil {
+			panic(errors.Wrapf(err, "scheduler.Tasks only accepts function of any arity, which must only return *scheduler.Task"))
+		}
+	}
+	return func(opt *app.Module) {
+		opt.Public = append(opt.Public, slices.Map(taskProviders, func(in any) any {
+			return fx.Annotate(in, fx.ResultTags(`group:"scheduler.task"`))
+		})...)
+	}
+}
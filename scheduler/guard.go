@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/module"
+	"github.com/kiwiworks/rodent/database/migration"
+	"github.com/kiwiworks/rodent/database/pg"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// GuardSchema records the last tick of each task which a replica claimed.
+const GuardSchema = `
+CREATE TABLE IF NOT EXISTS rodent_scheduler_ticks (
+	task       TEXT        PRIMARY KEY,
+	tick       TIMESTAMPTZ NOT NULL,
+	claimed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+`
+
+type (
+	// Guard makes a single replica run each tick of a task, the others skip it.
+	Guard interface {
+		// Acquire returns whether this replica runs the given tick of the task, release must be called once the
+		// run is over. The tick is the unjittered time the run was scheduled at, it is shared by every replica.
+		Acquire(ctx context.Context, task string, tick time.Time) (release func(), acquired bool, err error)
+	}
+	advisoryLockGuard struct {
+		db *pg.Database
+	}
+)
+
+// AdvisoryLockGuard makes the Scheduler guard its tasks with NewAdvisoryLockGuard, it registers the migration
+// of the ticks table, which relies on migration.Module.
+func AdvisoryLockGuard() opt.Option[app.Module] {
+	provide := module.Public(NewAdvisoryLockGuard)
+	migrations := migration.Migrations(guardMigration)
+	return func(opt *app.Module) {
+		provide(opt)
+		migrations(opt)
+	}
+}
+
+// NewAdvisoryLockGuard guards the tasks with Postgres session advisory locks, keyed by the task name, so that a run
+// does not overlap the one of another replica, and records the last tick of each task, so that a replica whose timer
+// fires once another replica completed the tick skips it.
+func NewAdvisoryLockGuard(db *pg.Database) Guard {
+	return &advisoryLockGuard{db: db}
+}
+
+func (g *advisoryLockGuard) Acquire(ctx context.Context, task string, tick time.Time) (func(), bool, error) {
+	lock, acquired, err := g.db.TryLock(ctx, "rodent.scheduler:"+task)
+	if err != nil || !acquired {
+		return func() {}, false, err
+	}
+	release := func() {
+		_ = lock.Release(context.WithoutCancel(ctx))
+	}
+	var claimed time.Time
+	err = g.db.DB().QueryRowContext(ctx, `
+		INSERT INTO rodent_scheduler_ticks (task, tick) VALUES ($1, $2)
+		ON CONFLICT (task) DO UPDATE SET tick = EXCLUDED.tick, claimed_at = now()
+		WHERE rodent_scheduler_ticks.tick < EXCLUDED.tick
+		RETURNING tick`,
+		task, tick,
+	).Scan(&claimed)
+	if errors.Is(err, sql.ErrNoRows) {
+		release()
+		return func() {}, false, nil
+	}
+	if err != nil {
+		release()
+		return func() {}, false, errors.Wrapf(err, "failed to claim tick %s of task %s", tick, task)
+	}
+	return release, true, nil
+}
+
+// guardMigration creates the ticks table of the advisoryLockGuard through the migration.Migrator.
+func guardMigration(db *pg.Database) *migration.Migration {
+	return migration.New(
+		"rodent_scheduler_ticks",
+		time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, GuardSchema); err != nil {
+				return errors.Wrapf(err, "failed to create the scheduler ticks table")
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			if _, err := db.Executor(ctx).ExecContext(ctx, `DROP TABLE IF EXISTS rodent_scheduler_ticks`); err != nil {
+				return errors.Wrapf(err, "failed to drop the scheduler ticks table")
+			}
+			return nil
+		},
+		migration.ChecksumOf(GuardSchema),
+		migration.AnyOrder(),
+	)
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/pg/pgtest"
+)
+
+func TestAdvisoryLockGuard(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	db := pgtest.New(t)
+	pgtest.Exec(t, db, GuardSchema)
+	guard := NewAdvisoryLockGuard(db)
+	tick := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+	release, acquired, err := guard.Acquire(ctx, "cleanup", tick)
+	r.NoError(err)
+	r.True(acquired)
+	_, acquired, err = guard.Acquire(ctx, "cleanup", tick.Add(time.Hour))
+	r.NoError(err)
+	r.False(acquired, "the run of another replica is still going")
+	release()
+
+	_, acquired, err = guard.Acquire(ctx, "cleanup", tick)
+	r.NoError(err)
+	r.False(acquired, "a replica whose timer fired late skips the tick which already ran")
+
+	release, acquired, err = guard.Acquire(ctx, "report", tick)
+	r.NoError(err)
+	r.True(acquired, "tasks are guarded independently")
+	release()
+
+	release, acquired, err = guard.Acquire(ctx, "cleanup", tick.Add(time.Hour))
+	r.NoError(err)
+	r.True(acquired)
+	release()
+}
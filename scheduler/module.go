@@ -0,0 +1,13 @@
+package scheduler
+
+import (
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/module"
+)
+
+func Module() app.Module {
+	return app.NewModule(
+		module.Public(New),
+		module.Service[Scheduler](),
+	)
+}
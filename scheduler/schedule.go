@@ -0,0 +1,187 @@
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+type (
+	// Schedule computes when a task runs next.
+	Schedule interface {
+		// Next returns the first run strictly after the given time, or the zero time if the task never runs again.
+		Next(after time.Time) time.Time
+	}
+	interval struct {
+		every time.Duration
+	}
+	// cronSchedule is a standard 5 fields cron expression: minute, hour, day of month, month and day of week.
+	cronSchedule struct {
+		minute, hour, dom, month, dow uint64
+		// restricted day fields are OR-ed together, as cron does when both are restricted
+		domRestricted, dowRestricted bool
+	}
+	field struct {
+		name     string
+		min, max int
+	}
+)
+
+var (
+	fields = []field{
+		{name: "minute", min: 0, max: 59},
+		{name: "hour", min: 0, max: 23},
+		{name: "day of month", min: 1, max: 31},
+		{name: "month", min: 1, max: 12},
+		{name: "day of week", min: 0, max: 7},
+	}
+	descriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// Every runs the task at a fixed interval, its ticks are aligned on multiples of the interval, so that every replica
+// shares them, such as every quarter hour for `Every(15 * time.Minute)`.
+func Every(every time.Duration) Schedule {
+	if every <= 0 {
+		panic(errors.Newf("scheduler.Every requires a positive interval, got %s", every))
+	}
+	return interval{every: every}
+}
+
+func (i interval) Next(after time.Time) time.Time {
+	return after.Truncate(i.every).Add(i.every)
+}
+
+// Cron parses a cron expression, it panics if the expression is invalid, see ParseCron.
+func Cron(expression string) Schedule {
+	return errors.Must(ParseCron(expression))
+}
+
+// ParseCron parses a standard 5 fields cron expression, such as `*/15 9-17 * * 1-5`,
+// or one of the @yearly, @monthly, @weekly, @daily, @midnight and @hourly descriptors.
+func ParseCron(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if descriptor, ok := descriptors[expression]; ok {
+		expression = descriptor
+	}
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return nil, errors.Newf("cron expression '%s' must have %d fields, got %d", expression, len(fields), len(parts))
+	}
+	bits := make([]uint64, len(fields))
+	for idx, part := range parts {
+		value, err := parseField(part, fields[idx])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cron expression '%s'", expression)
+		}
+		bits[idx] = value
+	}
+	// sunday is both 0 and 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &cronSchedule{
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseField(expression string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expression, ",") {
+		rangeExpression, stepExpression, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepExpression)
+			if err != nil || parsed <= 0 {
+				return 0, errors.Newf("invalid step '%s' for the %s field", stepExpression, f.name)
+			}
+			step = parsed
+		}
+		low, high := f.min, f.max
+		switch {
+		case rangeExpression == "*":
+		case strings.Contains(rangeExpression, "-"):
+			lowExpression, highExpression, _ := strings.Cut(rangeExpression, "-")
+			var err error
+			if low, err = parseValue(lowExpression, f); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(highExpression, f); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, errors.Newf("invalid range '%s' for the %s field", rangeExpression, f.name)
+			}
+		default:
+			value, err := parseValue(rangeExpression, f)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			if !hasStep {
+				high = value
+			}
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(expression string, f field) (int, error) {
+	value, err := strconv.Atoi(expression)
+	if err != nil || value < f.min || value > f.max {
+		return 0, errors.Newf("invalid value '%s' for the %s field, it must be between %d and %d", expression, f.name, f.min, f.max)
+	}
+	return value, nil
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<value) != 0
+}
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domMatches := has(c.dom, t.Day())
+	dowMatches := has(c.dow, int(t.Weekday()))
+	if c.domRestricted && c.dowRestricted {
+		return domMatches || dowMatches
+	}
+	return domMatches && dowMatches
+}
+
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	// an expression such as `0 0 30 2 *` never matches, give up after a few years
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !has(c.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !has(c.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
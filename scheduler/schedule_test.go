@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	// a wednesday
+	from := time.Date(2024, time.May, 15, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expression string
+		next       time.Time
+	}{
+		{expression: "* * * * *", next: time.Date(2024, time.May, 15, 10, 8, 0, 0, time.UTC)},
+		{expression: "*/15 * * * *", next: time.Date(2024, time.May, 15, 10, 15, 0, 0, time.UTC)},
+		{expression: "0 9-17 * * *", next: time.Date(2024, time.May, 15, 11, 0, 0, 0, time.UTC)},
+		{expression: "30 2 * * *", next: time.Date(2024, time.May, 16, 2, 30, 0, 0, time.UTC)},
+		{expression: "0 0 * * 1-5", next: time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 * * 7", next: time.Date(2024, time.May, 19, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 1 * 0", next: time.Date(2024, time.May, 19, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 29 2 *", next: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{expression: "5,10 8 1 6,12 *", next: time.Date(2024, time.June, 1, 8, 5, 0, 0, time.UTC)},
+		{expression: "@monthly", next: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{expression: "@hourly", next: time.Date(2024, time.May, 15, 11, 0, 0, 0, time.UTC)},
+		{expression: "0 0 30 2 *", next: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expression)
+			require.NoError(t, err)
+			require.Equal(t, tt.next, schedule.Next(from))
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{expression: "* * * *", err: "must have 5 fields, got 4"},
+		{expression: "60 * * * *", err: "invalid value '60' for the minute field"},
+		{expression: "* * 0 * *", err: "invalid value '0' for the day of month field"},
+		{expression: "*/0 * * * *", err: "invalid step '0' for the minute field"},
+		{expression: "* 5-2 * * *", err: "invalid range '5-2' for the hour field"},
+		{expression: "@often", err: "must have 5 fields, got 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := ParseCron(tt.expression)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestEvery(t *testing.T) {
+	from := time.Date(2024, time.May, 15, 10, 7, 30, 0, time.UTC)
+	require.Equal(t, time.Date(2024, time.May, 15, 10, 15, 0, 0, time.UTC), Every(15*time.Minute).Next(from))
+	require.Equal(t, Every(15*time.Minute).Next(from), Every(15*time.Minute).Next(from.Add(time.Minute*5)),
+		"replicas started apart share the tick")
+	require.Panics(t, func() { Every(0) })
+}
@@ -0,0 +1,279 @@
+package scheduler
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/telemetry"
+)
+
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeCancelled = "cancelled"
+	OutcomeSkipped   = "skipped"
+)
+
+type (
+	// Scheduler runs every registered Task according to its Schedule.
+	Scheduler struct {
+		tasks    []*Task
+		guard    Guard
+		tracer   trace.Tracer
+		mu       sync.Mutex
+		stopping chan struct{}
+		cancel   context.CancelFunc
+		loops    sync.WaitGroup
+		runs     sync.WaitGroup
+	}
+	Params struct {
+		fx.In
+		Tasks     []*Task              `group:"scheduler.task"`
+		Guard     Guard                `optional:"true"`
+		Telemetry *telemetry.Telemetry `optional:"true"`
+	}
+	// runner tracks the current run of a task, to enforce its OverlapPolicy.
+	runner struct {
+		task     *Task
+		stopping <-chan struct{}
+		mu       sync.Mutex
+		running  bool
+		queued   bool
+		// tick is the scheduled time of the queued run
+		tick   time.Time
+		cancel context.CancelFunc
+		done   chan struct{}
+	}
+)
+
+func New(params Params) (*Scheduler, error) {
+	names := make(map[string]struct{}, len(params.Tasks))
+	for _, task := range params.Tasks {
+		if _, exists := names[task.Name]; exists {
+			return nil, errors.Newf("task %s is registered more than once", task.Name)
+		}
+		names[task.Name] = struct{}{}
+	}
+	var tracer trace.Tracer
+	if params.Telemetry != nil {
+		tracer = params.Telemetry.Tracer("scheduler")
+	} else {
+		tracer = otel.Tracer("scheduler")
+	}
+	return &Scheduler{
+		tasks:  params.Tasks,
+		guard:  params.Guard,
+		tracer: tracer,
+	}, nil
+}
+
+func (s *Scheduler) OnStart(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.mu.Lock()
+	s.stopping = make(chan struct{})
+	s.cancel = cancel
+	stopping := s.stopping
+	s.mu.Unlock()
+	for _, task := range s.tasks {
+		log.Info("scheduling task",
+			props.TaskName(task.Name),
+			zap.Stringer("task.overlap", task.Overlap),
+			zap.Bool("task.guarded", s.guard != nil && !task.EveryInstance),
+		)
+		s.loops.Add(1)
+		go s.loop(runCtx, &runner{task: task, stopping: stopping})
+	}
+	return nil
+}
+
+// OnStop stops scheduling new runs and waits for the current ones, they are cancelled if the context expires first.
+func (s *Scheduler) OnStop(ctx context.Context) error {
+	s.mu.Lock()
+	stopping, cancel := s.stopping, s.cancel
+	s.mu.Unlock()
+	if stopping == nil {
+		return nil
+	}
+	close(stopping)
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+	defer cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "failed to wait for the running tasks")
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, r *runner) {
+	defer s.loops.Done()
+	log := logger.FromContext(ctx).With(props.TaskName(r.task.Name))
+	tick := time.Now()
+	for {
+		tick = nextTick(r.task, tick, time.Now())
+		if tick.IsZero() {
+			log.Warn("task will never run again")
+			return
+		}
+		at := tick
+		if r.task.Jitter > 0 {
+			at = at.Add(rand.N(r.task.Jitter))
+		}
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-r.stopping:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.trigger(ctx, r, tick)
+	}
+}
+
+// nextTick returns the tick following the given one, it follows the scheduled time rather than the jittered time
+// the tick ran at, so that every replica computes the same ticks. The ticks which are already late by more than
+// the jitter, because the loop was blocked, are skipped.
+func nextTick(task *Task, tick, now time.Time) time.Time {
+	next := task.Schedule.Next(tick)
+	if since := now.Add(-task.Jitter); !next.IsZero() && next.Before(since) {
+		return task.Schedule.Next(since)
+	}
+	return next
+}
+
+// trigger starts the run of the tick of the task, unless its overlap policy says otherwise.
+func (s *Scheduler) trigger(ctx context.Context, r *runner, tick time.Time) {
+	r.mu.Lock()
+	if r.running {
+		switch r.task.Overlap {
+		case OverlapQueue:
+			r.queued = true
+			r.tick = tick
+			r.mu.Unlock()
+			return
+		case OverlapCancelPrevious:
+			r.cancel()
+			done := r.done
+			r.mu.Unlock()
+			<-done
+			r.mu.Lock()
+		default:
+			r.mu.Unlock()
+			logger.FromContext(ctx).Info("task run skipped, the previous one is still running",
+				props.TaskName(r.task.Name),
+				props.TaskOutcome(OutcomeSkipped),
+			)
+			return
+		}
+	}
+	s.start(ctx, r, tick)
+	r.mu.Unlock()
+}
+
+// start runs the task in the background, r.mu must be held.
+func (s *Scheduler) start(ctx context.Context, r *runner, tick time.Time) {
+	runCtx, cancel := context.WithCancel(ctx)
+	r.running = true
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	done := r.done
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		s.run(runCtx, r.task, tick)
+		cancel()
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.running = false
+		close(done)
+		if !r.queued {
+			return
+		}
+		r.queued = false
+		select {
+		case <-r.stopping:
+		default:
+			s.start(ctx, r, r.tick)
+		}
+	}()
+}
+
+// run executes the run of the tick of the task, traced and logged with its outcome.
+func (s *Scheduler) run(ctx context.Context, task *Task, tick time.Time) {
+	ctx, span := s.tracer.Start(ctx, "scheduler.run "+task.Name,
+		trace.WithNewRoot(),
+		trace.WithAttributes(attribute.String("task.name", task.Name)),
+	)
+	defer span.End()
+	log := logger.FromContext(ctx).With(props.TaskName(task.Name))
+	startedAt := time.Now()
+
+	if s.guard != nil && !task.EveryInstance {
+		release, acquired, err := s.guard.Acquire(ctx, task.Name, tick)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to acquire the task guard", props.TaskOutcome(OutcomeFailed), zap.Error(err))
+			return
+		}
+		defer release()
+		if !acquired {
+			span.SetAttributes(attribute.String("task.outcome", OutcomeSkipped))
+			log.Debug("task run skipped, another instance ran or is running it", props.TaskOutcome(OutcomeSkipped))
+			return
+		}
+	}
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.Timeout)
+		defer cancel()
+	}
+
+	err := invoke(ctx, task)
+	duration := time.Since(startedAt)
+	outcome := OutcomeSucceeded
+	switch {
+	case err != nil && errors.Is(err, context.Canceled):
+		outcome = OutcomeCancelled
+	case err != nil:
+		outcome = OutcomeFailed
+	}
+	span.SetAttributes(attribute.String("task.outcome", outcome))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("task run failed", props.TaskOutcome(outcome), props.TaskDuration(duration), zap.Error(err))
+		return
+	}
+	log.Info("task run completed", props.TaskOutcome(outcome), props.TaskDuration(duration))
+}
+
+// invoke isolates the task, a panic fails the run instead of crashing the application.
+func invoke(ctx context.Context, task *Task) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.Newf("task %s panicked: %v", task.Name, recovered)
+		}
+	}()
+	return task.Run(ctx)
+}
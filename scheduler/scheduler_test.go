@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGuard struct {
+	acquired bool
+	released atomic.Int32
+	ticks    []time.Time
+}
+
+func (g *fakeGuard) Acquire(_ context.Context, _ string, tick time.Time) (func(), bool, error) {
+	g.ticks = append(g.ticks, tick)
+	return func() { g.released.Add(1) }, g.acquired, nil
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	tests := []struct {
+		policy    OverlapPolicy
+		runs      int32
+		cancelled int32
+	}{
+		{policy: OverlapSkip, runs: 1},
+		{policy: OverlapQueue, runs: 2},
+		{policy: OverlapCancelPrevious, runs: 3, cancelled: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			r := require.New(t)
+			var runs, cancelled atomic.Int32
+			release := make(chan struct{})
+			task := NewTask("overlap", Every(time.Hour), func(ctx context.Context) error {
+				runs.Add(1)
+				select {
+				case <-ctx.Done():
+					cancelled.Add(1)
+					return ctx.Err()
+				case <-release:
+					return nil
+				}
+			}, Overlap(tt.policy))
+			scheduler, err := New(Params{Tasks: []*Task{task}})
+			r.NoError(err)
+			r.NoError(scheduler.OnStart(context.Background()))
+
+			runner := &runner{task: task, stopping: make(chan struct{})}
+			scheduler.trigger(context.Background(), runner, time.Now())
+			r.Eventually(func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+			// the second and third ticks overlap the current run, queued runs are coalesced
+			scheduler.trigger(context.Background(), runner, time.Now())
+			scheduler.trigger(context.Background(), runner, time.Now())
+			close(release)
+			scheduler.runs.Wait()
+
+			r.NoError(scheduler.OnStop(context.Background()))
+			r.Equal(tt.runs, runs.Load())
+			r.Equal(tt.cancelled, cancelled.Load())
+		})
+	}
+}
+
+func TestSchedulerGuard(t *testing.T) {
+	r := require.New(t)
+	var runs atomic.Int32
+	run := func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}
+	guarded := NewTask("guarded", Every(time.Hour), run)
+	everywhere := NewTask("everywhere", Every(time.Hour), run, EveryInstance())
+	guard := &fakeGuard{}
+	scheduler, err := New(Params{Tasks: []*Task{guarded, everywhere}, Guard: guard})
+	r.NoError(err)
+
+	tick := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	scheduler.run(context.Background(), guarded, tick)
+	scheduler.run(context.Background(), everywhere, tick)
+	r.Equal(int32(1), runs.Load())
+	r.Equal(int32(1), guard.released.Load())
+
+	guard.acquired = true
+	scheduler.run(context.Background(), guarded, tick.Add(time.Hour))
+	r.Equal(int32(2), runs.Load())
+	r.Equal(int32(2), guard.released.Load())
+	r.Equal([]time.Time{tick, tick.Add(time.Hour)}, guard.ticks)
+}
+
+func TestNewRejectsDuplicateTasks(t *testing.T) {
+	run := func(context.Context) error { return nil }
+	_, err := New(Params{Tasks: []*Task{
+		NewTask("cleanup", Every(time.Hour), run),
+		NewTask("cleanup", Cron("@daily"), run),
+	}})
+	require.ErrorContains(t, err, "task cleanup is registered more than once")
+}
+
+func TestNextTick(t *testing.T) {
+	tick := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		task *Task
+		now  time.Time
+		next time.Time
+	}{
+		{
+			name: "ignores the jitter the tick ran with",
+			task: NewTask("jittered", Every(time.Minute*15), nil, Jitter(time.Minute*5)),
+			now:  tick.Add(time.Minute * 4),
+			next: tick.Add(time.Minute * 15),
+		},
+		{
+			name: "cron",
+			task: NewTask("cron", Cron("*/10 * * * *"), nil, Jitter(time.Minute*12)),
+			now:  tick.Add(time.Minute * 11),
+			next: tick.Add(time.Minute * 10),
+		},
+		{
+			name: "skips the missed ticks",
+			task: NewTask("blocked", Every(time.Minute*15), nil, Jitter(time.Minute)),
+			now:  tick.Add(time.Minute * 50),
+			next: tick.Add(time.Hour),
+		},
+		{
+			name: "keeps a late tick within the jitter",
+			task: NewTask("late", Every(time.Minute*15), nil, Jitter(time.Minute*10)),
+			now:  tick.Add(time.Minute * 20),
+			next: tick.Add(time.Minute * 15),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.next, nextTick(tt.task, tick, tt.now))
+		})
+	}
+}
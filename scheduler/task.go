@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+// OverlapPolicy defines what happens when a task is due while its previous run is still going.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the new run.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs once more right after the current run, pending runs are coalesced.
+	OverlapQueue
+	// OverlapCancelPrevious cancels the current run, and starts the new one once it returned.
+	OverlapCancelPrevious
+)
+
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueue:
+		return "queue"
+	case OverlapCancelPrevious:
+		return "cancel-previous"
+	default:
+		return "unknown"
+	}
+}
+
+type (
+	// Task is a periodic unit of work, it is registered through scheduler.Tasks.
+	Task struct {
+		Name     string
+		Schedule Schedule
+		// Jitter delays every run by a random duration up to its value, to spread the load of many replicas.
+		Jitter time.Duration
+		// Timeout bounds every run, when positive.
+		Timeout time.Duration
+		Overlap OverlapPolicy
+		// EveryInstance opts out of the Guard, the task runs on every replica.
+		EveryInstance bool
+		Run           func(ctx context.Context) error
+	}
+)
+
+func NewTask(name string, schedule Schedule, run func(ctx context.Context) error, opts ...opt.Option[Task]) *Task {
+	task := &Task{
+		Name:     name,
+		Schedule: schedule,
+		Overlap:  OverlapSkip,
+		Run:      run,
+	}
+	opt.Apply(task, opts...)
+	return task
+}
+
+func Jitter(jitter time.Duration) opt.Option[Task] {
+	return func(opt *Task) {
+		opt.Jitter = jitter
+	}
+}
+
+func Timeout(timeout time.Duration) opt.Option[Task] {
+	return func(opt *Task) {
+		opt.Timeout = timeout
+	}
+}
+
+func Overlap(policy OverlapPolicy) opt.Option[Task] {
+	return func(opt *Task) {
+		opt.Overlap = policy
+	}
+}
+
+// EveryInstance runs the task on every replica, even when a Guard is configured.
+func EveryInstance() opt.Option[Task] {
+	return func(opt *Task) {
+		opt.EveryInstance = true
+	}
+}
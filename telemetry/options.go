@@ -0,0 +1,18 @@
+package telemetry
+
+import (
+	"go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/kiwiworks/rodent/system/opt"
+)
+
+type Options struct {
+	// SpanExporter replaces the OTLP exporter configured from the environment, spans are exported synchronously.
+	SpanExporter trace.SpanExporter
+}
+
+func SpanExporter(exporter trace.SpanExporter) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.SpanExporter = exporter
+	}
+}
@@ -0,0 +1,128 @@
+package telemetry
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/kiwiworks/rodent/errors"
+)
+
+var (
+	runtimeOnce   sync.Once
+	runtimeReader *metric.ManualReader
+	runtimeErr    error
+)
+
+// startRuntimeInstrumentation reports the runtime metrics once per process, on a meter provider of their own:
+// several applications of one process, such as test harnesses, would otherwise register duplicate instruments.
+// They are readable through RuntimeStats, and produced into the exported metrics when an OTEL endpoint is configured.
+func startRuntimeInstrumentation() (*metric.ManualReader, error) {
+	runtimeOnce.Do(func() {
+		runtimeReader = metric.NewManualReader()
+		err := runtime.Start(
+			runtime.WithMeterProvider(metric.NewMeterProvider(metric.WithReader(runtimeReader))),
+			runtime.WithMinimumReadMemStatsInterval(time.Second),
+		)
+		if err != nil {
+			runtimeErr = errors.Wrapf(err, "failed to start runtime instrumentation")
+		}
+	})
+	return runtimeReader, runtimeErr
+}
+
+// runtimeProducer produces the runtime metrics into the readers exporting the metrics of a Telemetry.
+type runtimeProducer struct {
+	reader *metric.ManualReader
+}
+
+func (p runtimeProducer) Produce(ctx context.Context) ([]metricdata.ScopeMetrics, error) {
+	var collected metricdata.ResourceMetrics
+	if err := p.reader.Collect(ctx, &collected); err != nil {
+		return nil, errors.Wrapf(err, "failed to collect runtime metrics")
+	}
+	return collected.ScopeMetrics, nil
+}
+
+// RuntimeStat is a single data point reported by the runtime instrumentation, such as the goroutine count.
+type RuntimeStat struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Unit        string            `json:"unit,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Value       float64           `json:"value"`
+}
+
+// RuntimeStats collects the current goroutine, memory and scheduler stats from the runtime instrumentation.
+func (t *Telemetry) RuntimeStats(ctx context.Context) ([]RuntimeStat, error) {
+	var collected metricdata.ResourceMetrics
+	if err := t.runtimeReader.Collect(ctx, &collected); err != nil {
+		return nil, errors.Wrapf(err, "failed to collect runtime metrics")
+	}
+	stats := make([]RuntimeStat, 0)
+	for _, scope := range collected.ScopeMetrics {
+		if scope.Scope.Name != runtime.ScopeName {
+			continue
+		}
+		for _, m := range scope.Metrics {
+			for _, point := range dataPoints(m.Data) {
+				stats = append(stats, RuntimeStat{
+					Name:        m.Name,
+					Description: m.Description,
+					Unit:        m.Unit,
+					Attributes:  point.attributes,
+					Value:       point.value,
+				})
+			}
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats, nil
+}
+
+type dataPoint struct {
+	attributes map[string]string
+	value      float64
+}
+
+func attributesOf(set attribute.Set) map[string]string {
+	if set.Len() == 0 {
+		return nil
+	}
+	attributes := make(map[string]string, set.Len())
+	for _, kv := range set.ToSlice() {
+		attributes[string(kv.Key)] = kv.Value.Emit()
+	}
+	return attributes
+}
+
+func dataPoints(data metricdata.Aggregation) []dataPoint {
+	points := make([]dataPoint, 0)
+	switch data := data.(type) {
+	case metricdata.Sum[int64]:
+		for _, p := range data.DataPoints {
+			points = append(points, dataPoint{attributesOf(p.Attributes), float64(p.Value)})
+		}
+	case metricdata.Sum[float64]:
+		for _, p := range data.DataPoints {
+			points = append(points, dataPoint{attributesOf(p.Attributes), p.Value})
+		}
+	case metricdata.Gauge[int64]:
+		for _, p := range data.DataPoints {
+			points = append(points, dataPoint{attributesOf(p.Attributes), float64(p.Value)})
+		}
+	case metricdata.Gauge[float64]:
+		for _, p := range data.DataPoints {
+			points = append(points, dataPoint{attributesOf(p.Attributes), p.Value})
+		}
+	}
+	return points
+}
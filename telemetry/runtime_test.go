@@ -0,0 +1,41 @@
+package telemetry
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+
+	"github.com/kiwiworks/rodent/system/manifest"
+)
+
+func TestRuntimeInstrumentationIsShared(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	first, err := New(manifest.New("first", "0.0.0"))
+	r.NoError(err)
+	second, err := New(manifest.New("second", "0.0.0"))
+	r.NoError(err)
+	r.Same(first.runtimeReader, second.runtimeReader)
+
+	stats, err := second.RuntimeStats(ctx)
+	r.NoError(err)
+	r.NotEmpty(stats)
+	seen := make(map[string]bool)
+	for _, stat := range stats {
+		key := stat.Name
+		for _, k := range slices.Sorted(maps.Keys(stat.Attributes)) {
+			key += "," + k + "=" + stat.Attributes[k]
+		}
+		r.False(seen[key], "duplicate runtime stat %s", key)
+		seen[key] = true
+	}
+
+	produced, err := runtimeProducer{reader: first.runtimeReader}.Produce(ctx)
+	r.NoError(err)
+	r.Len(produced, 1)
+	r.Equal(runtime.ScopeName, produced[0].Scope.Name)
+}
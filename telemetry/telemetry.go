@@ -3,9 +3,7 @@ package telemetry
 import (
 	"context"
 	"os"
-	"time"
 
-	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
@@ -22,6 +20,7 @@ import (
 
 	"github.com/kiwiworks/rodent/errors"
 	"github.com/kiwiworks/rodent/system/manifest"
+	"github.com/kiwiworks/rodent/system/opt"
 )
 
 type Telemetry struct {
@@ -30,11 +29,20 @@ type Telemetry struct {
 	traceExporter *otlptrace.Exporter
 	traceProvider *trace.TracerProvider
 	meterProvider *metric.MeterProvider
+	// runtimeReader reads the runtime metrics, which are shared by every Telemetry of the process.
+	runtimeReader *metric.ManualReader
 }
 
-func New(manifest *manifest.Manifest) (*Telemetry, error) {
+func New(manifest *manifest.Manifest, opts ...opt.Option[Options]) (*Telemetry, error) {
+	options := Options{}
+	opt.Apply(&options, opts...)
+	runtimeReader, err := startRuntimeInstrumentation()
+	if err != nil {
+		return nil, err
+	}
 	t := &Telemetry{
-		manifest: manifest,
+		manifest:      manifest,
+		runtimeReader: runtimeReader,
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	t.cancel = cancel
@@ -42,6 +50,21 @@ func New(manifest *manifest.Manifest) (*Telemetry, error) {
 	propagator := newPropagator()
 	otel.SetTextMapPropagator(propagator)
 
+	if options.SpanExporter != nil {
+		traceResource, err := newResource(t.manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create resource")
+		}
+		t.traceProvider = trace.NewTracerProvider(
+			trace.WithSyncer(options.SpanExporter),
+			trace.WithResource(traceResource),
+		)
+		otel.SetTracerProvider(t.traceProvider)
+		t.meterProvider = metric.NewMeterProvider()
+		otel.SetMeterProvider(t.meterProvider)
+		return t, nil
+	}
+
 	// Set up telemetry only if OTEL endpoint is configured
 	if hasOtelEndpoint() {
 		traceExporter, err := newTraceExporter(ctx, os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"))
@@ -60,16 +83,12 @@ func New(manifest *manifest.Manifest) (*Telemetry, error) {
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create metric exporter")
 		}
-		meterProvider, err := newMeterProvider(httpMetricExporter)
+		meterProvider, err := newMeterProvider(httpMetricExporter, runtimeProducer{reader: t.runtimeReader})
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create metric provider")
 		}
 		otel.SetMeterProvider(meterProvider)
 		t.meterProvider = meterProvider
-
-		if err = runtime.Start(runtime.WithMinimumReadMemStatsInterval(time.Second)); err != nil {
-			return nil, errors.Wrapf(err, "failed to start runtime")
-		}
 	} else {
 		// Set up no-op providers when no OTEL endpoint is configured
 		noopProvider := noop.NewTracerProvider()
@@ -180,10 +199,13 @@ func newHttpMetricExporter(ctx context.Context) (*otlpmetrichttp.Exporter, error
 	return exporter, nil
 }
 
-func newMeterProvider(httpMetricExporter *otlpmetrichttp.Exporter) (*metric.MeterProvider, error) {
-	meterProvider := metric.NewMeterProvider(metric.WithReader(
-		metric.NewPeriodicReader(httpMetricExporter),
-	))
+func newMeterProvider(
+	httpMetricExporter *otlpmetrichttp.Exporter,
+	runtime metric.Producer,
+) (*metric.MeterProvider, error) {
+	meterProvider := metric.NewMeterProvider(
+		metric.WithReader(metric.NewPeriodicReader(httpMetricExporter, metric.WithProducer(runtime))),
+	)
 	return meterProvider, nil
 }
 
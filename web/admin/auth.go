@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+// authMiddleware protects every admin route with the dedicated auth.Provider.
+func authMiddleware(provider auth.Provider) func(http.Handler) http.Handler {
+	manifest := provider.Manifest()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op := &huma.Operation{
+				Method:   r.Method,
+				Path:     r.URL.Path,
+				Security: []map[string][]string{{manifest.Name: {}}},
+			}
+			user, err := provider.UserResolver(humachi.NewContext(op, r, w))
+			if err != nil || user == nil {
+				logger.New().Warn("admin authentication failed",
+					props.HttpMethod(r.Method),
+					props.HttpPath(r.URL.Path),
+					zap.String("provider.name", manifest.Name),
+					zap.Error(err),
+				)
+				writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "authentication failed"})
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.InjectUser(r.Context(), user)))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package admin
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/kiwiworks/rodent/web/auth"
+	"github.com/kiwiworks/rodent/web/server"
+)
+
+// ListenAddress sets the address the admin server listens on, it defaults to [::1]:9090.
+func ListenAddress(addr string) fx.Option {
+	return fx.Supply(fx.Annotated{
+		Name:   "admin",
+		Target: server.ListenAddress(addr),
+	})
+}
+
+// AsAuthProvider registers the auth.Provider protecting the admin server.
+func AsAuthProvider(provider any) any {
+	return fx.Annotate(provider, fx.As(new(auth.Provider)), fx.ResultTags(`name:"admin"`))
+}
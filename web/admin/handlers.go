@@ -0,0 +1,160 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/app/module"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/system/manifest"
+	"github.com/kiwiworks/rodent/telemetry"
+)
+
+type (
+	handlers struct {
+		manifest  *manifest.Manifest
+		registry  *module.ServiceRegistry
+		telemetry *telemetry.Telemetry
+	}
+	BuildInfo struct {
+		GoVersion string            `json:"goVersion"`
+		Path      string            `json:"path,omitempty"`
+		Version   string            `json:"version,omitempty"`
+		Settings  map[string]string `json:"settings,omitempty"`
+	}
+	ManifestResponse struct {
+		Manifest *manifest.Manifest `json:"manifest"`
+		Build    BuildInfo          `json:"build"`
+	}
+	RuntimeResponse struct {
+		Goroutines int                     `json:"goroutines"`
+		HeapAlloc  uint64                  `json:"heapAlloc"`
+		HeapInuse  uint64                  `json:"heapInuse"`
+		Sys        uint64                  `json:"sys"`
+		NumGC      uint32                  `json:"numGC"`
+		Stats      []telemetry.RuntimeStat `json:"stats"`
+	}
+	LogLevel struct {
+		Level string `json:"level"`
+	}
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+func (h *handlers) mount(mux chi.Router) {
+	if h.registry != nil {
+		mux.Get("/healthz", h.liveness)
+		mux.Get("/readyz", h.readiness)
+	}
+	mux.Get("/manifest", h.getManifest)
+	mux.Get("/runtime", h.getRuntime)
+	mux.Get("/log/level", h.getLogLevel)
+	mux.Put("/log/level", h.putLogLevel)
+	mux.HandleFunc("/debug/pprof/*", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.New().Error("failed to write admin response", zap.Error(err))
+	}
+}
+
+func (h *handlers) liveness(w http.ResponseWriter, _ *http.Request) {
+	report := h.registry.Health()
+	writeJSON(w, healthStatus(report.Live), report)
+}
+
+func (h *handlers) readiness(w http.ResponseWriter, _ *http.Request) {
+	report := h.registry.Health()
+	writeJSON(w, healthStatus(report.Ready), report)
+}
+
+func healthStatus(healthy bool) int {
+	if healthy {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+func (h *handlers) getManifest(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, ManifestResponse{
+		Manifest: h.manifest,
+		Build:    buildInfo(),
+	})
+}
+
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		GoVersion: runtime.Version(),
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Path = build.Main.Path
+	info.Version = build.Main.Version
+	info.Settings = make(map[string]string, len(build.Settings))
+	for _, setting := range build.Settings {
+		info.Settings[setting.Key] = setting.Value
+	}
+	return info
+}
+
+func (h *handlers) getRuntime(w http.ResponseWriter, r *http.Request) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	response := RuntimeResponse{
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  memStats.HeapAlloc,
+		HeapInuse:  memStats.HeapInuse,
+		Sys:        memStats.Sys,
+		NumGC:      memStats.NumGC,
+		Stats:      []telemetry.RuntimeStat{},
+	}
+	if h.telemetry != nil {
+		stats, err := h.telemetry.RuntimeStats(r.Context())
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+		response.Stats = stats
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *handlers) getLogLevel(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, LogLevel{Level: logger.GetLevel().String()})
+}
+
+func (h *handlers) putLogLevel(w http.ResponseWriter, r *http.Request) {
+	var body LogLevel
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid body: " + err.Error()})
+		return
+	}
+	level, err := logger.ParseLevel(body.Level)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	previous := logger.GetLevel()
+	logger.SetLevel(level)
+	logger.New().Info("log level changed",
+		zap.Stringer("log.level.previous", previous),
+		zap.Stringer("log.level", level),
+	)
+	writeJSON(w, http.StatusOK, LogLevel{Level: level.String()})
+}
@@ -0,0 +1,135 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/system/manifest"
+	"github.com/kiwiworks/rodent/telemetry"
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+type tokenProvider struct{}
+
+func (tokenProvider) Manifest() *auth.Manifest {
+	return &auth.Manifest{Name: "admin-token", SecurityScheme: &huma.SecurityScheme{Type: "http"}}
+}
+
+func (tokenProvider) UserResolver(ctx huma.Context) (*auth.ResolvedUser, error) {
+	if ctx.Header("Authorization") != "Bearer secret" {
+		return nil, errors.Newf("invalid token")
+	}
+	return &auth.ResolvedUser{Username: "ops", IsAdmin: true}, nil
+}
+
+func (tokenProvider) AuthMiddleware(ctx huma.Context, next func(ctx huma.Context)) {
+	next(ctx)
+}
+
+func TestAdminHandlers(t *testing.T) {
+	m := manifest.New("admin-test", "1.2.3")
+	telemetryInstance, err := telemetry.New(m)
+	require.NoError(t, err)
+	server := New(Params{
+		Manifest:  m,
+		Telemetry: telemetryInstance,
+		Auth:      tokenProvider{},
+	})
+	previous := logger.GetLevel()
+	t.Cleanup(func() { logger.SetLevel(previous) })
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		token  string
+		status int
+		check  func(r *require.Assertions, body []byte)
+	}{
+		{
+			name:   "unauthenticated",
+			method: http.MethodGet,
+			path:   "/manifest",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "manifest",
+			method: http.MethodGet,
+			path:   "/manifest",
+			token:  "secret",
+			status: http.StatusOK,
+			check: func(r *require.Assertions, body []byte) {
+				var response ManifestResponse
+				r.NoError(json.Unmarshal(body, &response))
+				r.Equal("admin-test", response.Manifest.Application)
+				r.Equal("1.2.3", response.Manifest.Version.String())
+				r.NotEmpty(response.Build.GoVersion)
+			},
+		},
+		{
+			name:   "runtime",
+			method: http.MethodGet,
+			path:   "/runtime",
+			token:  "secret",
+			status: http.StatusOK,
+			check: func(r *require.Assertions, body []byte) {
+				var response RuntimeResponse
+				r.NoError(json.Unmarshal(body, &response))
+				r.Positive(response.Goroutines)
+				r.NotEmpty(response.Stats)
+			},
+		},
+		{
+			name:   "invalid log level",
+			method: http.MethodPut,
+			path:   "/log/level",
+			body:   `{"level":"verbose"}`,
+			token:  "secret",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "change log level",
+			method: http.MethodPut,
+			path:   "/log/level",
+			body:   `{"level":"debug"}`,
+			token:  "secret",
+			status: http.StatusOK,
+			check: func(r *require.Assertions, body []byte) {
+				r.JSONEq(`{"level":"debug"}`, string(body))
+				r.Equal(logger.DebugLevel, logger.GetLevel())
+			},
+		},
+		{
+			name:   "pprof index",
+			method: http.MethodGet,
+			path:   "/debug/pprof/",
+			token:  "secret",
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				request.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			recorder := httptest.NewRecorder()
+			server.Handler().ServeHTTP(recorder, request)
+			r.Equal(tt.status, recorder.Code, recorder.Body.String())
+			if tt.check != nil {
+				tt.check(r, recorder.Body.Bytes())
+			}
+		})
+	}
+}
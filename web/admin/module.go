@@ -0,0 +1,14 @@
+package admin
+
+import (
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/lifecycle"
+	"github.com/kiwiworks/rodent/app/module"
+)
+
+func Module() app.Module {
+	return app.NewModule(
+		module.Public(New),
+		module.Service[Server](module.InPhase(lifecycle.PhaseServing)),
+	)
+}
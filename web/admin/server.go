@@ -0,0 +1,114 @@
+package admin
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/app/module"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/system/manifest"
+	"github.com/kiwiworks/rodent/telemetry"
+	"github.com/kiwiworks/rodent/web/auth"
+	"github.com/kiwiworks/rodent/web/server"
+)
+
+type (
+	// Server is the admin HTTP server, it exposes the operational endpoints on their own listener,
+	// away from the public API.
+	Server struct {
+		addr      string
+		mux       *chi.Mux
+		mu        sync.RWMutex
+		server    *http.Server
+		startedAt time.Time
+	}
+	Params struct {
+		fx.In
+		Addr      server.Addr   `name:"admin" optional:"true"`
+		Auth      auth.Provider `name:"admin" optional:"true"`
+		Manifest  *manifest.Manifest
+		Registry  *module.ServiceRegistry `optional:"true"`
+		Telemetry *telemetry.Telemetry    `optional:"true"`
+	}
+)
+
+func New(params Params) *Server {
+	addr := params.Addr
+	if addr == "" {
+		addr = "[::1]:9090"
+	}
+	mux := chi.NewRouter()
+	if params.Auth != nil {
+		mux.Use(authMiddleware(params.Auth))
+	}
+	handlers := &handlers{
+		manifest:  params.Manifest,
+		registry:  params.Registry,
+		telemetry: params.Telemetry,
+	}
+	handlers.mount(mux)
+
+	return &Server{
+		addr: string(addr),
+		mux:  mux,
+	}
+}
+
+func (s *Server) OnStart(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on '%s'", s.addr)
+	}
+	server := &http.Server{
+		Addr:    s.addr,
+		Handler: s.mux,
+	}
+	s.mu.Lock()
+	s.server = server
+	s.startedAt = time.Now()
+	s.mu.Unlock()
+	go func() {
+		log.Info("starting admin server", zap.String("address", s.addr))
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			module.ReportCrash(ctx, errors.Wrapf(err, "admin server listening on '%s' crashed", s.addr))
+		}
+	}()
+	return nil
+}
+
+func (s *Server) OnStop(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	s.mu.RLock()
+	server := s.server
+	s.mu.RUnlock()
+	if server == nil {
+		return nil
+	}
+	log.Info("stopping admin server", zap.String("address", s.addr))
+	return server.Shutdown(ctx)
+}
+
+func (s *Server) Inspect() module.HealthCheckManifest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return module.HealthCheckManifest{
+		Name:        "web.admin",
+		Description: "admin HTTP server listening on " + s.addr,
+		Optional:    true,
+		StartedAt:   s.startedAt,
+	}
+}
+
+// Handler returns the admin routes, mostly useful to serve them from a test server.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
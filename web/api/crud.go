@@ -0,0 +1,676 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/schema/field"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+
+	"github.com/kiwiworks/rodent/database/ent/entity"
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/system/opt"
+	"github.com/kiwiworks/rodent/web/http"
+)
+
+// internalFields are the fields of the rodent mixins which are never exposed, such as `deleted_at` of SoftDeletable.
+var internalFields = []string{"deleted_at", "tenant_id"}
+
+// versionField is the field of mixins.Versioned, the updates set the version they expect on it.
+const versionField = "version"
+
+type (
+	// CRUDOptions configures the operations registered by CRUD.
+	CRUDOptions struct {
+		// List, Get, Create, Update and Delete are the options of each operation, such as their Auth.
+		List   []opt.Option[Options]
+		Get    []opt.Option[Options]
+		Create []opt.Option[Options]
+		Update []opt.Option[Options]
+		Delete []opt.Option[Options]
+		// Hidden are the fields left out of the DTOs, on top of the internal and the sensitive ones.
+		Hidden []string
+		// Writable are the sensitive fields which the create and update requests set, they are never returned.
+		Writable []string
+	}
+	// crud serves the entities T of a generated client, such as *ent.User of client.User.
+	crud[T any] struct {
+		client reflect.Value
+		name   string
+		path   string
+		fields []crudField
+		// publicID is how the public IDs are parsed, an uuid.UUID or a prefixed ID.
+		publicID      *huma.Schema
+		publicIDIndex []int
+		isUUID        bool
+		// versionIndex is the version field of the entities of the Versioned schemas, nil for the others.
+		versionIndex []int
+	}
+	crudField struct {
+		name  string
+		index []int
+		// value is the type the mutations set, the type of the entity field without its pointer when it is nillable.
+		value     reflect.Type
+		required  bool
+		nullable  bool
+		immutable bool
+		// output fields are part of the responses, and input fields are set by the create and update requests.
+		output bool
+		input  bool
+		enums  []any
+	}
+	crudListRequest struct {
+		Limit  int `query:"limit" default:"50" minimum:"1" maximum:"500" doc:"How many resources to return"`
+		Offset int `query:"offset" minimum:"0" doc:"How many resources to skip"`
+	}
+	// crudIDRequest hides the id parameter, it is documented by idParam along with the pattern of the public IDs.
+	crudIDRequest struct {
+		ID string `path:"id" hidden:"true"`
+	}
+	crudCreateRequest struct {
+		Body map[string]json.RawMessage
+	}
+	crudUpdateRequest struct {
+		ID   string `path:"id" hidden:"true"`
+		Body map[string]json.RawMessage
+	}
+	crudPage struct {
+		Items []crudEntity `json:"items"`
+		Total int          `json:"total"`
+	}
+	// crudEntity is the DTO of an entity, its fields are encoded in the order of the schema.
+	crudEntity struct {
+		keys   []string
+		values []any
+	}
+)
+
+func CRUDList(opts ...opt.Option[Options]) opt.Option[CRUDOptions] {
+	return func(opt *CRUDOptions) {
+		opt.List = append(opt.List, opts...)
+	}
+}
+
+func CRUDGet(opts ...opt.Option[Options]) opt.Option[CRUDOptions] {
+	return func(opt *CRUDOptions) {
+		opt.Get = append(opt.Get, opts...)
+	}
+}
+
+func CRUDCreate(opts ...opt.Option[Options]) opt.Option[CRUDOptions] {
+	return func(opt *CRUDOptions) {
+		opt.Create = append(opt.Create, opts...)
+	}
+}
+
+func CRUDUpdate(opts ...opt.Option[Options]) opt.Option[CRUDOptions] {
+	return func(opt *CRUDOptions) {
+		opt.Update = append(opt.Update, opts...)
+	}
+}
+
+func CRUDDelete(opts ...opt.Option[Options]) opt.Option[CRUDOptions] {
+	return func(opt *CRUDOptions) {
+		opt.Delete = append(opt.Delete, opts...)
+	}
+}
+
+// CRUDAll applies the options to the five operations.
+func CRUDAll(opts ...opt.Option[Options]) opt.Option[CRUDOptions] {
+	return func(opt *CRUDOptions) {
+		opt.List = append(opt.List, opts...)
+		opt.Get = append(opt.Get, opts...)
+		opt.Create = append(opt.Create, opts...)
+		opt.Update = append(opt.Update, opts...)
+		opt.Delete = append(opt.Delete, opts...)
+	}
+}
+
+func CRUDHidden(fields ...string) opt.Option[CRUDOptions] {
+	return func(opt *CRUDOptions) {
+		opt.Hidden = append(opt.Hidden, fields...)
+	}
+}
+
+// CRUDWritable lets the create and update requests set the given sensitive fields, such as a password hashed by
+// a hook of the schema, the sensitive fields are read-only otherwise.
+func CRUDWritable(fields ...string) opt.Option[CRUDOptions] {
+	return func(opt *CRUDOptions) {
+		opt.Writable = append(opt.Writable, fields...)
+	}
+}
+
+// CRUD serves the list, get, create, update and delete operations of the entities T of a generated client,
+// whose schema uses the mixins.Resource, the rows are addressed by their public ID.
+// The DTOs are derived from the schema: the internal ID, the internal fields of the mixins and the sensitive fields
+// are never exposed, and only the fields declared by the schema itself can be set, but for the sensitive ones,
+// unless they are CRUDWritable.
+// The soft-deletion, the tenancy and the versions of the mixins apply, the updates and the deletions honor the
+// If-Match header.
+//
+//	module.Handlers(func(client *ent.Client) *api.Handler {
+//		return api.CRUD[*ent.User](client.User, schema.User{}, "/users",
+//			api.CRUDAll(api.Auth("jwt")),
+//			api.CRUDDelete(api.Oauth2("google", "admin")),
+//		)
+//	})
+func CRUD[T any](client any, schema ent.Interface, path string, opts ...opt.Option[CRUDOptions]) *Handler {
+	options := CRUDOptions{}
+	opt.Apply(&options, opts...)
+	c := errors.Must(newCRUD[T](client, schema, strings.TrimSuffix(path, "/"), options))
+	tags := Tags(c.name)
+	handlers := []*Handler{
+		NewHandler(http.GET, c.path, crudHandler(c.list), append([]opt.Option[Options]{
+			tags,
+			OperationID("list" + plural(c.name)),
+			Operation(c.pageBody),
+		}, options.List...)...),
+		NewHandler(http.POST, c.path, crudHandler(c.create), append([]opt.Option[Options]{
+			tags,
+			OperationID("create" + c.name),
+			Conditional(),
+			Operation(c.createBody),
+		}, options.Create...)...),
+		NewHandler(http.GET, c.path+"/{id}", crudHandler(c.get), append([]opt.Option[Options]{
+			tags,
+			OperationID("get" + c.name),
+			Conditional(),
+			Operation(c.idParam),
+			Operation(c.entityBody(200)),
+		}, options.Get...)...),
+		NewHandler(http.PATCH, c.path+"/{id}", crudHandler(c.update), append([]opt.Option[Options]{
+			tags,
+			OperationID("update" + c.name),
+			Conditional(),
+			Operation(c.idParam),
+			Operation(c.updateBody),
+		}, options.Update...)...),
+		NewHandler(http.DELETE, c.path+"/{id}", crudHandler(c.delete), append([]opt.Option[Options]{
+			tags,
+			OperationID("delete" + c.name),
+			Conditional(),
+			Operation(c.idParam),
+		}, options.Delete...)...),
+	}
+	return &Handler{
+		Options: handlers[0].Options,
+		Mount: func(api huma.API, config Config) {
+			for _, handler := range handlers {
+				handler.Mount(api, config)
+			}
+		},
+	}
+}
+
+func newCRUD[T any](client any, schema ent.Interface, path string, options CRUDOptions) (*crud[T], error) {
+	entityType := reflect.TypeFor[T]()
+	if entityType.Kind() != reflect.Pointer || entityType.Elem().Kind() != reflect.Struct {
+		return nil, errors.Newf("CRUD entities must be pointers to the generated structs, not %s", entityType)
+	}
+	value := reflect.ValueOf(client)
+	for _, method := range []string{"Query", "Create", "UpdateOne", "DeleteOne"} {
+		if !value.MethodByName(method).IsValid() {
+			return nil, errors.Newf("CRUD client %T has no %s method, it must be a generated client such as client.User", client, method)
+		}
+	}
+	c := &crud[T]{
+		client: value,
+		name:   entity.Name[T](),
+		path:   path,
+	}
+	own := make(map[string]bool)
+	for _, f := range schema.Fields() {
+		own[f.Descriptor().Name] = true
+	}
+	descriptors := make([]*field.Descriptor, 0)
+	for _, m := range schema.Mixin() {
+		for _, f := range m.Fields() {
+			descriptors = append(descriptors, f.Descriptor())
+		}
+	}
+	for _, f := range schema.Fields() {
+		descriptors = append(descriptors, f.Descriptor())
+	}
+	for _, descriptor := range descriptors {
+		structField, ok := fieldOf(entityType.Elem(), descriptor.Name)
+		if !ok {
+			return nil, errors.Newf("entity %s has no %s field", c.name, descriptor.Name)
+		}
+		if descriptor.Name == entity.PublicIDField {
+			if err := c.setPublicID(structField, descriptor); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if descriptor.Name == versionField {
+			c.versionIndex = structField.Index
+		}
+		hidden := descriptor.Sensitive || slices.Contains(internalFields, descriptor.Name) || slices.Contains(options.Hidden, descriptor.Name)
+		valueType := structField.Type
+		if descriptor.Nillable {
+			valueType = valueType.Elem()
+		}
+		input := own[descriptor.Name] && !slices.Contains(options.Hidden, descriptor.Name) &&
+			(!descriptor.Sensitive || slices.Contains(options.Writable, descriptor.Name))
+		f := crudField{
+			name:      descriptor.Name,
+			index:     structField.Index,
+			value:     valueType,
+			required:  input && !descriptor.Optional && !descriptor.Nillable && descriptor.Default == nil,
+			nullable:  descriptor.Optional || descriptor.Nillable,
+			immutable: descriptor.Immutable,
+			output:    !hidden,
+			input:     input,
+		}
+		for _, enum := range descriptor.Enums {
+			f.enums = append(f.enums, enum.V)
+		}
+		c.fields = append(c.fields, f)
+	}
+	if c.publicID == nil {
+		return nil, errors.Newf("schema of %s has no %s field, it must use the mixins.Resource", c.name, entity.PublicIDField)
+	}
+	return c, nil
+}
+
+// fieldOf finds the struct field of an ent field, the sensitive fields have no json tag to match.
+func fieldOf(t reflect.Type, name string) (reflect.StructField, bool) {
+	normalized := strings.ReplaceAll(name, "_", "")
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag == name {
+			return f, true
+		}
+		if strings.EqualFold(f.Name, normalized) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// setPublicID documents the public IDs, the pattern of the prefixed IDs is found from an ID generated by their default.
+func (c *crud[T]) setPublicID(structField reflect.StructField, descriptor *field.Descriptor) error {
+	c.publicID = &huma.Schema{Type: huma.TypeString}
+	c.publicIDIndex = structField.Index
+	if structField.Type == reflect.TypeFor[uuid.UUID]() {
+		c.isUUID = true
+		c.publicID.Format = "uuid"
+		return nil
+	}
+	generate, ok := descriptor.Default.(func() string)
+	if !ok {
+		return nil
+	}
+	prefix, _, found := strings.Cut(generate(), "_")
+	if found && entity.ValidatePrefix(prefix) == nil {
+		c.publicID.Pattern = entity.PrefixedIDPattern(prefix)
+	}
+	return nil
+}
+
+// parseID converts the id parameter into the type of the public IDs, the malformed ones cannot be found.
+func (c *crud[T]) parseID(id string) (any, error) {
+	if !c.isUUID {
+		return id, nil
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.NotFoundError{Entity: c.name, RequestedIdentifier: id}
+	}
+	return parsed, nil
+}
+
+func (c *crud[T]) query() any {
+	return c.client.MethodByName("Query").Call(nil)[0].Interface()
+}
+
+func (c *crud[T]) find(ctx context.Context, id string) (T, error) {
+	var zero T
+	publicID, err := c.parseID(id)
+	if err != nil {
+		return zero, err
+	}
+	query, ok := c.query().(interface {
+		Only(ctx context.Context) (T, error)
+	})
+	if !ok {
+		return zero, errors.Newf("the query of %s does not return %T", c.name, zero)
+	}
+	return entity.FindByPublicID[T](ctx, query, publicID)
+}
+
+func (c *crud[T]) list(ctx context.Context, request *crudListRequest) (*Response[crudPage], error) {
+	q := reflect.ValueOf(c.query())
+	total := q.MethodByName("Clone").Call(nil)[0].MethodByName("Count").Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if err, _ := total[1].Interface().(error); err != nil {
+		return nil, errors.Wrapf(err, "failed to count %s", plural(c.name))
+	}
+	entity.Order(q.Interface(), sql.OrderByField("id").ToFunc())
+	q = q.MethodByName("Limit").Call([]reflect.Value{reflect.ValueOf(request.Limit)})[0]
+	q = q.MethodByName("Offset").Call([]reflect.Value{reflect.ValueOf(request.Offset)})[0]
+	all := q.MethodByName("All").Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if err, _ := all[1].Interface().(error); err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", plural(c.name))
+	}
+	page := crudPage{Items: make([]crudEntity, 0, all[0].Len()), Total: int(total[0].Int())}
+	for i := 0; i < all[0].Len(); i++ {
+		page.Items = append(page.Items, c.dto(all[0].Index(i)))
+	}
+	return Ok(page)
+}
+
+func (c *crud[T]) get(ctx context.Context, request *crudIDRequest) (*Response[crudEntity], error) {
+	row, err := c.find(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	return Ok(c.dto(reflect.ValueOf(row)))
+}
+
+func (c *crud[T]) create(ctx context.Context, request *crudCreateRequest) (*Response[crudEntity], error) {
+	builder := c.client.MethodByName("Create").Call(nil)[0]
+	if err := c.apply(builder, request.Body, false); err != nil {
+		return nil, err
+	}
+	return c.save(ctx, builder)
+}
+
+func (c *crud[T]) update(ctx context.Context, request *crudUpdateRequest) (*Response[crudEntity], error) {
+	row, err := c.find(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	builder := c.client.MethodByName("UpdateOne").Call([]reflect.Value{reflect.ValueOf(row)})[0]
+	if err = c.apply(builder, request.Body, true); err != nil {
+		return nil, err
+	}
+	// the Versioned hook checks the version set on the mutation, only this update expects the If-Match version
+	if version, ok := c.ifMatch(ctx); ok {
+		mutation, err := mutationOf(builder)
+		if err != nil {
+			return nil, err
+		}
+		if err = mutation.SetField(versionField, version); err != nil {
+			return nil, errors.Wrapf(err, "failed to expect version %d of %s %s", version, c.name, request.ID)
+		}
+	}
+	return c.save(ctx, builder)
+}
+
+func (c *crud[T]) delete(ctx context.Context, request *crudIDRequest) (*struct{}, error) {
+	row, err := c.find(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	builder := c.client.MethodByName("DeleteOne").Call([]reflect.Value{reflect.ValueOf(row)})[0]
+	version, conditional := c.ifMatch(ctx)
+	if conditional {
+		if actual := int(reflect.ValueOf(row).Elem().FieldByIndex(c.versionIndex).Int()); actual != version {
+			return nil, errors.Conflict(c.name, request.ID, version, actual)
+		}
+		// the version is checked again by the deletion, in case the row moved on since it was read
+		if !entity.Where(builder.Interface(), sql.FieldEQ(versionField, version)) {
+			return nil, errors.Newf("the deletion of %s cannot check the version", c.name)
+		}
+	}
+	out := builder.MethodByName("Exec").Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if err, _ := out[0].Interface().(error); err != nil {
+		if conditional && entity.IsNotFound(err) {
+			return nil, errors.Conflict(c.name, request.ID, version, 0)
+		}
+		return nil, errors.Wrapf(err, "failed to delete %s %s", c.name, request.ID)
+	}
+	return nil, nil
+}
+
+// ifMatch returns the version required by the If-Match header, which the schemas that are not Versioned ignore.
+func (c *crud[T]) ifMatch(ctx context.Context) (int, bool) {
+	if c.versionIndex == nil {
+		return 0, false
+	}
+	return IfMatch(ctx)
+}
+
+// mutationOf returns the mutation of a generated builder.
+func mutationOf(builder reflect.Value) (ent.Mutation, error) {
+	mutation, ok := builder.MethodByName("Mutation").Call(nil)[0].Interface().(ent.Mutation)
+	if !ok {
+		return nil, errors.Newf("the builder %s has no mutation", builder.Type())
+	}
+	return mutation, nil
+}
+
+// apply sets the fields of the body on the mutation of the builder, null clears the optional fields of an update.
+func (c *crud[T]) apply(builder reflect.Value, body map[string]json.RawMessage, update bool) error {
+	mutation, err := mutationOf(builder)
+	if err != nil {
+		return err
+	}
+	for _, f := range c.fields {
+		raw, ok := body[f.name]
+		if !ok || !f.input || update && f.immutable {
+			continue
+		}
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if update && f.nullable {
+				if err := mutation.ClearField(f.name); err != nil {
+					return errors.Wrapf(err, "failed to clear %s", f.name)
+				}
+			}
+			continue
+		}
+		value := reflect.New(f.value)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return huma.Error422UnprocessableEntity(fmt.Sprintf("invalid %s", f.name), err)
+		}
+		if err := mutation.SetField(f.name, value.Elem().Interface()); err != nil {
+			return errors.Wrapf(err, "failed to set %s", f.name)
+		}
+	}
+	return nil
+}
+
+func (c *crud[T]) save(ctx context.Context, builder reflect.Value) (*Response[crudEntity], error) {
+	out := builder.MethodByName("Save").Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, errors.Wrapf(err, "failed to save %s", c.name)
+	}
+	return Ok(c.dto(out[0]))
+}
+
+// dto exposes the public ID as the id of the entity, followed by its output fields.
+func (c *crud[T]) dto(row reflect.Value) crudEntity {
+	row = row.Elem()
+	dto := crudEntity{
+		keys:   []string{"id"},
+		values: []any{row.FieldByIndex(c.publicIDIndex).Interface()},
+	}
+	for _, f := range c.fields {
+		if f.output {
+			dto.keys = append(dto.keys, f.name)
+			dto.values = append(dto.values, row.FieldByIndex(f.index).Interface())
+		}
+	}
+	return dto
+}
+
+func (e crudEntity) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString("{")
+	for i, key := range e.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		encoded, err := json.Marshal(e.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buffer.WriteString(fmt.Sprintf("%q:", key))
+		buffer.Write(encoded)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// etagVersion is the version of the entities of the Versioned schemas.
+func (e crudEntity) etagVersion() (int, bool) {
+	for i, key := range e.keys {
+		if key == versionField {
+			version, ok := e.values[i].(int)
+			return version, ok
+		}
+	}
+	return 0, false
+}
+
+// schema registers the schema of the DTOs under the name, and returns a reference to it.
+// The DTOs declare the `$schema` property themselves, huma.SchemaLinkTransformer only adds it to the Go types.
+func (c *crud[T]) schema(api huma.API, name string, build func(registry huma.Registry) *huma.Schema) *huma.Schema {
+	registry := api.OpenAPI().Components.Schemas
+	if _, ok := registry.Map()[name]; !ok {
+		s := build(registry)
+		s.Properties["$schema"] = &huma.Schema{
+			Type:        huma.TypeString,
+			Format:      "uri",
+			Description: "A URL to the JSON Schema for this object.",
+			ReadOnly:    true,
+		}
+		s.PrecomputeMessages()
+		registry.Map()[name] = s
+	}
+	return &huma.Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (c *crud[T]) entitySchema(api huma.API) *huma.Schema {
+	return c.schema(api, c.name, func(registry huma.Registry) *huma.Schema {
+		s := &huma.Schema{
+			Type:       huma.TypeObject,
+			Properties: map[string]*huma.Schema{"id": c.publicID},
+			Required:   []string{"id"},
+		}
+		for _, f := range c.fields {
+			if f.output {
+				s.Properties[f.name] = c.fieldSchema(registry, f)
+			}
+		}
+		return s
+	})
+}
+
+func (c *crud[T]) fieldSchema(registry huma.Registry, f crudField) *huma.Schema {
+	s := *registry.Schema(f.value, true, c.name+f.name)
+	s.Nullable = f.nullable
+	if len(f.enums) > 0 {
+		s.Enum = f.enums
+	}
+	return &s
+}
+
+func (c *crud[T]) inputSchema(api huma.API, update bool) *huma.Schema {
+	name := "Create" + c.name + "Body"
+	if update {
+		name = "Update" + c.name + "Body"
+	}
+	return c.schema(api, name, func(registry huma.Registry) *huma.Schema {
+		s := &huma.Schema{
+			Type:                 huma.TypeObject,
+			Properties:           map[string]*huma.Schema{},
+			AdditionalProperties: false,
+		}
+		for _, f := range c.fields {
+			if !f.input || update && f.immutable {
+				continue
+			}
+			s.Properties[f.name] = c.fieldSchema(registry, f)
+			if !update && f.required {
+				s.Required = append(s.Required, f.name)
+			}
+		}
+		return s
+	})
+}
+
+func (c *crud[T]) idParam(_ huma.API, op *huma.Operation) {
+	op.Parameters = append(op.Parameters, &huma.Param{
+		Name:        "id",
+		In:          "path",
+		Description: "The public ID of the " + c.name,
+		Required:    true,
+		Schema:      c.publicID,
+	})
+}
+
+func (c *crud[T]) entityBody(status int) func(api huma.API, op *huma.Operation) {
+	return func(api huma.API, op *huma.Operation) {
+		op.DefaultStatus = status
+		op.Responses = map[string]*huma.Response{
+			fmt.Sprint(status): {
+				Description: c.name,
+				Content: map[string]*huma.MediaType{
+					"application/json": {Schema: c.entitySchema(api)},
+				},
+			},
+		}
+	}
+}
+
+func (c *crud[T]) pageBody(api huma.API, op *huma.Operation) {
+	op.Responses = map[string]*huma.Response{
+		"200": {
+			Description: "A page of " + plural(c.name),
+			Content: map[string]*huma.MediaType{
+				"application/json": {Schema: c.schema(api, c.name+"Page", func(registry huma.Registry) *huma.Schema {
+					return &huma.Schema{
+						Type: huma.TypeObject,
+						Properties: map[string]*huma.Schema{
+							"items": {Type: huma.TypeArray, Items: c.entitySchema(api)},
+							"total": {Type: huma.TypeInteger},
+						},
+						Required: []string{"items", "total"},
+					}
+				})},
+			},
+		},
+	}
+}
+
+func (c *crud[T]) createBody(api huma.API, op *huma.Operation) {
+	c.entityBody(201)(api, op)
+	op.RequestBody = &huma.RequestBody{
+		Required: true,
+		Content: map[string]*huma.MediaType{
+			"application/json": {Schema: c.inputSchema(api, false)},
+		},
+	}
+}
+
+func (c *crud[T]) updateBody(api huma.API, op *huma.Operation) {
+	c.entityBody(200)(api, op)
+	op.RequestBody = &huma.RequestBody{
+		Required: true,
+		Content: map[string]*huma.MediaType{
+			"application/json": {Schema: c.inputSchema(api, true)},
+		},
+	}
+}
+
+// plural is the naive plural of the entity names, such as `Users` or `Companies`.
+func plural(name string) string {
+	switch {
+	case strings.HasSuffix(name, "y") && !strings.HasSuffix(name, "ey"):
+		return strings.TrimSuffix(name, "y") + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
@@ -0,0 +1,188 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/ent/mixins"
+	"github.com/kiwiworks/rodent/internal/testent"
+	"github.com/kiwiworks/rodent/internal/testent/enttest"
+	_ "github.com/kiwiworks/rodent/internal/testent/runtime"
+	"github.com/kiwiworks/rodent/internal/testent/schema"
+	"github.com/kiwiworks/rodent/internal/testent/user"
+	"github.com/kiwiworks/rodent/web/api"
+)
+
+// crudAPI serves the users and the orgs of an in-memory SQLite database, private to the test.
+func crudAPI(t *testing.T) (humatest.TestAPI, *testent.Client) {
+	t.Helper()
+	client := enttest.Open(t, dialect.SQLite, "file:"+t.Name()+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { _ = client.Close() })
+	_, testAPI := humatest.New(t)
+	config := *api.DefaultConfig()
+	api.CRUD[*testent.User](client.User, schema.User{}, "/users", api.CRUDWritable("password")).Mount(testAPI, config)
+	api.CRUD[*testent.Org](client.Org, schema.Org{}, "/orgs").Mount(testAPI, config)
+	return testAPI, client
+}
+
+func decode(t *testing.T, body []byte) map[string]any {
+	t.Helper()
+	decoded := make(map[string]any)
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	return decoded
+}
+
+func createUser(t *testing.T, testAPI humatest.TestAPI, email string) string {
+	t.Helper()
+	response := testAPI.Post("/users", map[string]any{"email": email, "password": "secret"})
+	require.Equal(t, 201, response.Code, response.Body.String())
+	return decode(t, response.Body.Bytes())["id"].(string)
+}
+
+func TestCRUDCreateAndGet(t *testing.T) {
+	r := require.New(t)
+	testAPI, client := crudAPI(t)
+
+	response := testAPI.Post("/users", map[string]any{"email": "ada@example.com", "password": "secret", "nickname": "ada"})
+	r.Equal(201, response.Code, response.Body.String())
+	r.Equal(`"1"`, response.Header().Get("ETag"))
+	created := decode(t, response.Body.Bytes())
+	r.Regexp(`^usr_`, created["id"])
+	r.Equal("ada@example.com", created["email"])
+	r.Equal("member", created["role"])
+	r.NotContains(created, "password", "the sensitive fields are never returned")
+	r.NotContains(created, "deleted_at")
+	r.Equal("secret", client.User.Query().OnlyX(context.Background()).Password, "the writable sensitive fields are set")
+
+	response = testAPI.Get("/users/" + created["id"].(string))
+	r.Equal(200, response.Code, response.Body.String())
+	r.Equal(created, decode(t, response.Body.Bytes()))
+
+	response = testAPI.Post("/users", map[string]any{"email": "ada@example.com"})
+	r.Equal(409, response.Code, "a violated constraint is a conflict: %s", response.Body.String())
+	response = testAPI.Post("/users", map[string]any{"email": ""})
+	r.Equal(422, response.Code, "an invalid field is unprocessable: %s", response.Body.String())
+}
+
+func TestCRUDReadOnlySensitiveFields(t *testing.T) {
+	r := require.New(t)
+	client := enttest.Open(t, dialect.SQLite, "file:"+t.Name()+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { _ = client.Close() })
+	_, testAPI := humatest.New(t)
+	api.CRUD[*testent.User](client.User, schema.User{}, "/users").Mount(testAPI, *api.DefaultConfig())
+
+	response := testAPI.Post("/users", map[string]any{"email": "ada@example.com", "password": "secret"})
+	r.Equal(422, response.Code, "the sensitive fields are not writable by default: %s", response.Body.String())
+	r.Zero(client.User.Query().CountX(context.Background()))
+}
+
+func TestCRUDNotFound(t *testing.T) {
+	testAPI, _ := crudAPI(t)
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "unknown prefixed id", path: "/users/usr_01HZX3C8V6J0T9Q2K8M1N4R5S7"},
+		{name: "malformed prefixed id", path: "/users/nope"},
+		{name: "unknown uuid", path: "/orgs/0190b2b4-5d3c-7a8e-9f10-111213141516"},
+		{name: "malformed uuid", path: "/orgs/nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, method := range []string{"GET", "PATCH", "DELETE"} {
+				var args []any
+				if method == "PATCH" {
+					args = append(args, map[string]any{})
+				}
+				response := testAPI.Do(method, tt.path, args...)
+				require.Equal(t, 404, response.Code, "%s %s", method, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestCRUDList(t *testing.T) {
+	r := require.New(t)
+	testAPI, _ := crudAPI(t)
+	ids := []string{createUser(t, testAPI, "ada@example.com"), createUser(t, testAPI, "bob@example.com"), createUser(t, testAPI, "eve@example.com")}
+	r.Equal(204, testAPI.Delete("/users/"+ids[1]).Code)
+
+	response := testAPI.Get("/users?limit=1&offset=1")
+	r.Equal(200, response.Code, response.Body.String())
+	var page struct {
+		Items []map[string]any `json:"items"`
+		Total int              `json:"total"`
+	}
+	r.NoError(json.Unmarshal(response.Body.Bytes(), &page))
+	r.Equal(2, page.Total, "the soft-deleted rows are not listed")
+	r.Len(page.Items, 1)
+	r.Equal(ids[2], page.Items[0]["id"])
+}
+
+func TestCRUDUpdate(t *testing.T) {
+	r := require.New(t)
+	testAPI, _ := crudAPI(t)
+	id := createUser(t, testAPI, "ada@example.com")
+
+	response := testAPI.Patch("/users/"+id, "If-Match: \"1\"", map[string]any{"nickname": "ada", "role": "admin"})
+	r.Equal(200, response.Code, response.Body.String())
+	r.Equal(`"2"`, response.Header().Get("ETag"))
+	updated := decode(t, response.Body.Bytes())
+	r.Equal("ada", updated["nickname"])
+	r.Equal("admin", updated["role"])
+
+	response = testAPI.Patch("/users/"+id, "If-Match: \"1\"", map[string]any{"nickname": "stale"})
+	r.Equal(412, response.Code, response.Body.String())
+
+	response = testAPI.Patch("/users/"+id, map[string]any{"nickname": nil, "email": "changed@example.com"})
+	r.Equal(200, response.Code, response.Body.String())
+	r.Equal(`"3"`, response.Header().Get("ETag"))
+	updated = decode(t, response.Body.Bytes())
+	r.Nil(updated["nickname"], "null clears the optional fields")
+	r.Equal("changed@example.com", updated["email"])
+}
+
+func TestCRUDDelete(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	testAPI, client := crudAPI(t)
+	id := createUser(t, testAPI, "ada@example.com")
+
+	r.Equal(412, testAPI.Delete("/users/"+id, "If-Match: \"2\"").Code)
+	response := testAPI.Delete("/users/"+id, "If-Match: \"1\"")
+	r.Equal(204, response.Code, response.Body.String())
+	r.Equal(404, testAPI.Get("/users/"+id).Code, "the soft-deleted rows are not found")
+	deleted := client.User.Query().Where(user.PublicID(id)).OnlyX(mixins.IncludeDeleted(ctx))
+	r.False(deleted.DeletedAt.IsZero(), "the row is soft-deleted")
+	r.Equal(404, testAPI.Delete("/users/"+id).Code)
+}
+
+func TestCRUDDeleteChecksTheVersionAtomically(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	testAPI, client := crudAPI(t)
+	id := createUser(t, testAPI, "ada@example.com")
+
+	// another request updates the row after the handler read it, right before the soft-deletion runs
+	raced := false
+	client.User.Use(func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			if _, deleting := m.Field("deleted_at"); deleting && !raced {
+				raced = true
+				client.User.Update().Where(user.PublicID(id)).SetNickname("moved on").ExecX(ctx)
+			}
+			return next.Mutate(ctx, m)
+		})
+	})
+	r.Equal(412, testAPI.Delete("/users/"+id, "If-Match: \"1\"").Code)
+	r.True(raced)
+	remaining := client.User.Query().Where(user.PublicID(id)).OnlyX(ctx)
+	r.Equal("moved on", *remaining.Nickname, "the row is not deleted")
+}
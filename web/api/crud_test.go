@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/mixin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/database/ent/entity"
+)
+
+type (
+	accountMixin struct {
+		mixin.Schema
+	}
+	accountSchema struct {
+		ent.Schema
+	}
+	// Account is shaped like a generated entity.
+	Account struct {
+		ID           int     `json:"id,omitempty"`
+		PublicID     string  `json:"public_id,omitempty"`
+		TenantID     string  `json:"tenant_id,omitempty"`
+		Email        string  `json:"email,omitempty"`
+		PasswordHash string  `json:"-"`
+		Nickname     *string `json:"nickname,omitempty"`
+		Role         string  `json:"role,omitempty"`
+		CreatedBy    string  `json:"created_by,omitempty"`
+	}
+	accountClient struct{}
+)
+
+func (accountMixin) Fields() []ent.Field {
+	return []ent.Field{
+		field.String(entity.PublicIDField).DefaultFunc(func() string { return entity.NewPrefixedID("acc") }),
+		field.String("tenant_id").Immutable(),
+		field.String("created_by").Optional().Immutable(),
+	}
+}
+
+func (accountSchema) Mixin() []ent.Mixin {
+	return []ent.Mixin{accountMixin{}}
+}
+
+func (accountSchema) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("email").Immutable(),
+		field.String("password_hash").Sensitive(),
+		field.String("nickname").Optional().Nillable(),
+		field.Enum("role").Values("admin", "member").Default("member"),
+	}
+}
+
+func (accountClient) Query()     {}
+func (accountClient) Create()    {}
+func (accountClient) UpdateOne() {}
+func (accountClient) DeleteOne() {}
+
+func TestNewCRUD(t *testing.T) {
+	r := require.New(t)
+	c, err := newCRUD[*Account](accountClient{}, accountSchema{}, "/accounts", CRUDOptions{Hidden: []string{"role"}})
+	r.NoError(err)
+	r.Equal("Account", c.name)
+	r.Equal(entity.PrefixedIDPattern("acc"), c.publicID.Pattern)
+
+	fields := make(map[string]crudField)
+	for _, f := range c.fields {
+		fields[f.name] = f
+	}
+	tests := []struct {
+		name     string
+		output   bool
+		input    bool
+		required bool
+		nullable bool
+	}{
+		{name: "tenant_id"},
+		{name: "created_by", output: true, nullable: true},
+		{name: "email", output: true, input: true, required: true},
+		{name: "password_hash"},
+		{name: "nickname", output: true, input: true, nullable: true},
+		{name: "role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := fields[tt.name]
+			require.True(t, ok)
+			require.Equal(t, tt.output, f.output, "output")
+			require.Equal(t, tt.input, f.input, "input")
+			require.Equal(t, tt.required, f.required, "required")
+			require.Equal(t, tt.nullable, f.nullable, "nullable")
+		})
+	}
+	r.Equal([]any{"admin", "member"}, fields["role"].enums)
+	r.True(fields["email"].immutable)
+
+	c, err = newCRUD[*Account](accountClient{}, accountSchema{}, "/accounts", CRUDOptions{Writable: []string{"password_hash"}})
+	r.NoError(err)
+	for _, f := range c.fields {
+		if f.name == "password_hash" {
+			r.True(f.input, "the writable sensitive fields are set by the requests")
+			r.True(f.required)
+			r.False(f.output, "the writable sensitive fields are never returned")
+		}
+	}
+
+	_, err = newCRUD[*Account](struct{}{}, accountSchema{}, "/accounts", CRUDOptions{})
+	r.Error(err)
+	_, err = newCRUD[Account](accountClient{}, accountSchema{}, "/accounts", CRUDOptions{})
+	r.Error(err)
+}
+
+func TestCRUDEntity(t *testing.T) {
+	r := require.New(t)
+	encoded, err := json.Marshal(crudEntity{
+		keys:   []string{"id", "version", "email"},
+		values: []any{"acc_1", 3, "a@b.c"},
+	})
+	r.NoError(err)
+	r.Equal(`{"id":"acc_1","version":3,"email":"a@b.c"}`, string(encoded))
+
+	version, ok := versionOf(&Response[crudEntity]{Body: crudEntity{keys: []string{"version"}, values: []any{3}}})
+	r.True(ok)
+	r.Equal(3, version)
+	_, ok = versionOf(&Response[crudEntity]{Body: crudEntity{keys: []string{"id"}, values: []any{"acc_1"}}})
+	r.False(ok)
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		name   string
+		plural string
+	}{
+		{name: "User", plural: "Users"},
+		{name: "Company", plural: "Companies"},
+		{name: "Key", plural: "Keys"},
+		{name: "Address", plural: "Addresses"},
+		{name: "Box", plural: "Boxes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.plural, plural(tt.name))
+		})
+	}
+}
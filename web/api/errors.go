@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/kiwiworks/rodent/database/ent/entity"
+	"github.com/kiwiworks/rodent/errors"
+)
+
+// conflictStatus answers the concurrent modifications with a 412 Precondition Failed when the request carried
+// an If-Match header, and with a 409 Conflict when the version came from elsewhere, such as the body.
+func conflictStatus(ctx context.Context, err error) error {
+	if !errors.IsConflict(err) {
+		return err
+	}
+	if _, ok := IfMatch(ctx); ok {
+		return huma.Error412PreconditionFailed("the resource was modified since it was read", cause(err))
+	}
+	return huma.Error409Conflict("the resource was modified since it was read", cause(err))
+}
+
+// crudStatus answers the errors of the generated clients served by CRUD with their status: a 404 Not Found for
+// the missing resources, a 409 Conflict when a constraint is violated and a 422 Unprocessable Entity when a field
+// is invalid, the concurrent modifications are left to conflictStatus.
+func crudStatus(err error) error {
+	switch {
+	case errors.IsNotFound(err):
+		return huma.Error404NotFound(errors.As[errors.NotFoundError](err).Error())
+	case entity.IsConstraintError(err):
+		return huma.Error409Conflict("the resource conflicts with another one", cause(err))
+	case entity.IsValidationError(err):
+		return huma.Error422UnprocessableEntity("the resource is invalid", cause(err))
+	}
+	return err
+}
+
+// crudHandler answers the errors of a CRUD operation with crudStatus.
+func crudHandler[Request any, Response any](
+	impl func(ctx context.Context, request *Request) (*Response, error),
+) func(ctx context.Context, request *Request) (*Response, error) {
+	return func(ctx context.Context, request *Request) (*Response, error) {
+		response, err := impl(ctx, request)
+		if err != nil {
+			return response, crudStatus(err)
+		}
+		return response, nil
+	}
+}
+
+// cause is the error wrapped by errors.Wrapf, whose messages locate the callers and are not meant for the clients.
+func cause(err error) error {
+	for {
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok || len(joined.Unwrap()) == 0 {
+			return err
+		}
+		err = joined.Unwrap()[0]
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/kiwiworks/rodent/web/http"
+)
+
+type (
+	// Versioned is implemented by the responses, or their Body, of the resources carrying a version,
+	// such as the ent schemas using mixins.Versioned, the version is sent as the ETag of the Conditional handlers.
+	Versioned interface {
+		Version() int
+	}
+	conditionalKey struct{}
+	// conditional is the state of the conditional request of an operation.
+	conditional struct {
+		ctx      huma.Context
+		ifMatch  int
+		hasMatch bool
+	}
+)
+
+// ETag formats a version as a strong entity tag.
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseETag parses an entity tag formatted by ETag, weak tags are compared as strong ones.
+func parseETag(tag string) (int, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// IfMatch returns the version required by the If-Match header of the request, a request without the header or
+// with `If-Match: *` does not require any.
+func IfMatch(ctx context.Context) (int, bool) {
+	if state, ok := ctx.Value(conditionalKey{}).(*conditional); ok && state.hasMatch {
+		return state.ifMatch, true
+	}
+	return 0, false
+}
+
+// conditionalMiddleware reads the If-Match header of the mutations, a value which is not a single version cannot
+// match any of them, the request fails right away.
+func conditionalMiddleware(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		state := &conditional{ctx: ctx}
+		header := strings.TrimSpace(ctx.Header("If-Match"))
+		if header != "" && header != "*" && !safe(ctx.Method()) {
+			version, ok := parseETag(header)
+			if !ok {
+				_ = huma.WriteErr(api, ctx, 412, "If-Match does not match the version of the resource")
+				return
+			}
+			state.ifMatch, state.hasMatch = version, true
+		}
+		next(huma.WithValue(ctx, conditionalKey{}, state))
+	}
+}
+
+// safe reports whether the method only reads, the If-Match header of the reads is ignored.
+func safe(method string) bool {
+	return method == http.GET.String() || method == http.HEAD.String()
+}
+
+// setETag sends the version of the response, if it is versioned.
+func setETag(ctx context.Context, response any) {
+	state, ok := ctx.Value(conditionalKey{}).(*conditional)
+	if !ok {
+		return
+	}
+	if version, ok := versionOf(response); ok {
+		state.ctx.SetHeader("ETag", ETag(version))
+	}
+}
+
+func versionOf(response any) (int, bool) {
+	if versioned, ok := response.(Versioned); ok {
+		return versioned.Version(), true
+	}
+	value := reflect.Indirect(reflect.ValueOf(response))
+	if value.Kind() != reflect.Struct {
+		return 0, false
+	}
+	body := value.FieldByName("Body")
+	if !body.IsValid() || (body.Kind() == reflect.Pointer && body.IsNil()) {
+		return 0, false
+	}
+	if versioned, ok := body.Interface().(Versioned); ok {
+		return versioned.Version(), true
+	}
+	if versioned, ok := body.Interface().(interface{ etagVersion() (int, bool) }); ok {
+		return versioned.etagVersion()
+	}
+	return 0, false
+}
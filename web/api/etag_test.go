@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/web/http"
+)
+
+type (
+	document struct {
+		Title    string `json:"title"`
+		Revision int    `json:"version"`
+	}
+	documentRequest struct {
+		Body struct {
+			Title   string `json:"title"`
+			Version int    `json:"version,omitempty"`
+		}
+	}
+)
+
+func (d document) Version() int {
+	return d.Revision
+}
+
+func TestParseETag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		version int
+		ok      bool
+	}{
+		{tag: `"3"`, version: 3, ok: true},
+		{tag: `W/"12"`, version: 12, ok: true},
+		{tag: ETag(7), version: 7, ok: true},
+		{tag: `3`},
+		{tag: `"abc"`},
+		{tag: `"1", "2"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			version, ok := parseETag(tt.tag)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.version, version)
+		})
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	r := require.New(t)
+	current := document{Title: "draft", Revision: 2}
+	_, humaAPI := humatest.New(t)
+	config := *DefaultConfig()
+
+	NewHandler(http.GET, "/document", func(ctx context.Context, _ *http.Empty) (*Response[document], error) {
+		return Ok(current)
+	}, Conditional()).Mount(humaAPI, config)
+	NewHandler(http.PUT, "/document", func(ctx context.Context, request *documentRequest) (*Response[document], error) {
+		expected, ok := IfMatch(ctx)
+		if !ok {
+			expected = request.Body.Version
+		}
+		if expected != current.Revision {
+			return nil, errors.Conflict("document", "1", expected, current.Revision)
+		}
+		current = document{Title: request.Body.Title, Revision: current.Revision + 1}
+		return Ok(current)
+	}, Conditional()).Mount(humaAPI, config)
+	// a handler which does not opt in ignores the If-Match header, and sends no ETag
+	NewHandler(http.POST, "/document", func(ctx context.Context, request *documentRequest) (*Response[document], error) {
+		if _, ok := IfMatch(ctx); ok {
+			return nil, errors.Newf("the If-Match header was read by a handler which is not conditional")
+		}
+		return Ok(document{Title: request.Body.Title, Revision: 1})
+	}).Mount(humaAPI, config)
+
+	tests := []struct {
+		name   string
+		method string
+		args   []any
+		status int
+		etag   string
+	}{
+		{name: "get emits the etag", method: "GET", status: 200, etag: `"2"`},
+		{name: "stale if-match", method: "PUT", args: []any{"If-Match: \"1\"", map[string]any{"title": "a"}}, status: 412},
+		{name: "malformed if-match", method: "PUT", args: []any{"If-Match: nope", map[string]any{"title": "a"}}, status: 412},
+		{name: "stale body version", method: "PUT", args: []any{map[string]any{"title": "a", "version": 1}}, status: 409},
+		{name: "matching if-match", method: "PUT", args: []any{"If-Match: \"2\"", map[string]any{"title": "b"}}, status: 200, etag: `"3"`},
+		{name: "not conditional", method: "POST", args: []any{"If-Match: nope", map[string]any{"title": "c"}}, status: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := humatest.Wrap(t, humaAPI)
+			response := api.Do(tt.method, "/document", tt.args...)
+			r.Equal(tt.status, response.Code, response.Body.String())
+			r.Equal(tt.etag, response.Header().Get("ETag"))
+		})
+	}
+}
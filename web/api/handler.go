@@ -31,6 +31,10 @@ type (
 		AuthProviders   []string
 		OAuth2Providers map[string][]string
 		Description     string
+		// Conditional handles the If-Match header of the mutations, and sends the ETag of the Versioned responses.
+		Conditional bool
+		// Customizers adjust the operation before it is registered, such as to document a body its type does not describe.
+		Customizers []func(api huma.API, op *huma.Operation)
 	}
 )
 
@@ -82,19 +86,29 @@ func NewHandler[Request any, Response any](
 				Tags:        options.Tags,
 				Security:    []map[string][]string{},
 			}
+			if options.Conditional {
+				op.Middlewares = append(op.Middlewares, conditionalMiddleware(api))
+			}
 			for _, authProvider := range options.AuthProviders {
 				op.Security = append(op.Security, map[string][]string{authProvider: {}})
 			}
 			for oauth2provider, scopes := range options.OAuth2Providers {
 				op.Security = append(op.Security, map[string][]string{oauth2provider: scopes})
 			}
+			for _, customize := range options.Customizers {
+				customize(api, &op)
+			}
 			huma.Register(api, op, func(ctx context.Context, i *Request) (*Response, error) {
 				log := logger.FromContext(ctx).With(props.Oas3OperationId(options.OperationId))
 				response, err := impl(ctx, i)
 				if err != nil {
 					log.Error("handler error", zap.Error(err))
+					return response, config.ErrorConverter(conflictStatus(ctx, err))
+				}
+				if response != nil {
+					setETag(ctx, response)
 				}
-				return response, config.ErrorConverter(err)
+				return response, nil
 			})
 		},
 	}
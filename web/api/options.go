@@ -1,6 +1,10 @@
 package api
 
-import "github.com/kiwiworks/rodent/system/opt"
+import (
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/kiwiworks/rodent/system/opt"
+)
 
 func Auth(providerNames ...string) opt.Option[Options] {
 	return func(opt *Options) {
@@ -31,3 +35,18 @@ func OperationID(operationID string) opt.Option[Options] {
 		opt.OperationId = operationID
 	}
 }
+
+// Conditional opts the handler into conditional requests, its implementation reads the If-Match version with
+// IfMatch, and a conflict it returns is answered with a 412 when one was given.
+// The version of a Versioned response is sent as its ETag.
+func Conditional() opt.Option[Options] {
+	return func(opt *Options) {
+		opt.Conditional = true
+	}
+}
+
+func Operation(customize func(api huma.API, op *huma.Operation)) opt.Option[Options] {
+	return func(opt *Options) {
+		opt.Customizers = append(opt.Customizers, customize)
+	}
+}
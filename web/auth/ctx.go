@@ -2,7 +2,10 @@ package auth
 
 import "context"
 
-type resolvedUserKey struct{}
+type (
+	resolvedUserKey struct{}
+	requestKey      struct{}
+)
 
 func InjectUser(ctx context.Context, user *ResolvedUser) context.Context {
 	return context.WithValue(ctx, resolvedUserKey{}, user)
@@ -14,3 +17,15 @@ func UserFromContext(ctx context.Context) *ResolvedUser {
 	}
 	return nil
 }
+
+// InjectRequest marks the context as the one of an HTTP request, the Middleware marks every request it handles,
+// whether a user is authenticated or not.
+func InjectRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestKey{}, true)
+}
+
+// IsRequest reports whether the context is the one of an HTTP request, see InjectRequest.
+func IsRequest(ctx context.Context) bool {
+	request, _ := ctx.Value(requestKey{}).(bool)
+	return request
+}
@@ -39,6 +39,7 @@ func (m *Middleware) writeError(ctx huma.Context, status int, msg string, err er
 func (m *Middleware) Middleware(ctx huma.Context, next func(ctx huma.Context)) {
 	op := ctx.Operation()
 	log := logger.New().With(zap.String("http.method", ctx.Method()), zap.String("http.path", op.Path))
+	ctx = huma.WithContext(ctx, InjectRequest(ctx.Context()))
 
 	if op.Security == nil || len(op.Security) == 0 {
 		next(ctx)
@@ -15,6 +15,8 @@ type ResolvedUser struct {
 	IsEmailVerified bool
 	Avatar          string
 	IsAdmin         bool
+	// Claims are the claims of the token the user was resolved from, when the provider exposes them.
+	Claims map[string]any
 }
 
 func (r *ResolvedUser) ProviderUUID() uuid.UUID {
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+
+	"github.com/kiwiworks/rodent/app/module"
+	"github.com/kiwiworks/rodent/web/api"
+	"github.com/kiwiworks/rodent/web/http"
+)
+
+type Response struct {
+	Status int
+	Body   module.HealthReport
+}
+
+func respond(healthy bool, report module.HealthReport) *Response {
+	status := 200
+	if !healthy {
+		status = 503
+	}
+	return &Response{
+		Status: status,
+		Body:   report,
+	}
+}
+
+// Liveness reports whether the application is alive, it fails as soon as a required service has crashed.
+func Liveness(registry *module.ServiceRegistry) *api.Handler {
+	return api.NewHandler(http.GET, "/healthz", func(ctx context.Context, _ *http.Empty) (*Response, error) {
+		report := registry.Health()
+		return respond(report.Live, report), nil
+	},
+		api.Tags("health"),
+		api.Description("Liveness probe, returns 503 when a required service has crashed"),
+	)
+}
+
+// Readiness reports whether the application can accept traffic, it fails until every required service is healthy.
+func Readiness(registry *module.ServiceRegistry) *api.Handler {
+	return api.NewHandler(http.GET, "/readyz", func(ctx context.Context, _ *http.Empty) (*Response, error) {
+		report := registry.Health()
+		return respond(report.Ready, report), nil
+	},
+		api.Tags("health"),
+		api.Description("Readiness probe, returns 503 until every required service is healthy"),
+	)
+}
@@ -0,0 +1,18 @@
+package health
+
+import (
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/lifecycle"
+	"github.com/kiwiworks/rodent/app/module"
+)
+
+func Module() app.Module {
+	return app.NewModule(
+		module.Public(module.NewServiceRegistry),
+		module.Service[module.ServiceRegistry](module.InPhase(lifecycle.PhaseInfrastructure)),
+		module.Handlers(
+			Liveness,
+			Readiness,
+		),
+	)
+}
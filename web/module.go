@@ -2,8 +2,10 @@ package web
 
 import (
 	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/lifecycle"
 	"github.com/kiwiworks/rodent/app/module"
 	"github.com/kiwiworks/rodent/web/auth"
+	"github.com/kiwiworks/rodent/web/health"
 	"github.com/kiwiworks/rodent/web/server"
 )
 
@@ -17,7 +19,7 @@ func Module() app.Module {
 		module.Public(
 			server.New,
 		),
-		module.Service[server.Server](),
-		module.SubModules(auth.Module),
+		module.Service[server.Server](module.InPhase(lifecycle.PhaseServing)),
+		module.SubModules(auth.Module, health.Module),
 	)
 }
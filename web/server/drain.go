@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// DrainConfig configures how the Server drains its traffic when it stops.
+	DrainConfig struct {
+		// PreStopDelay is how long the server keeps serving new requests once its readiness started failing,
+		// so that load balancers have the time to stop routing traffic to it.
+		PreStopDelay time.Duration
+		// RetryAfter is advertised to the clients whose requests are rejected while draining.
+		RetryAfter time.Duration
+	}
+	inflightRequest struct {
+		Method    string
+		Path      string
+		StartedAt time.Time
+	}
+	// trackedListener accepts trackedConns, so that the hijacked connections leave the drainer once closed.
+	trackedListener struct {
+		net.Listener
+		drainer *drainer
+	}
+	trackedConn struct {
+		net.Conn
+		drainer *drainer
+		once    sync.Once
+	}
+	drainer struct {
+		config        DrainConfig
+		mu            sync.Mutex
+		drainingSince *time.Time
+		rejecting     bool
+		nextID        uint64
+		requests      map[uint64]inflightRequest
+		hijacked      map[net.Conn]struct{}
+		changed       chan struct{}
+	}
+)
+
+func DefaultDrainConfig() *DrainConfig {
+	return &DrainConfig{
+		PreStopDelay: 0,
+		RetryAfter:   time.Second,
+	}
+}
+
+func WithDrain(config DrainConfig) *DrainConfig {
+	return &config
+}
+
+func newDrainer(config DrainConfig) *drainer {
+	return &drainer{
+		config:   config,
+		requests: make(map[uint64]inflightRequest),
+		hijacked: make(map[net.Conn]struct{}),
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// Middleware tracks the in-flight requests, and rejects the new ones once the drainer started rejecting.
+func (d *drainer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, accepted := d.begin(r)
+		if !accepted {
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Retry-After", d.retryAfter())
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		defer d.end(id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Listener wraps the connections of the listener, so that TrackConn forgets the hijacked ones once they are closed.
+func (d *drainer) Listener(listener net.Listener) net.Listener {
+	return &trackedListener{Listener: listener, drainer: d}
+}
+
+// TrackConn keeps track of the hijacked connections, which are no longer managed by the http.Server, until they
+// are closed, which requires the connections to be accepted by the Listener.
+func (d *drainer) TrackConn(conn net.Conn, state http.ConnState) {
+	if state != http.StateHijacked {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.hijacked[conn] = struct{}{}
+}
+
+func (d *drainer) forget(conn net.Conn) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.hijacked, conn)
+}
+
+func (l *trackedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &trackedConn{Conn: conn, drainer: l.drainer}, nil
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.drainer.forget(c)
+	})
+	return c.Conn.Close()
+}
+
+func (d *drainer) begin(r *http.Request) (uint64, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.rejecting {
+		return 0, false
+	}
+	d.nextID++
+	d.requests[d.nextID] = inflightRequest{
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		StartedAt: time.Now(),
+	}
+	return d.nextID, true
+}
+
+func (d *drainer) end(id uint64) {
+	d.mu.Lock()
+	delete(d.requests, id)
+	d.mu.Unlock()
+	select {
+	case d.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (d *drainer) retryAfter() string {
+	seconds := int((d.config.RetryAfter + time.Second - 1) / time.Second)
+	return strconv.Itoa(max(seconds, 1))
+}
+
+// startDraining flags the drainer as draining, it is reported through the server health.
+func (d *drainer) startDraining() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.drainingSince == nil {
+		now := time.Now()
+		d.drainingSince = &now
+	}
+}
+
+func (d *drainer) draining() *time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.drainingSince
+}
+
+// reject makes every new request fail with a 503.
+func (d *drainer) reject() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rejecting = true
+}
+
+func (d *drainer) inflight() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.requests)
+}
+
+// wait blocks until every in-flight request completed, it returns false if the context expired first.
+func (d *drainer) wait(ctx context.Context) bool {
+	for d.inflight() > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-d.changed:
+		}
+	}
+	return true
+}
+
+// remaining returns the requests still in-flight, oldest first, along with the hijacked connections.
+func (d *drainer) remaining() ([]inflightRequest, []net.Conn) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	requests := make([]inflightRequest, 0, len(d.requests))
+	for _, request := range d.requests {
+		requests = append(requests, request)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].StartedAt.Before(requests[j].StartedAt)
+	})
+	conns := make([]net.Conn, 0, len(d.hijacked))
+	for conn := range d.hijacked {
+		conns = append(conns, conn)
+	}
+	return requests, conns
+}
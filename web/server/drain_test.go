@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainer(t *testing.T) {
+	r := require.New(t)
+	d := newDrainer(DrainConfig{RetryAfter: 1500 * time.Millisecond})
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	handler := d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	inflight := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(inflight, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	}()
+	<-entered
+
+	d.startDraining()
+	r.NotNil(d.draining())
+	d.reject()
+
+	rejected := httptest.NewRecorder()
+	handler.ServeHTTP(rejected, httptest.NewRequest(http.MethodGet, "/new", nil))
+	r.Equal(http.StatusServiceUnavailable, rejected.Code)
+	r.Equal("2", rejected.Header().Get("Retry-After"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	r.False(d.wait(ctx))
+	requests, conns := d.remaining()
+	r.Len(requests, 1)
+	r.Equal("/stream", requests[0].Path)
+	r.Empty(conns)
+
+	close(release)
+	<-done
+	r.Equal(http.StatusNoContent, inflight.Code)
+	r.True(d.wait(context.Background()))
+}
+
+func TestDrainerHijackedConns(t *testing.T) {
+	r := require.New(t)
+	d := newDrainer(*DefaultDrainConfig())
+	hijacked := make(chan net.Conn, 1)
+	server := &http.Server{
+		Handler: d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				t.Errorf("failed to hijack: %s", err)
+				return
+			}
+			hijacked <- conn
+		})),
+		ConnState: d.TrackConn,
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	r.NoError(err)
+	go func() { _ = server.Serve(d.Listener(listener)) }()
+	defer server.Close()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	r.NoError(err)
+	defer client.Close()
+	_, err = client.Write([]byte("GET /ws HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	r.NoError(err)
+	conn := <-hijacked
+
+	_, conns := d.remaining()
+	r.Len(conns, 1)
+	r.NoError(conn.Close())
+	_, conns = d.remaining()
+	r.Empty(conns, "the closed hijacked connections are forgotten")
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/kiwiworks/rodent/logger"
 	"github.com/kiwiworks/rodent/web/auth"
+	"github.com/kiwiworks/rodent/web/tenancy"
 )
 
 type Router struct {
@@ -20,6 +21,8 @@ type RouterConfig struct {
 	Mux            *chi.Mux
 	Api            huma.API
 	AuthMiddleware *auth.Middleware
+	// TenancyMiddleware is provided by the tenancy.Module.
+	TenancyMiddleware *tenancy.Middleware `optional:"true"`
 }
 
 func NewRouter(cfg RouterConfig) *Router {
@@ -28,6 +31,10 @@ func NewRouter(cfg RouterConfig) *Router {
 		log.Info("using auth middleware")
 		cfg.Api.UseMiddleware(cfg.AuthMiddleware.Middleware)
 	}
+	if cfg.TenancyMiddleware != nil {
+		log.Info("using tenancy middleware")
+		cfg.Api.UseMiddleware(cfg.TenancyMiddleware.Middleware)
+	}
 	return &Router{
 		mux:            cfg.Mux,
 		api:            cfg.Api,
@@ -2,24 +2,39 @@ package server
 
 import (
 	"context"
+	"net"
 	"net/http"
+	"sync"
+	"time"
 
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"github.com/kiwiworks/rodent/app/module"
 	"github.com/kiwiworks/rodent/errors"
 	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/system/manifest"
 	"github.com/kiwiworks/rodent/web/api"
 )
 
 type (
 	Server struct {
-		server http.Server
-		router *Router
+		addr        string
+		router      *Router
+		drainConfig DrainConfig
+		stopBudget  time.Duration
+		mu          sync.RWMutex
+		server      *http.Server
+		drainer     *drainer
+		listener    net.Listener
+		startedAt   time.Time
 	}
 	Params struct {
 		fx.In
-		Addr      Addr `optional:"true"`
+		Addr      Addr               `optional:"true"`
+		Drain     *DrainConfig       `optional:"true"`
+		Manifest  *manifest.Manifest `optional:"true"`
 		Router    *Router
 		ApiConfig *api.Config    `optional:"true"`
 		Handlers  []*api.Handler `group:"api.handler"`
@@ -37,12 +52,18 @@ func New(params Params) *Server {
 	if addr == "" {
 		addr = "[::1]:8080"
 	}
+	if params.Drain == nil {
+		params.Drain = DefaultDrainConfig()
+	}
+	stopBudget := fx.DefaultTimeout
+	if params.Manifest != nil {
+		stopBudget = params.Manifest.Timeouts.Stop
+	}
 	server := &Server{
-		server: http.Server{
-			Addr:    string(addr),
-			Handler: params.Router.mux,
-		},
-		router: params.Router,
+		addr:        string(addr),
+		router:      params.Router,
+		drainConfig: *params.Drain,
+		stopBudget:  stopBudget,
 	}
 
 	return server
@@ -51,21 +72,112 @@ func New(params Params) *Server {
 func (s *Server) OnStart(ctx context.Context) error {
 	log := logger.FromContext(ctx)
 	s.sanityCheck(ctx)
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on '%s'", s.addr)
+	}
+	drainer := newDrainer(s.drainConfig)
+	server := &http.Server{
+		Addr:      s.addr,
+		Handler:   drainer.Middleware(s.router.mux),
+		ConnState: drainer.TrackConn,
+		// the requests outlive the start context, they only keep where it logs
+		BaseContext: func(net.Listener) context.Context {
+			return logger.Scope(context.Background(), ctx)
+		},
+	}
+	s.mu.Lock()
+	s.server = server
+	s.drainer = drainer
+	s.listener = listener
+	s.startedAt = time.Now()
+	s.mu.Unlock()
 	go func() {
-		log.Info("starting server", zap.String("address", s.server.Addr))
-		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Error("server crashed!", zap.Error(err))
+		log.Info("starting server", zap.String("address", s.addr))
+		if err := server.Serve(drainer.Listener(listener)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			module.ReportCrash(ctx, errors.Wrapf(err, "server listening on '%s' crashed", s.addr))
 		}
 	}()
 	return nil
 }
 
+// OnStop drains the server: its readiness fails first, then new requests are rejected once the pre-stop delay
+// elapsed, and in-flight requests are awaited up to the stop budget before the remaining connections are cut.
 func (s *Server) OnStop(ctx context.Context) error {
 	log := logger.FromContext(ctx)
-	log.Info("stopping server", zap.String("address", s.server.Addr))
-	return s.server.Shutdown(ctx)
+	s.mu.RLock()
+	server, drainer := s.server, s.drainer
+	s.mu.RUnlock()
+	if server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.stopBudget)
+	defer cancel()
+
+	drainer.startDraining()
+	log.Info("draining server",
+		zap.String("address", s.addr),
+		zap.Duration("server.drain.pre_stop_delay", s.drainConfig.PreStopDelay),
+	)
+	select {
+	case <-ctx.Done():
+	case <-time.After(s.drainConfig.PreStopDelay):
+	}
+	drainer.reject()
+	if drainer.wait(ctx) {
+		log.Info("stopping server", zap.String("address", s.addr))
+		if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+	}
+
+	requests, conns := drainer.remaining()
+	for _, request := range requests {
+		log.Warn("cutting in-flight request",
+			props.HttpMethod(request.Method),
+			props.HttpPath(request.Path),
+			zap.Duration("http.request.duration", time.Since(request.StartedAt)),
+		)
+	}
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+	log.Warn("forcing server to stop",
+		zap.String("address", s.addr),
+		zap.Int("server.drain.requests", len(requests)),
+		zap.Int("server.drain.hijacked", len(conns)),
+	)
+	return server.Close()
+}
+
+func (s *Server) Inspect() module.HealthCheckManifest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return module.HealthCheckManifest{
+		Name:          "web.server",
+		Description:   "HTTP server listening on " + s.addr,
+		StartedAt:     s.startedAt,
+		DrainingSince: s.drainingSince(),
+	}
+}
+
+// Address is the address the server listens on once started, such as the port picked for `127.0.0.1:0`.
+func (s *Server) Address() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.listener == nil {
+		return s.addr
+	}
+	return s.listener.Addr().String()
 }
 
 func (s *Server) Router() *Router {
 	return s.router
 }
+
+func (s *Server) drainingSince() *time.Time {
+	if s.drainer == nil {
+		return nil
+	}
+	return s.drainer.draining()
+}
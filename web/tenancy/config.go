@@ -0,0 +1,41 @@
+package tenancy
+
+import (
+	"context"
+
+	"github.com/kiwiworks/rodent/system/opt"
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+// Config configures how the Middleware resolves the tenant of the requests.
+type Config struct {
+	// Resolvers are tried in order, the first one returning a tenant wins.
+	Resolvers []Resolver
+	// Authorize checks the user of the request belongs to the tenant, the user is nil on the unprotected operations.
+	// The tenants resolved from the host or a header are chosen by the client, so it is required by their resolvers.
+	Authorize func(ctx context.Context, user *auth.ResolvedUser, tenant string) error
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Resolvers: []Resolver{Claim("tenant_id")},
+	}
+}
+
+func NewConfig(opts ...opt.Option[Config]) *Config {
+	config := DefaultConfig()
+	opt.Apply(config, opts...)
+	return config
+}
+
+func Resolvers(resolvers ...Resolver) opt.Option[Config] {
+	return func(opt *Config) {
+		opt.Resolvers = resolvers
+	}
+}
+
+func Authorize(authorize func(ctx context.Context, user *auth.ResolvedUser, tenant string) error) opt.Option[Config] {
+	return func(opt *Config) {
+		opt.Authorize = authorize
+	}
+}
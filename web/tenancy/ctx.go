@@ -0,0 +1,56 @@
+package tenancy
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+type (
+	tenantKey      struct{}
+	crossTenantKey struct{}
+)
+
+// ErrMissingTenant is returned when a tenant scoped operation runs with a context carrying no tenant.
+var ErrMissingTenant = errors.Newf("the context carries no tenant")
+
+// WithTenant scopes the context to the tenant, such as within a job running on behalf of a tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// FromContext returns the tenant the context is scoped to.
+func FromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// CrossTenant lifts the tenant scoping of the context, for the administration operations spanning all the tenants.
+// Each use is logged along with the reason and the user of the context.
+func CrossTenant(ctx context.Context, reason string) context.Context {
+	fields := []zap.Field{props.TenancyReason(reason)}
+	if _, file, line, ok := runtime.Caller(1); ok {
+		fields = append(fields, zap.String("tenancy.caller", fmt.Sprintf("%s:%d", file, line)))
+	}
+	if user := auth.UserFromContext(ctx); user != nil {
+		fields = append(fields, props.TenancyActor(user.Username))
+	}
+	if tenant, ok := FromContext(ctx); ok {
+		fields = append(fields, props.TenantId(tenant))
+	}
+	logger.FromContext(ctx).Warn("cross-tenant access granted", fields...)
+	return context.WithValue(ctx, crossTenantKey{}, reason)
+}
+
+// IsCrossTenant reports whether the tenant scoping of the context was lifted by CrossTenant, and why.
+func IsCrossTenant(ctx context.Context) (string, bool) {
+	reason, ok := ctx.Value(crossTenantKey{}).(string)
+	return reason, ok
+}
@@ -0,0 +1,85 @@
+package tenancy
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/logger"
+	"github.com/kiwiworks/rodent/logger/props"
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+type (
+	// Middleware scopes the context of the requests to their tenant, it runs after the auth.Middleware so that
+	// the tenant can be resolved from the authenticated user.
+	Middleware struct {
+		api    huma.API
+		config Config
+	}
+	MiddlewareParams struct {
+		fx.In
+		API    huma.API
+		Config *Config `optional:"true"`
+	}
+)
+
+// NewMiddleware fails when a resolver lets the client choose the tenant, such as Header or Subdomain, and the Config
+// has no Authorize to check the user belongs to it.
+func NewMiddleware(params MiddlewareParams) (*Middleware, error) {
+	config := params.Config
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.Authorize == nil {
+		for _, resolver := range config.Resolvers {
+			if !resolver.fromUser {
+				return nil, errors.Newf("the tenant resolved from the %s is chosen by the client, configure tenancy.Authorize", resolver.name)
+			}
+		}
+	}
+	return &Middleware{
+		api:    params.API,
+		config: *config,
+	}, nil
+}
+
+func (m *Middleware) writeError(ctx huma.Context, status int, msg string, err error) {
+	log := logger.New().With(props.HttpMethod(ctx.Method()), props.HttpPath(ctx.Operation().Path), props.HttpStatusCode(status))
+	if err = huma.WriteErr(m.api, ctx, status, msg, err); err != nil {
+		log.Error("failed to write error response", zap.Error(err))
+	}
+}
+
+func (m *Middleware) Middleware(ctx huma.Context, next func(ctx huma.Context)) {
+	tenant, err := m.resolve(ctx)
+	if err != nil {
+		m.writeError(ctx, 400, "invalid tenant", err)
+		return
+	}
+	if tenant == "" {
+		next(ctx)
+		return
+	}
+	if m.config.Authorize != nil {
+		if err = m.config.Authorize(ctx.Context(), auth.UserFromContext(ctx.Context()), tenant); err != nil {
+			m.writeError(ctx, 403, "access to the tenant denied", err)
+			return
+		}
+	}
+	next(huma.WithContext(ctx, WithTenant(ctx.Context(), tenant)))
+}
+
+func (m *Middleware) resolve(ctx huma.Context) (string, error) {
+	for _, resolver := range m.config.Resolvers {
+		tenant, err := resolver.resolve(ctx)
+		if err != nil {
+			return "", err
+		}
+		if tenant != "" {
+			return tenant, nil
+		}
+	}
+	return "", nil
+}
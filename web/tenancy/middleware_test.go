@@ -0,0 +1,151 @@
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+type tenantResponse struct {
+	Body struct {
+		Tenant string `json:"tenant"`
+	}
+}
+
+func allow(context.Context, *auth.ResolvedUser, string) error {
+	return nil
+}
+
+func TestNewMiddleware(t *testing.T) {
+	resolve := func(huma.Context) (string, error) { return "", nil }
+	tests := []struct {
+		name   string
+		config *Config
+		err    string
+	}{
+		{name: "default", config: nil},
+		{name: "claim", config: NewConfig(Resolvers(Claim("org_id")))},
+		{name: "from user", config: NewConfig(Resolvers(FromUser("membership", resolve)))},
+		{
+			name:   "header without authorize",
+			config: NewConfig(Resolvers(Claim("tenant_id"), Header("X-Tenant-Id"))),
+			err:    "the tenant resolved from the header X-Tenant-Id is chosen by the client",
+		},
+		{
+			name:   "subdomain without authorize",
+			config: NewConfig(Resolvers(Subdomain("example.com"))),
+			err:    "the tenant resolved from the subdomain of example.com is chosen by the client",
+		},
+		{
+			name:   "custom without authorize",
+			config: NewConfig(Resolvers(Custom("path", resolve))),
+			err:    "the tenant resolved from the path is chosen by the client",
+		},
+		{name: "header with authorize", config: NewConfig(Resolvers(Header("X-Tenant-Id")), Authorize(allow))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMiddleware(MiddlewareParams{Config: tt.config})
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		user   *auth.ResolvedUser
+		args   []any
+		status int
+		tenant string
+	}{
+		{
+			name:   "no tenant",
+			config: DefaultConfig(),
+			status: 200,
+		},
+		{
+			name:   "claim",
+			config: DefaultConfig(),
+			user:   &auth.ResolvedUser{Claims: map[string]any{"tenant_id": "acme"}},
+			status: 200,
+			tenant: "acme",
+		},
+		{
+			name:   "invalid claim",
+			config: DefaultConfig(),
+			user:   &auth.ResolvedUser{Claims: map[string]any{"tenant_id": 42}},
+			status: 400,
+		},
+		{
+			name:   "first resolver wins",
+			config: NewConfig(Resolvers(Header("X-Tenant-Id"), Subdomain("example.com")), Authorize(allow)),
+			args:   []any{"Host: globex.example.com", "X-Tenant-Id: acme"},
+			status: 200,
+			tenant: "acme",
+		},
+		{
+			name:   "subdomain",
+			config: NewConfig(Resolvers(Header("X-Tenant-Id"), Subdomain("example.com")), Authorize(allow)),
+			args:   []any{"Host: globex.example.com:8080"},
+			status: 200,
+			tenant: "globex",
+		},
+		{
+			name:   "nested subdomain",
+			config: NewConfig(Resolvers(Subdomain("example.com")), Authorize(allow)),
+			args:   []any{"Host: a.b.example.com"},
+			status: 200,
+		},
+		{
+			name: "unauthorized tenant",
+			config: NewConfig(
+				Resolvers(Header("X-Tenant-Id")),
+				Authorize(func(ctx context.Context, user *auth.ResolvedUser, tenant string) error {
+					return errors.Newf("%s is not a member of %s", user.Username, tenant)
+				}),
+			),
+			user:   &auth.ResolvedUser{Username: "bob"},
+			args:   []any{"X-Tenant-Id: acme"},
+			status: 403,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+			_, humaAPI := humatest.New(t)
+			humaAPI.UseMiddleware(func(ctx huma.Context, next func(huma.Context)) {
+				if tt.user != nil {
+					ctx = huma.WithContext(ctx, auth.InjectUser(ctx.Context(), tt.user))
+				}
+				next(ctx)
+			})
+			middleware, err := NewMiddleware(MiddlewareParams{API: humaAPI, Config: tt.config})
+			r.NoError(err)
+			humaAPI.UseMiddleware(middleware.Middleware)
+			huma.Get(humaAPI, "/tenant", func(ctx context.Context, _ *struct{}) (*tenantResponse, error) {
+				response := &tenantResponse{}
+				response.Body.Tenant, _ = FromContext(ctx)
+				return response, nil
+			})
+			api := humatest.Wrap(t, humaAPI)
+
+			response := api.Get("/tenant", tt.args...)
+			r.Equal(tt.status, response.Code, response.Body.String())
+			if tt.status == 200 {
+				r.JSONEq(`{"tenant":"`+tt.tenant+`"}`, response.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package tenancy
+
+import (
+	"github.com/kiwiworks/rodent/app"
+	"github.com/kiwiworks/rodent/app/module"
+)
+
+// Module enables the tenant resolution of the requests, supply a *Config to choose the resolvers.
+func Module() app.Module {
+	return app.NewModule(
+		module.Public(
+			NewMiddleware,
+		),
+	)
+}
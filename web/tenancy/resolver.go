@@ -0,0 +1,78 @@
+package tenancy
+
+import (
+	"net"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/kiwiworks/rodent/errors"
+	"github.com/kiwiworks/rodent/web/auth"
+)
+
+// Resolver returns the tenant of the request, or an empty string when it does not carry one.
+type Resolver struct {
+	name    string
+	resolve func(ctx huma.Context) (string, error)
+	// fromUser resolvers read the tenant from the authenticated user, the others read it from the request, so that
+	// the client chooses it and the Middleware requires an Authorize.
+	fromUser bool
+}
+
+// Custom resolves the tenant with a function, the tenant is deemed chosen by the client, use FromUser when the
+// function only reads the authenticated user.
+func Custom(name string, resolve func(ctx huma.Context) (string, error)) Resolver {
+	return Resolver{name: name, resolve: resolve}
+}
+
+// FromUser resolves the tenant with a function reading it from the authenticated user, see auth.UserFromContext.
+func FromUser(name string, resolve func(ctx huma.Context) (string, error)) Resolver {
+	return Resolver{name: name, resolve: resolve, fromUser: true}
+}
+
+// Subdomain resolves the tenant from the subdomain of the host, `acme.example.com` is the tenant `acme` of
+// the domain `example.com`, the hosts with more than one label in front of the domain carry no tenant.
+func Subdomain(domain string) Resolver {
+	suffix := "." + strings.ToLower(strings.Trim(domain, "."))
+	return Custom("subdomain of "+domain, func(ctx huma.Context) (string, error) {
+		host := ctx.Host()
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		host = strings.ToLower(host)
+		if !strings.HasSuffix(host, suffix) {
+			return "", nil
+		}
+		label := strings.TrimSuffix(host, suffix)
+		if label == "" || strings.Contains(label, ".") {
+			return "", nil
+		}
+		return label, nil
+	})
+}
+
+// Header resolves the tenant from a request header, such as `X-Tenant-Id`.
+func Header(name string) Resolver {
+	return Custom("header "+name, func(ctx huma.Context) (string, error) {
+		return strings.TrimSpace(ctx.Header(name)), nil
+	})
+}
+
+// Claim resolves the tenant from a claim of the authenticated user, see auth.ResolvedUser.Claims.
+func Claim(name string) Resolver {
+	return FromUser("claim "+name, func(ctx huma.Context) (string, error) {
+		user := auth.UserFromContext(ctx.Context())
+		if user == nil {
+			return "", nil
+		}
+		value, ok := user.Claims[name]
+		if !ok || value == nil {
+			return "", nil
+		}
+		tenant, ok := value.(string)
+		if !ok {
+			return "", errors.Newf("the %s claim is a %T, not a string", name, value)
+		}
+		return tenant, nil
+	})
+}